@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"solace_exporter/internal/exporter"
+)
+
+// checkConfig parses configFile and validates its endpoints against the known scrape targets, so a typo fails in CI
+// instead of as up=0 at scrape time. Secrets are not resolved and no broker is contacted. It writes one line per
+// problem to w and returns the exit code. It also lists the targets that need a minimum broker version.
+func checkConfig(w io.Writer, configFile string) int {
+	endpoints, conf, err := exporter.ParseConfig(configFile)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "%s: %s\n", configFile, err)
+		return 1
+	}
+
+	for _, requirement := range conf.VersionRequirements(endpoints) {
+		_, _ = fmt.Fprintf(w, "%s: %s\n", configFile, requirement)
+	}
+
+	problems := conf.CheckEndpoints(endpoints)
+	for _, problem := range problems {
+		_, _ = fmt.Fprintf(w, "%s: %s\n", configFile, problem)
+	}
+	if len(problems) > 0 {
+		_, _ = fmt.Fprintf(w, "%d problem(s) found in %d endpoint(s)\n", len(problems), len(endpoints))
+		return 1
+	}
+
+	_, _ = fmt.Fprintf(w, "%s: %d endpoint(s) OK\n", configFile, len(endpoints))
+	return 0
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		ini      string
+		wantCode int
+		wantOut  []string
+	}{
+		{
+			name:     "valid",
+			ini:      "[solace]\nscrapeUri=http://broker:8080\nusername=u\npassword=p\n\n[endpoint.std]\nVersion=*|*\nQueueRates.0=*|a*\nQueueRates.1=*|b*\n",
+			wantCode: 0,
+			wantOut:  []string{"1 endpoint(s) OK"},
+		},
+		{
+			name:     "version requirements",
+			ini:      "[solace]\nscrapeUri=http://broker:8080\nusername=u\npassword=p\n\n[endpoint.std]\nVersion=*|*\nVpnReplication=*|*\nMqttSession=*|*\n\n[endpoint.det]\nReplicationStats=*|*\n",
+			wantCode: 0,
+			wantOut: []string{
+				"SolOS 7.1 or later: ReplicationStats, VpnReplication\n",
+				"SolOS 7.1.1 or later: MqttSession\n",
+				"SolOS 7.2 or later: ReplicationStats metric system_replication_transitions_to_ineligible, VpnReplication metric vpn_replication_transaction_replication_mode\n",
+				"2 endpoint(s) OK",
+			},
+		},
+		{
+			name:     "unknown target",
+			ini:      "[solace]\nscrapeUri=http://broker:8080\nusername=u\npassword=p\n\n[endpoint.std]\nVersion=*|*\nQueueStat=*|*\n",
+			wantCode: 1,
+			wantOut:  []string{`endpoint "std": QueueStat: unknown scrape target`, "1 problem(s) found in 1 endpoint(s)"},
+		},
+		{
+			name:     "unparsable config",
+			ini:      "[solace]\nusername=u\npassword=p\n",
+			wantCode: 1,
+			wantOut:  []string{"scrapeUri"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			configFile := filepath.Join(t.TempDir(), "solace.ini")
+			if err := os.WriteFile(configFile, []byte(tt.ini), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var out strings.Builder
+			if code := checkConfig(&out, configFile); code != tt.wantCode {
+				t.Errorf("checkConfig() = %d, want %d; output:\n%s", code, tt.wantCode, out.String())
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output %q does not contain %q", out.String(), want)
+				}
+			}
+		})
+	}
+}
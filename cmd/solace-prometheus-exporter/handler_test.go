@@ -99,3 +99,38 @@ func TestDoHandleExporterAuthProtectsEndpoint(t *testing.T) {
 		t.Errorf("with exporter auth: status = %d, want 200", rr.Code)
 	}
 }
+
+// TestDoHandleTargetSelectsBroker verifies that `?target=<name>` scrapes the [broker.<name>] broker with its own
+// credentials, and that an unknown target is rejected instead of silently scraping the [solace] broker.
+func TestDoHandleTargetSelectsBroker(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	resolver := newTestResolver(t)
+	broker := newMockBroker(t, 3)
+
+	base := &exporter.Config{
+		Username:   "wrong-base",
+		Password:   "wrong-base",
+		Timeout:    5 * time.Second,
+		DefaultVpn: "default",
+		Brokers: map[string]*exporter.BrokerConfig{
+			"b3": {Name: "b3", ScrapeURI: broker.server.URL, Username: "user-3", Password: "pass-3", Timeout: 5 * time.Second},
+		},
+	}
+	if err := base.Brokers["b3"].DetermineAuthType(); err != nil {
+		t.Fatal(err)
+	}
+	ds := []exporter.DataSource{{Name: "QueueDetails", VpnFilter: "*", ItemFilter: "*"}}
+
+	rr := httptest.NewRecorder()
+	doHandle(rr, httptest.NewRequest(http.MethodGet, "/solace?target=b3", nil), ds, base, resolver, logger)
+	if up := scrapeUp(t, rr.Body.String()); up != "1" {
+		t.Errorf("solace_up = %s, want 1 (target credentials should be used)", up)
+	}
+
+	rr = httptest.NewRecorder()
+	doHandle(rr, httptest.NewRequest(http.MethodGet, "/solace?target=unknown", nil), ds, base, resolver, logger)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("unknown target: status = %d, want 404", rr.Code)
+	}
+}
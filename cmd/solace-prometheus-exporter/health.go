@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"solace_exporter/internal/exporter"
+)
+
+// Outcomes of a health check.
+const (
+	checkOK      = "ok"
+	checkFailed  = "failed"
+	checkSkipped = "skipped"
+)
+
+// healthCheck is the outcome of one check of /healthz or /readyz.
+type healthCheck struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// healthReport is the JSON body of /healthz and /readyz. Status is "ok" if no check failed.
+type healthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]healthCheck `json:"checks"`
+}
+
+// handleHealthz answers the liveness probe: the exporter runs and serves HTTP. It does not depend on the brokers, so
+// an unreachable broker never gets the exporter restarted.
+func (rt *router) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	writeHealthReport(w, map[string]healthCheck{"http": {Status: checkOK}})
+}
+
+// handleReadyz answers the readiness probe. It checks that the config is loaded with its secrets resolved, that every
+// prefetch loop started finished its first fetch, and, with readinessProbeBroker, that the [solace] broker answers
+// `show version`.
+func (rt *router) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	state := rt.state.Load()
+	if state == nil {
+		writeHealthReport(w, map[string]healthCheck{"config": {Status: checkFailed, Detail: "config not loaded yet"}})
+		return
+	}
+
+	writeHealthReport(w, map[string]healthCheck{
+		"config":   {Status: checkOK, Detail: "config loaded and secrets resolved"},
+		"prefetch": checkPrefetches(state),
+		"broker":   rt.checkBroker(r.Context(), state.conf),
+	})
+}
+
+// checkPrefetches fails while a started prefetch loop has not finished its first fetch of every data source.
+func checkPrefetches(state *routerState) healthCheck {
+	if len(state.prefetches) == 0 {
+		return healthCheck{Status: checkSkipped, Detail: "prefetching disabled"}
+	}
+
+	var started, pending int
+	for _, prefetch := range state.prefetches {
+		for _, fetcher := range prefetch.fetchers.started() {
+			started++
+			if !fetcher.Fetched() {
+				pending++
+			}
+		}
+	}
+	if pending > 0 {
+		return healthCheck{Status: checkFailed, Detail: fmt.Sprintf("%d of %d prefetch loops did not finish their first fetch", pending, started)}
+	}
+	return healthCheck{Status: checkOK, Detail: fmt.Sprintf("%d prefetch loops fetched", started)}
+}
+
+// checkBroker probes the [solace] broker if readinessProbeBroker is enabled.
+func (rt *router) checkBroker(ctx context.Context, conf *exporter.Config) healthCheck {
+	if !conf.ReadinessProbeBroker {
+		return healthCheck{Status: checkSkipped, Detail: "readinessProbeBroker disabled"}
+	}
+
+	if err := exporter.ProbeBroker(ctx, rt.logger, conf); err != nil {
+		return healthCheck{Status: checkFailed, Detail: err.Error()}
+	}
+	return healthCheck{Status: checkOK, Detail: conf.ScrapeURI + " answered show version"}
+}
+
+// writeHealthReport writes checks as JSON, with status 503 if one of them failed.
+func writeHealthReport(w http.ResponseWriter, checks map[string]healthCheck) {
+	report := healthReport{Status: checkOK, Checks: checks}
+	for _, check := range checks {
+		if check.Status == checkFailed {
+			report.Status = checkFailed
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status != checkOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func getHealthReport(t *testing.T, rt *router, path string) (int, healthReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report healthReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("%s returned no JSON: %v: %s", path, err, rec.Body.String())
+	}
+	return rec.Code, report
+}
+
+// TestReadyzWaitsForFirstPrefetch verifies that /readyz fails until the prefetch loops fetched once, while /healthz
+// does not depend on it.
+//
+//nolint:paralleltest // the reload gauges are process-wide
+func TestReadyzWaitsForFirstPrefetch(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	release := make(chan struct{})
+	broker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(queueReplyXML))
+	}))
+	defer broker.Close()
+	var once sync.Once
+	releaseBroker := func() { once.Do(func() { close(release) }) }
+	defer releaseBroker()
+
+	configFile := filepath.Join(t.TempDir(), "solace.ini")
+	writeRouterConfig(t, configFile, broker.URL, "1s", "[endpoint.pre]\nQueueDetails=*|*\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rt := newRouter(ctx, configFile, newTestResolver(t), logger)
+
+	if code, report := getHealthReport(t, rt, "/readyz"); code != http.StatusServiceUnavailable || report.Checks["config"].Status != checkFailed {
+		t.Errorf("/readyz before the config was loaded = %d %+v, want 503 with failed config", code, report)
+	}
+	if err := rt.reload(); err != nil {
+		t.Fatalf("initial reload: %v", err)
+	}
+
+	if code, report := getHealthReport(t, rt, "/healthz"); code != http.StatusOK || report.Status != checkOK {
+		t.Errorf("/healthz = %d %+v, want 200 ok", code, report)
+	}
+	code, report := getHealthReport(t, rt, "/readyz")
+	if code != http.StatusServiceUnavailable || report.Checks["prefetch"].Status != checkFailed {
+		t.Errorf("/readyz during the first prefetch = %d %+v, want 503 with failed prefetch", code, report)
+	}
+	if report.Checks["broker"].Status != checkSkipped {
+		t.Errorf("broker check = %+v, want skipped", report.Checks["broker"])
+	}
+
+	releaseBroker()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		code, report = getHealthReport(t, rt, "/readyz")
+		if code == http.StatusOK || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if code != http.StatusOK || report.Checks["prefetch"].Status != checkOK {
+		t.Errorf("/readyz after the first prefetch = %d %+v, want 200 with ok prefetch", code, report)
+	}
+}
+
+//nolint:paralleltest // the reload gauges are process-wide
+func TestReadyzProbesBroker(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	broker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer broker.Close()
+
+	configFile := filepath.Join(t.TempDir(), "solace.ini")
+	writeRouterConfig(t, configFile, broker.URL, "0s\nreadinessProbeBroker=true", "[endpoint.sync]\nVersion=*|*\n")
+	rt := newRouter(context.Background(), configFile, newTestResolver(t), logger)
+	if err := rt.reload(); err != nil {
+		t.Fatalf("initial reload: %v", err)
+	}
+
+	code, report := getHealthReport(t, rt, "/readyz")
+	if code != http.StatusServiceUnavailable || report.Checks["broker"].Status != checkFailed || report.Checks["broker"].Detail == "" {
+		t.Errorf("/readyz with an unreachable broker = %d %+v, want 503 with failed broker and its error", code, report)
+	}
+	if report.Checks["prefetch"].Status != checkSkipped {
+		t.Errorf("prefetch check = %+v, want skipped", report.Checks["prefetch"])
+	}
+}
@@ -2,16 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
 	"solace_exporter/internal/exporter"
 	"solace_exporter/internal/secret"
 	"solace_exporter/internal/web"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
@@ -20,13 +25,41 @@ import (
 	"github.com/prometheus/common/promslog"
 	"github.com/prometheus/common/promslog/flag"
 	promVersion "github.com/prometheus/common/version"
-	"golang.org/x/sync/semaphore"
 )
 
 // secretResolveRequestTimeout bounds how long a request waits on the secret backend for per-request credentials.
 // Kept separate from Config.Timeout, which is the per-call SEMP scrape timeout, not a whole-request budget.
 const secretResolveRequestTimeout = 5 * time.Second
 
+// shutdownTimeout bounds a graceful shutdown on SIGTERM or SIGINT. It stays below the default termination grace period
+// of Kubernetes (30s), so the exporter is not killed while it still talks to a broker.
+const shutdownTimeout = 20 * time.Second
+
+// scrapeTimeoutOffset is kept from the X-Prometheus-Scrape-Timeout-Seconds budget, so the metrics collected until the
+// deadline still reach Prometheus before it gives up on the scrape.
+const scrapeTimeoutOffset = 500 * time.Millisecond
+
+// scrapeContext returns the context for the SEMP calls of a scrape. It is done when the client goes away, or when the
+// scrape timeout Prometheus sends in the X-Prometheus-Scrape-Timeout-Seconds header (less scrapeTimeoutOffset) runs
+// out. A missing or invalid header leaves only the per-call SEMP timeout.
+func scrapeContext(r *http.Request, logger *slog.Logger) (context.Context, context.CancelFunc) {
+	header := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if header == "" {
+		return context.WithCancel(r.Context())
+	}
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 {
+		logger.Warn("Ignoring invalid scrape timeout header", "X-Prometheus-Scrape-Timeout-Seconds", header)
+		return context.WithCancel(r.Context())
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > 2*scrapeTimeoutOffset {
+		timeout -= scrapeTimeoutOffset
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
+
 func logDataSource(dataSources []exporter.DataSource) string {
 	dS := make([]string, len(dataSources))
 	for index, dataSource := range dataSources {
@@ -50,7 +83,12 @@ func main() {
 		"config-file",
 		"Path and name of ini file with configuration settings. See sample file solace_prometheus_exporter.ini.",
 	).String()
-	kingpin.Parse()
+	kingpin.Command("serve", "Serve the metrics of the configured brokers.").Default()
+	checkConfigCmd := kingpin.Command("check-config", "Validate the config file and its endpoints without contacting a broker.")
+
+	if kingpin.Parse() == checkConfigCmd.FullCommand() {
+		os.Exit(checkConfig(os.Stdout, *configFile))
+	}
 
 	logger := promslog.New(&promlogConfig)
 
@@ -60,8 +98,8 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Owns the lifetime of background work started during setup (currently the Vault token renewal loop), so it is
-	// cancelable instead of pinned to context.Background().
+	// Owns the lifetime of background work started during setup (the Vault token renewal loop, the prefetch loops and
+	// the config reload listener), so it is cancelable instead of pinned to context.Background().
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -87,73 +125,75 @@ func main() {
 		"sslVerify", conf.SslVerify,
 		"timeout", conf.Timeout)
 
-	// Configure endpoints
-	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
-		doHandle(w, r, nil, conf, secretResolver, logger)
-	})
+	rt := newRouter(ctx, *configFile, secretResolver, logger)
+	rt.apply(endpoints, conf)
+	http.Handle("/", rt)
 
-	// A broker has only max 10 semp connections that can be served in parallel.
-	var sempConnections = semaphore.NewWeighted(conf.ParallelSempConnections)
-	declareHandlerFromConfig := func(urlPath string, dataSource []exporter.DataSource) {
-		logger.Info("Register handler from config", "handler", "/"+urlPath, "dataSource", logDataSource(dataSource))
+	// SIGHUP reloads the config file, same as a POST to /-/reload.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go rt.reloadOn(ctx, hup)
 
-		if conf.PrefetchInterval.Seconds() > 0 {
-			var asyncFetcher = exporter.NewAsyncFetcher(context.Background(), urlPath, dataSource, conf, logger, sempConnections)
-			http.HandleFunc("/"+urlPath, func(w http.ResponseWriter, r *http.Request) {
-				doHandleAsync(w, r, asyncFetcher, conf)
-			})
-		} else {
-			http.HandleFunc("/"+urlPath, func(w http.ResponseWriter, r *http.Request) {
-				doHandle(w, r, dataSource, conf, secretResolver, logger)
-			})
+	// start server
+	var server *http.Server
+	if conf.EnableTLS {
+		server, err = exporter.NewTLSServer(conf)
+		if err != nil {
+			logger.Error("Error creating HTTPS server", "err", err)
+			os.Exit(2)
+		}
+	} else {
+		server = &http.Server{
+			Addr:              conf.ListenAddr,
+			ReadHeaderTimeout: 5 * time.Second,
 		}
 	}
-	for urlPath, dataSource := range endpoints {
-		declareHandlerFromConfig(urlPath, dataSource)
+	// The scrapes get their own context, so that a shutdown can let them finish after the background work stopped.
+	scrapeCtx, cancelScrapes := context.WithCancel(context.Background())
+	defer cancelScrapes()
+	server.BaseContext = func(net.Listener) context.Context { return scrapeCtx }
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if conf.EnableTLS {
+			serveErr <- server.ListenAndServeTLS("", "")
+		} else {
+			serveErr <- server.ListenAndServe()
+		}
+	}()
+
+	terminate := make(chan os.Signal, 1)
+	signal.Notify(terminate, syscall.SIGTERM, os.Interrupt)
+	select {
+	case err := <-serveErr:
+		logger.Error("Error starting HTTP server", "err", err)
+		os.Exit(2)
+	case sig := <-terminate:
+		logger.Info("Shutting down", "signal", sig.String())
 	}
 
-	http.HandleFunc("/solace", func(w http.ResponseWriter, r *http.Request) {
-		if err := r.ParseForm(); err != nil {
-			logger.Error("Can not parse the request parameter", "err", err)
-			return
-		}
+	shutdown(server, rt, cancel, cancelScrapes, logger)
+}
 
-		doHandle(w, r, parseDataSources(r.Form, logger), conf, secretResolver, logger)
-	})
+// shutdown stops the exporter gracefully within shutdownTimeout: it stops the prefetch loops, the config reload and
+// the secret renewal by cancelling the background context, which aborts their SEMP requests. Then it stops accepting
+// connections and lets the running scrapes finish. Scrapes still running at the deadline are cancelled.
+func shutdown(server *http.Server, rt *router, cancelBackground, cancelScrapes context.CancelFunc, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
 
-	endpointViews := make([]web.EndpointView, 0, len(endpoints))
+	cancelBackground()
 
-	for urlPath, dataSources := range endpoints {
-		endpointViews = append(endpointViews, web.EndpointView{
-			Path: urlPath,
-			Meta: logDataSource(dataSources),
-		})
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Warn("Scrapes did not finish in time, cancelling them", "err", err)
+		cancelScrapes()
+		_ = server.Close()
 	}
-
-	handler, err := web.NewHandler(web.TemplateData{
-		IsHWBroker: conf.IsHWBroker,
-		Endpoints:  endpointViews,
-	})
-	if err != nil {
-		logger.Error(err.Error())
+	if err := rt.waitPrefetches(ctx); err != nil {
+		logger.Warn("Prefetch loops did not stop in time", "err", err)
 	}
 
-	http.Handle("/", web.WrapWithAuth(handler, conf.ExporterAuth))
-
-	// start server
-	if conf.EnableTLS {
-		exporter.ListenAndServeTLS(conf)
-	} else {
-		server := &http.Server{
-			Addr:              conf.ListenAddr,
-			ReadHeaderTimeout: 5 * time.Second,
-		}
-
-		if err := server.ListenAndServe(); err != nil {
-			logger.Error("Error starting HTTP server", "err", err)
-			os.Exit(2)
-		}
-	}
+	logger.Info("Shut down")
 }
 
 func doHandleAsync(w http.ResponseWriter, r *http.Request, asyncFetcher *exporter.AsyncFetcher, conf *exporter.Config) string {
@@ -175,6 +215,11 @@ func doHandle(w http.ResponseWriter, r *http.Request, dataSource []exporter.Data
 		// Each request scrapes a broker whose credentials/scrapeURI come from the request itself, so we work on a
 		// per-request Config copy -- a shared Config here previously caused broker-wide SEMP 401s.
 		reqConf, err := resolveRequestConfig(r, conf, secretResolver, logger)
+		if errors.Is(err, exporter.ErrUnknownTarget) {
+			logger.Error("Error selecting broker target", "err", err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return "404"
+		}
 		if err != nil {
 			logger.Error("Error resolving per-request broker credentials", "err", err)
 			http.Error(w, "internal error resolving broker credentials", http.StatusInternalServerError)
@@ -183,7 +228,10 @@ func doHandle(w http.ResponseWriter, r *http.Request, dataSource []exporter.Data
 
 		logger.Info("handle http request", "dataSource", logDataSource(dataSource), "scrapeURI", reqConf.ScrapeURI)
 
-		exp := exporter.NewExporter(r.Context(), logger, reqConf, &dataSource)
+		ctx, cancel := scrapeContext(r, logger)
+		defer cancel()
+
+		exp := exporter.NewExporter(ctx, logger, reqConf, strings.TrimPrefix(r.URL.Path, "/"), &dataSource)
 		registry := prometheus.NewRegistry()
 		registry.MustRegister(exp)
 		handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
@@ -228,8 +276,12 @@ func parseDataSources(form url.Values, logger *slog.Logger) []exporter.DataSourc
 // resolveRequestConfig returns a per-request copy of conf with credentials, scrape URI, timeout and broker type
 // overridden from the request (form param, then x-solace-broker-* header, else the base Config value); conf itself is
 // never mutated. Username/password are resolved through resolver (skip via secretBackend=none), bounded by r.Context() and secretResolveRequestTimeout.
+// A `target` selects a [broker.<name>] section as base instead of [solace]; the other overrides still apply on top.
 func resolveRequestConfig(r *http.Request, conf *exporter.Config, resolver *secret.Resolver, logger *slog.Logger) (*exporter.Config, error) {
-	reqConf := conf.Clone()
+	reqConf, err := conf.ForTarget(requestTarget(r))
+	if err != nil {
+		return nil, err
+	}
 
 	if timeout := firstNonEmpty(r.FormValue("timeout"), r.Header.Get("x-solace-broker-timeout")); timeout != "" {
 		parsed, err := time.ParseDuration(timeout)
@@ -247,13 +299,13 @@ func resolveRequestConfig(r *http.Request, conf *exporter.Config, resolver *secr
 	}
 
 	if isHWBroker := firstNonEmpty(r.FormValue("isHWBroker"), r.FormValue("ishwbroker"), r.Header.Get("x-solace-broker-ishwbroker")); isHWBroker != "" {
-		parsed, err := strconv.ParseBool(isHWBroker)
+		parsed, detect, err := exporter.ParseIsHWBroker(isHWBroker)
 		if err != nil {
 			// Keep the configured value: defaulting to false would silently gate off hardware-only scrape targets
 			// and report them as scrape errors.
 			logger.Error("Per HTTP given isHWBroker parameter is not valid", "err", err, "isHWBroker", isHWBroker)
 		} else {
-			reqConf.IsHWBroker = parsed
+			reqConf.IsHWBroker, reqConf.DetectHWBroker = parsed, detect
 		}
 	}
 
@@ -287,11 +339,66 @@ func resolveRequestConfig(r *http.Request, conf *exporter.Config, resolver *secr
 	}
 	if scrapeURI := firstNonEmpty(r.FormValue("scrapeURI"), r.FormValue("scrapeUri"), r.Header.Get("x-solace-broker-scrapeuri")); scrapeURI != "" {
 		reqConf.ScrapeURI = scrapeURI
+		// The configured backup is the mate of the configured broker, not of this one.
+		reqConf.BackupScrapeURI = ""
+	}
+	if backupScrapeURI := firstNonEmpty(r.FormValue("backupScrapeURI"), r.FormValue("backupScrapeUri"), r.Header.Get("x-solace-broker-backup-scrapeuri")); backupScrapeURI != "" {
+		reqConf.BackupScrapeURI = backupScrapeURI
 	}
 
 	return reqConf, nil
 }
 
+// requestTarget returns the name of the [broker.<name>] section selected by the request, or "" for the [solace] broker.
+func requestTarget(r *http.Request) string {
+	return strings.TrimSpace(firstNonEmpty(r.FormValue("target"), r.Header.Get("x-solace-broker-target")))
+}
+
+// targetFetchers starts one AsyncFetcher per broker target of a prefetch endpoint, on first use of that target.
+type targetFetchers struct {
+	mu       sync.Mutex
+	fetchers map[string]*exporter.AsyncFetcher
+	start    func(targetConf *exporter.Config) *exporter.AsyncFetcher
+}
+
+func newTargetFetchers(start func(targetConf *exporter.Config) *exporter.AsyncFetcher) *targetFetchers {
+	return &targetFetchers{
+		fetchers: make(map[string]*exporter.AsyncFetcher),
+		start:    start,
+	}
+}
+
+// get returns the fetcher of target, starting it if needed. Unknown targets yield exporter.ErrUnknownTarget.
+func (t *targetFetchers) get(conf *exporter.Config, target string) (*exporter.AsyncFetcher, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if fetcher, ok := t.fetchers[target]; ok {
+		return fetcher, nil
+	}
+
+	targetConf, err := conf.ForTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	fetcher := t.start(targetConf)
+	t.fetchers[target] = fetcher
+
+	return fetcher, nil
+}
+
+// started returns the fetchers started so far.
+func (t *targetFetchers) started() []*exporter.AsyncFetcher {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fetchers := make([]*exporter.AsyncFetcher, 0, len(t.fetchers))
+	for _, fetcher := range t.fetchers {
+		fetchers = append(fetchers, fetcher)
+	}
+	return fetchers
+}
+
 // firstNonEmpty returns the first non-empty string of the given values, or "" if all are empty.
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -27,6 +28,7 @@ func TestResolveRequestConfig(t *testing.T) {
 		expectedScrapeURI  string
 		expectedTimeout    time.Duration
 		expectedIsHWBroker bool
+		expectedDetect     bool
 	}{
 		{
 			name: "Header override",
@@ -172,6 +174,27 @@ func TestResolveRequestConfig(t *testing.T) {
 			expectedTimeout:    5 * time.Second,
 			expectedIsHWBroker: true,
 		},
+		{
+			name:               "isHWBroker param auto detects the broker type",
+			queryParams:        map[string]string{"isHWBroker": "auto"},
+			base:               exporter.Config{Username: "conf-user", Password: "conf-pass", ScrapeURI: "http://conf-uri", Timeout: 5 * time.Second, IsHWBroker: true},
+			expectedUsername:   "conf-user",
+			expectedPassword:   "conf-pass",
+			expectedScrapeURI:  "http://conf-uri",
+			expectedTimeout:    5 * time.Second,
+			expectedIsHWBroker: false,
+			expectedDetect:     true,
+		},
+		{
+			name:               "isHWBroker param overrides a detecting base",
+			queryParams:        map[string]string{"isHWBroker": "true"},
+			base:               exporter.Config{Username: "conf-user", Password: "conf-pass", ScrapeURI: "http://conf-uri", Timeout: 5 * time.Second, DetectHWBroker: true},
+			expectedUsername:   "conf-user",
+			expectedPassword:   "conf-pass",
+			expectedScrapeURI:  "http://conf-uri",
+			expectedTimeout:    5 * time.Second,
+			expectedIsHWBroker: true,
+		},
 		{
 			name:               "absent isHWBroker falls back to config",
 			base:               exporter.Config{Username: "conf-user", Password: "conf-pass", ScrapeURI: "http://conf-uri", Timeout: 5 * time.Second, IsHWBroker: true},
@@ -219,6 +242,9 @@ func TestResolveRequestConfig(t *testing.T) {
 			if reqConf.IsHWBroker != tt.expectedIsHWBroker {
 				t.Errorf("IsHWBroker: expected %v, got %v", tt.expectedIsHWBroker, reqConf.IsHWBroker)
 			}
+			if reqConf.DetectHWBroker != tt.expectedDetect {
+				t.Errorf("DetectHWBroker: expected %v, got %v", tt.expectedDetect, reqConf.DetectHWBroker)
+			}
 
 			// The shared base Config must NOT be mutated by request resolution.
 			if base.Username != tt.base.Username || base.Password != tt.base.Password ||
@@ -259,3 +285,44 @@ func TestFirstNonEmpty(t *testing.T) {
 		})
 	}
 }
+
+func TestScrapeContext(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tests := []struct {
+		name         string
+		header       string
+		wantDeadline time.Duration // 0: no deadline
+	}{
+		{"no header", "", 0},
+		{"invalid", "soon", 0},
+		{"negative", "-1", 0},
+		{"offset kept", "10", 9500 * time.Millisecond},
+		{"fractional", "2.5", 2 * time.Second},
+		{"too short for offset", "0.8", 800 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/solace", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", tt.header)
+			}
+			start := time.Now()
+			ctx, cancel := scrapeContext(req, logger)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if tt.wantDeadline == 0 {
+				if ok {
+					t.Errorf("got deadline in %s, want none", deadline.Sub(start))
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("got no deadline, want %s", tt.wantDeadline)
+			}
+			if got := deadline.Sub(start); got < tt.wantDeadline || got > tt.wantDeadline+time.Second {
+				t.Errorf("got deadline in %s, want %s", got, tt.wantDeadline)
+			}
+		})
+	}
+}
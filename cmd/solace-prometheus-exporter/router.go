@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"solace_exporter/internal/exporter"
+	"solace_exporter/internal/secret"
+	"solace_exporter/internal/web"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	configLastReloadSuccessful = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "solace_exporter_config_last_reload_successful",
+		Help: "Whether the last configuration reload attempt was successful.",
+	})
+	configLastReloadSuccessTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "solace_exporter_config_last_reload_success_timestamp_seconds",
+		Help: "Timestamp of the last successful configuration reload.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(configLastReloadSuccessful, configLastReloadSuccessTimestamp)
+}
+
+// router serves all HTTP endpoints of the exporter. Everything that depends on the config file lives in a
+// routerState, which a reload builds completely and then swaps in atomically, so a request always sees one
+// consistent config.
+type router struct {
+	ctx            context.Context
+	configFile     string
+	secretResolver *secret.Resolver
+	logger         *slog.Logger
+
+	// reloadMu serializes reloads; requests never take it.
+	reloadMu sync.Mutex
+	state    atomic.Pointer[routerState]
+
+	// prefetchLoops tracks the loops of all AsyncFetchers started, until they stopped.
+	prefetchLoops sync.WaitGroup
+}
+
+// routerState is an immutable snapshot of the routing table built from one config.
+type routerState struct {
+	conf       *exporter.Config
+	endpoints  map[string][]exporter.DataSource
+	handlers   map[string]http.Handler
+	prefetches map[string]*prefetchEndpoint
+	index      http.Handler
+}
+
+// prefetchEndpoint owns the AsyncFetchers of one endpoint and the context that stops them.
+type prefetchEndpoint struct {
+	dataSource string
+	fetchers   *targetFetchers
+	cancel     context.CancelFunc
+}
+
+func newRouter(ctx context.Context, configFile string, secretResolver *secret.Resolver, logger *slog.Logger) *router {
+	return &router{
+		ctx:            ctx,
+		configFile:     configFile,
+		secretResolver: secretResolver,
+		logger:         logger,
+	}
+}
+
+// reload re-reads the config file and resolves its secrets. Only if both succeed the new routing table replaces the
+// current one; otherwise the exporter keeps serving the previous config.
+func (rt *router) reload() error {
+	err := rt.doReload()
+	if err != nil {
+		configLastReloadSuccessful.Set(0)
+		rt.logger.Error("Error reloading config, keeping the previous one", "err", err)
+		return err
+	}
+
+	rt.logger.Info("Reloaded config", "configFile", rt.configFile)
+	return nil
+}
+
+func (rt *router) doReload() error {
+	endpoints, conf, err := exporter.ParseConfig(rt.configFile)
+	if err != nil {
+		return fmt.Errorf("parsing config: %w", err)
+	}
+	if err := conf.ResolveSecrets(rt.ctx, rt.secretResolver); err != nil {
+		return fmt.Errorf("resolving vault-backed config: %w", err)
+	}
+
+	if previous := rt.state.Load(); previous != nil {
+		if conf.ListenAddr != previous.conf.ListenAddr || conf.EnableTLS != previous.conf.EnableTLS {
+			rt.logger.Warn("Changes of listenAddr and enableTLS need a restart to take effect")
+		}
+		if conf.SecretBackend != previous.conf.SecretBackend || conf.SecretCacheTTL != previous.conf.SecretCacheTTL {
+			rt.logger.Warn("Changes of secretBackend and secretCacheTTL need a restart to take effect")
+		}
+	}
+
+	rt.apply(endpoints, conf)
+	return nil
+}
+
+// apply builds the routing table for endpoints and conf and swaps it in. Prefetch loops of endpoints whose
+// datasources and scrape settings did not change are carried over with their cached metrics; all others are stopped.
+func (rt *router) apply(endpoints map[string][]exporter.DataSource, conf *exporter.Config) {
+	rt.reloadMu.Lock()
+	defer rt.reloadMu.Unlock()
+
+	previous := rt.state.Load()
+	state := rt.buildState(endpoints, conf, previous)
+	rt.state.Store(state)
+
+	if previous != nil {
+		for urlPath, prefetch := range previous.prefetches {
+			if state.prefetches[urlPath] != prefetch {
+				rt.logger.Info("Stop prefetching", "handler", "/"+urlPath)
+				prefetch.cancel()
+			}
+		}
+	}
+
+	configLastReloadSuccessful.Set(1)
+	configLastReloadSuccessTimestamp.SetToCurrentTime()
+}
+
+func (rt *router) buildState(endpoints map[string][]exporter.DataSource, conf *exporter.Config, previous *routerState) *routerState {
+	logger := rt.logger
+	state := &routerState{
+		conf:       conf,
+		endpoints:  endpoints,
+		handlers:   make(map[string]http.Handler, len(endpoints)),
+		prefetches: make(map[string]*prefetchEndpoint),
+	}
+
+	for _, name := range conf.BrokerNames() {
+		logger.Info("Register broker target", "target", name, "scrapeURI", conf.Brokers[name].ScrapeURI)
+	}
+
+	keepPrefetches := previous != nil && previous.conf.SameScrapeSettings(conf)
+
+	for urlPath, dataSource := range endpoints {
+		endpointConf := conf.ForEndpoint(urlPath)
+		if endpointConf.PrefetchInterval.Seconds() > 0 {
+			var prefetch *prefetchEndpoint
+			if keepPrefetches {
+				if old, ok := previous.prefetches[urlPath]; ok && old.dataSource == logDataSource(dataSource) {
+					prefetch = old
+				}
+			}
+			if prefetch == nil {
+				logger.Info("Register handler from config", "handler", "/"+urlPath, "dataSource", logDataSource(dataSource))
+				prefetch = rt.startPrefetch(urlPath, dataSource, endpointConf)
+			}
+			state.prefetches[urlPath] = prefetch
+			state.handlers[urlPath] = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				asyncFetcher, err := prefetch.fetchers.get(endpointConf, requestTarget(r))
+				if err != nil {
+					logger.Error("Error selecting broker target", "handler", "/"+urlPath, "err", err)
+					http.Error(w, err.Error(), http.StatusNotFound)
+					return
+				}
+				doHandleAsync(w, r, asyncFetcher, conf)
+			})
+		} else {
+			logger.Info("Register handler from config", "handler", "/"+urlPath, "dataSource", logDataSource(dataSource))
+			state.handlers[urlPath] = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				doHandle(w, r, dataSource, endpointConf, rt.secretResolver, logger)
+			})
+		}
+	}
+
+	endpointViews := make([]web.EndpointView, 0, len(endpoints))
+	for urlPath, dataSources := range endpoints {
+		endpointViews = append(endpointViews, web.EndpointView{
+			Path: urlPath,
+			Meta: logDataSource(dataSources),
+		})
+	}
+
+	index, err := web.NewHandler(web.TemplateData{
+		IsHWBroker:     conf.IsHWBroker,
+		DetectHWBroker: conf.DetectHWBroker,
+		Endpoints:      endpointViews,
+		Targets:        conf.BrokerNames(),
+	})
+	if err != nil {
+		logger.Error(err.Error())
+	}
+	state.index = web.WrapWithAuth(index, conf.ExporterAuth)
+
+	return state
+}
+
+// startPrefetch starts prefetching the [solace] broker for urlPath right away; [broker.<name>] targets start once
+// they are first requested. All of them stop when the returned prefetchEndpoint is canceled.
+func (rt *router) startPrefetch(urlPath string, dataSource []exporter.DataSource, conf *exporter.Config) *prefetchEndpoint {
+	ctx, cancel := context.WithCancel(rt.ctx)
+	fetchers := newTargetFetchers(func(targetConf *exporter.Config) *exporter.AsyncFetcher {
+		fetcher := exporter.NewAsyncFetcher(ctx, urlPath, dataSource, targetConf, rt.logger)
+		rt.prefetchLoops.Go(func() { <-fetcher.Done() })
+		return fetcher
+	})
+	if _, err := fetchers.get(conf, ""); err != nil {
+		rt.logger.Error("Can not start prefetching", "handler", "/"+urlPath, "err", err)
+	}
+
+	return &prefetchEndpoint{
+		dataSource: logDataSource(dataSource),
+		fetchers:   fetchers,
+		cancel:     cancel,
+	}
+}
+
+// waitPrefetches waits until the loops of all AsyncFetchers stopped, after rt.ctx is done, or until ctx is done.
+func (rt *router) waitPrefetches(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		rt.prefetchLoops.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The probes answer before the config is loaded, and without exporter auth, like Kubernetes probes expect.
+	switch r.URL.Path {
+	case "/healthz":
+		rt.handleHealthz(w, r)
+		return
+	case "/readyz":
+		rt.handleReadyz(w, r)
+		return
+	}
+
+	state := rt.state.Load()
+	if state == nil {
+		http.Error(w, "config not loaded yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/metrics":
+		doHandle(w, r, nil, state.conf, rt.secretResolver, rt.logger)
+	case "/solace":
+		if err := r.ParseForm(); err != nil {
+			rt.logger.Error("Can not parse the request parameter", "err", err)
+			return
+		}
+		doHandle(w, r, parseDataSources(r.Form, rt.logger), state.conf, rt.secretResolver, rt.logger)
+	case "/-/reload":
+		web.WrapWithAuth(http.HandlerFunc(rt.handleReload), state.conf.ExporterAuth).ServeHTTP(w, r)
+	default:
+		if handler, ok := state.handlers[strings.TrimPrefix(r.URL.Path, "/")]; ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		state.index.ServeHTTP(w, r)
+	}
+}
+
+// handleReload triggers a reload like SIGHUP does. Like Prometheus, it only accepts POST and PUT.
+func (rt *router) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		w.Header().Set("Allow", "POST, PUT")
+		http.Error(w, "only POST or PUT requests allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := rt.reload(); err != nil {
+		http.Error(w, fmt.Sprintf("failed to reload config: %s", err), http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write([]byte("config reloaded\n"))
+}
+
+// reloadOn calls reload for every signal received until ctx is done.
+func (rt *router) reloadOn(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			start := time.Now()
+			if err := rt.reload(); err == nil {
+				rt.logger.Debug("Config reload done", "duration", time.Since(start))
+			}
+		}
+	}
+}
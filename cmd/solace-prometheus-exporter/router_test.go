@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func writeRouterConfig(t *testing.T, path string, scrapeURI string, prefetch string, endpoints string) {
+	t.Helper()
+	ini := "[solace]\nscrapeUri=" + scrapeURI + "\nusername=user-1\npassword=pass-1\nprefetchInterval=" + prefetch + "\n\n" + endpoints
+	if err := os.WriteFile(path, []byte(ini), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRouterReloadSwapsEndpoints verifies that /-/reload picks up added and removed endpoints, and that a broken
+// config file keeps the previous routing table while reporting the failed reload.
+//
+//nolint:paralleltest // the reload gauges are process-wide
+func TestRouterReloadSwapsEndpoints(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	broker := newMockBroker(t, 1)
+	configFile := filepath.Join(t.TempDir(), "solace.ini")
+	writeRouterConfig(t, configFile, broker.server.URL, "0s", "[endpoint.first]\nQueueDetails=*|*\n")
+
+	rt := newRouter(context.Background(), configFile, newTestResolver(t), logger)
+	if err := rt.reload(); err != nil {
+		t.Fatalf("initial reload: %v", err)
+	}
+
+	get := func(path string) int {
+		rr := httptest.NewRecorder()
+		rt.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr.Code
+	}
+	reload := func(method string) int {
+		rr := httptest.NewRecorder()
+		rt.ServeHTTP(rr, httptest.NewRequest(method, "/-/reload", nil))
+		return rr.Code
+	}
+
+	if _, ok := rt.state.Load().handlers["first"]; !ok {
+		t.Fatal("endpoint 'first' not registered")
+	}
+	if code := get("/first"); code != http.StatusOK {
+		t.Errorf("GET /first = %d, want 200", code)
+	}
+
+	writeRouterConfig(t, configFile, broker.server.URL, "0s", "[endpoint.second]\nQueueDetails=*|*\n")
+	if code := reload(http.MethodGet); code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /-/reload = %d, want 405", code)
+	}
+	if code := reload(http.MethodPost); code != http.StatusOK {
+		t.Fatalf("POST /-/reload = %d, want 200", code)
+	}
+	handlers := rt.state.Load().handlers
+	if _, ok := handlers["first"]; ok {
+		t.Error("endpoint 'first' should be removed after reload")
+	}
+	if _, ok := handlers["second"]; !ok {
+		t.Error("endpoint 'second' should be added after reload")
+	}
+	if got := testutil.ToFloat64(configLastReloadSuccessful); got != 1 {
+		t.Errorf("last reload successful = %v, want 1", got)
+	}
+
+	// A config without scrapeUri is invalid: the previous routing table must stay in place.
+	if err := os.WriteFile(configFile, []byte("[solace]\nusername=a\npassword=b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if code := reload(http.MethodPost); code != http.StatusInternalServerError {
+		t.Errorf("POST /-/reload with broken config = %d, want 500", code)
+	}
+	if _, ok := rt.state.Load().handlers["second"]; !ok {
+		t.Error("a failed reload must keep the previous endpoints")
+	}
+	if got := testutil.ToFloat64(configLastReloadSuccessful); got != 0 {
+		t.Errorf("last reload successful = %v, want 0", got)
+	}
+}
+
+// TestRouterReloadKeepsUnchangedPrefetches verifies that a reload keeps the prefetch loops (and thereby the cached
+// metrics) of unchanged endpoints, and stops the ones of changed endpoints.
+//
+//nolint:paralleltest // the reload gauges are process-wide
+func TestRouterReloadKeepsUnchangedPrefetches(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	broker := newMockBroker(t, 1)
+	configFile := filepath.Join(t.TempDir(), "solace.ini")
+	writeRouterConfig(t, configFile, broker.server.URL, "1h", "[endpoint.keep]\nQueueDetails=*|*\n\n[endpoint.change]\nQueueDetails=*|*\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rt := newRouter(ctx, configFile, newTestResolver(t), logger)
+	if err := rt.reload(); err != nil {
+		t.Fatalf("initial reload: %v", err)
+	}
+	before := rt.state.Load().prefetches
+
+	writeRouterConfig(t, configFile, broker.server.URL, "1h", "[endpoint.keep]\nQueueDetails=*|*\n\n[endpoint.change]\nQueueDetails=*|q*\n")
+	if err := rt.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	after := rt.state.Load().prefetches
+
+	if after["keep"] != before["keep"] {
+		t.Error("prefetch of unchanged endpoint 'keep' should be carried over")
+	}
+	if after["change"] == before["change"] {
+		t.Error("prefetch of changed endpoint 'change' should be restarted")
+	}
+
+	// Changing a scrape setting restarts every prefetch loop.
+	writeRouterConfig(t, configFile, broker.server.URL, "2h", "[endpoint.keep]\nQueueDetails=*|*\n\n[endpoint.change]\nQueueDetails=*|q*\n")
+	if err := rt.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if rt.state.Load().prefetches["keep"] == after["keep"] {
+		t.Error("prefetch should be restarted when the prefetch interval changes")
+	}
+}
+
+// TestShutdownStopsPrefetchesAndServer verifies that a shutdown stops the prefetch loops and no longer accepts scrapes.
+//
+//nolint:paralleltest // the reload gauges are process-wide
+func TestShutdownStopsPrefetchesAndServer(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	broker := newMockBroker(t, 1)
+	configFile := filepath.Join(t.TempDir(), "solace.ini")
+	writeRouterConfig(t, configFile, broker.server.URL, "1h", "[endpoint.pre]\nQueueDetails=*|*\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rt := newRouter(ctx, configFile, newTestResolver(t), logger)
+	if err := rt.reload(); err != nil {
+		t.Fatalf("initial reload: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: rt, ReadHeaderTimeout: time.Second}
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	url := "http://" + listener.Addr().String() + "/pre"
+	resp, err := http.Get(url) //nolint:noctx // test request
+	if err != nil {
+		t.Fatalf("scrape before shutdown: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	shutdown(server, rt, cancel, func() {}, logger)
+
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("Serve returned %v, want http.ErrServerClosed", err)
+	}
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer waitCancel()
+	if err := rt.waitPrefetches(waitCtx); err != nil {
+		t.Errorf("prefetch loops still running after shutdown: %v", err)
+	}
+	if resp, err := http.Get(url); err == nil { //nolint:noctx // test request
+		_ = resp.Body.Close()
+		t.Error("server still accepts scrapes after shutdown")
+	}
+}
@@ -9,6 +9,7 @@ require (
 	github.com/prometheus/common v0.70.1
 	golang.org/x/oauth2 v0.36.0
 	golang.org/x/sync v0.22.0
+	golang.org/x/time v0.12.0
 	gopkg.in/ini.v1 v1.67.3
 )
 
@@ -32,7 +33,6 @@ require (
 	golang.org/x/crypto v0.54.0 // indirect
 	golang.org/x/net v0.57.0 // indirect
 	golang.org/x/text v0.40.0 // indirect
-	golang.org/x/time v0.12.0 // indirect
 )
 
 require (
@@ -2,13 +2,16 @@ package exporter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"slices"
 	"solace_exporter/internal/semp"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"golang.org/x/sync/semaphore"
 )
 
 const (
@@ -19,49 +22,132 @@ const (
 	metricCacheChunkSize = 100
 )
 
-func NewAsyncFetcher(ctx context.Context, urlPath string, dataSource []DataSource, conf *Config, logger *slog.Logger, connections *semaphore.Weighted) *AsyncFetcher {
+// NewAsyncFetcher starts fetching dataSource until ctx is done. Each data source is fetched every RefreshInterval, or
+// every conf.PrefetchInterval if it sets none; the data sources sharing an interval are fetched together. The first
+// fetch of every interval is delayed by a random startJitter, so that the fetchers do not all hit the broker at once.
+// The SEMP connections are bounded per data source by the broker's parallelSempConnections budget, see
+// Exporter.CollectPrometheusMetric.
+func NewAsyncFetcher(ctx context.Context, urlPath string, dataSource []DataSource, conf *Config, logger *slog.Logger) *AsyncFetcher {
 	var fetcher = &AsyncFetcher{
 		dataSource: dataSource,
 		conf:       conf,
 		logger:     logger,
 		metrics:    make(map[string]semp.PrometheusMetric),
-		exporter:   NewExporter(ctx, logger, conf, &dataSource),
+		sources:    make(map[string]int),
+		retention:  make([]dataSourceRetention, len(dataSource)),
+		exporter:   NewExporter(ctx, logger, conf, urlPath, &dataSource),
+		handler:    "/" + urlPath,
+		done:       make(chan struct{}),
 	}
+	groups := newFetchGroups(ctx, urlPath, dataSource, conf, logger, time.Now())
+	fetcher.groups = groups
+	prefetchHealth.register(ctx, fetcher)
 
 	collectWorker := func() {
-		ticker := time.NewTicker(conf.PrefetchInterval)
-		defer ticker.Stop()
+		defer close(fetcher.done)
+		timer := time.NewTimer(untilNextFetch(groups, time.Now()))
+		defer timer.Stop()
 
 		for {
-			if err := connections.Acquire(ctx, 1); err != nil {
-				logger.Error("Failed to acquire semaphore", "handler", "/"+urlPath, "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
 
-				select {
-				case <-ctx.Done():
-					return
-				case <-time.After(time.Second):
+			for _, group := range groups {
+				if now := time.Now(); group.next.After(now) {
 					continue
 				}
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Debug("Fetching for handler", "handler", fetcher.handler, "interval", group.interval)
+
+				readMetrics(ctx, fetcher, group)
+				group.scheduleNext(time.Now())
 			}
 
-			logger.Debug("Fetching for handler", "handler", "/"+urlPath)
+			timer.Reset(untilNextFetch(groups, time.Now()))
+		}
+	}
 
-			readMetrics(fetcher)
+	go collectWorker()
 
-			connections.Release(1)
+	return fetcher
+}
 
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				continue
-			}
+// maxPrefetchStartJitter bounds the delay of the first fetch, so that endpoints with long intervals still serve
+// metrics soon after startup.
+const maxPrefetchStartJitter = 10 * time.Second
+
+// startJitter returns a random delay of up to a tenth of interval, at most maxPrefetchStartJitter.
+func startJitter(interval time.Duration) time.Duration {
+	maxJitter := min(interval/10, maxPrefetchStartJitter)
+	if maxJitter <= 0 {
+		return 0
+	}
+	return rand.N(maxJitter)
+}
+
+// fetchGroup holds the data sources of an AsyncFetcher that share a refresh interval. They are fetched together by
+// their own Exporter, and have their own fetchHealth.
+type fetchGroup struct {
+	interval time.Duration
+	// indexes maps the data sources of exporter to their index in AsyncFetcher.dataSource.
+	indexes  []int
+	exporter *Exporter
+	next     time.Time
+	health   fetchHealth
+}
+
+// newFetchGroups groups dataSource by refresh interval, in the order of their first data source. The first fetch of
+// each group is due at now plus startJitter.
+func newFetchGroups(ctx context.Context, urlPath string, dataSource []DataSource, conf *Config, logger *slog.Logger, now time.Time) []*fetchGroup {
+	var groups []*fetchGroup
+	byInterval := make(map[time.Duration]*fetchGroup)
+	groupDataSources := make(map[*fetchGroup]*[]DataSource)
+
+	for index, ds := range dataSource {
+		interval := conf.PrefetchInterval
+		if ds.RefreshInterval > 0 {
+			interval = ds.RefreshInterval
 		}
+		group, ok := byInterval[interval]
+		if !ok {
+			group = &fetchGroup{interval: interval, next: now.Add(startJitter(interval))}
+			byInterval[interval] = group
+			groupDataSources[group] = &[]DataSource{}
+			groups = append(groups, group)
+		}
+		group.indexes = append(group.indexes, index)
+		*groupDataSources[group] = append(*groupDataSources[group], ds)
 	}
 
-	go collectWorker()
+	for _, group := range groups {
+		group.exporter = NewExporter(ctx, logger, conf, urlPath, groupDataSources[group])
+	}
+	return groups
+}
 
-	return fetcher
+// scheduleNext schedules the next fetch one interval after the last one was due. Fetches missed, because the last one
+// took too long, are skipped like ticks of a time.Ticker.
+func (group *fetchGroup) scheduleNext(now time.Time) {
+	group.next = group.next.Add(group.interval)
+	if !group.next.After(now) {
+		group.next = now.Add(group.interval)
+	}
+}
+
+// untilNextFetch returns the time until the next group is due, 0 if one is due already.
+func untilNextFetch(groups []*fetchGroup, now time.Time) time.Duration {
+	var next time.Time
+	for _, group := range groups {
+		if next.IsZero() || group.next.Before(next) {
+			next = group.next
+		}
+	}
+	return max(next.Sub(now), 0)
 }
 
 type AsyncFetcher struct {
@@ -70,13 +156,52 @@ type AsyncFetcher struct {
 	conf       *Config
 	logger     *slog.Logger
 	metrics    map[string]semp.PrometheusMetric
-	exporter   *Exporter
+	// sources maps the series in metrics to the index of the data source that emitted them last.
+	sources   map[string]int
+	retention []dataSourceRetention
+	exporter  *Exporter
+	handler   string
+	groups    []*fetchGroup
+	done      chan struct{}
+}
+
+// Fetched reports whether every data source of f was fetched at least once, successfully or not.
+func (f *AsyncFetcher) Fetched() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, retention := range f.retention {
+		if retention.lastSuccess.IsZero() && retention.failures == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Done returns a channel that is closed once the fetcher stopped, after its context is done. A fetch running then is
+// cancelled and finishes first.
+func (f *AsyncFetcher) Done() <-chan struct{} {
+	return f.done
+}
+
+// dataSourceRetention tracks the failed fetches of one data source, to decide whether the series it did not emit in
+// the last fetch are retained. See Config.PrefetchRetainIntervals and Config.PrefetchRetainMaxAge.
+type dataSourceRetention struct {
+	lastSuccess time.Time
+	// failures counts the failed fetches since lastSuccess.
+	failures    int64
+	retained    bool
+	staleSeries int
 }
 
-func readMetrics(f *AsyncFetcher) {
-	var metricsChan = make(chan semp.PrometheusMetric, capMetricChan)
+// readMetrics fetches the data sources of group and replaces their series. The series of the other data sources are
+// kept as they are.
+func readMetrics(ctx context.Context, f *AsyncFetcher, group *fetchGroup) {
+	var metricsChan = make(chan dataSourceMetric, capMetricChan)
+	var fetchErrs []error
 
-	f.DeprecateAll()
+	f.deprecateDataSources(group.indexes)
+	start := group.health.start()
 
 	go func() {
 		defer close(metricsChan)
@@ -84,36 +209,60 @@ func readMetrics(f *AsyncFetcher) {
 		defer func() {
 			if r := recover(); r != nil {
 				f.logger.Error("recovered from panic while scraping broker (async)", "panic", r)
+				fetchErrs = make([]error, len(group.indexes))
+				for index := range fetchErrs {
+					fetchErrs[index] = fmt.Errorf("recovered from panic: %v", r)
+				}
 			}
 		}()
-		f.exporter.CollectPrometheusMetric(metricsChan)
+		fetchErrs = group.exporter.collectDataSources(ctx, metricsChan)
 	}()
 
 	// read from channel until the channel is closed
-	cache := make([]semp.PrometheusMetric, 0, metricCacheChunkSize)
+	cache := make([]dataSourceMetric, 0, metricCacheChunkSize)
 	for metric := range metricsChan {
+		metric.dataSource = group.indexes[metric.dataSource]
 		cache = append(cache, metric)
 		if len(cache) >= metricCacheChunkSize {
 			// Update cache by chunks to provide updated metrics as early as possible
 			f.Merge(cache)
-			cache = make([]semp.PrometheusMetric, 0, metricCacheChunkSize)
+			cache = make([]dataSourceMetric, 0, metricCacheChunkSize)
 		}
 	}
 
 	f.Merge(cache)
+
+	// metricsChan is closed after fetchErrs was set.
+	f.updateRetention(group.indexes, fetchErrs, time.Now())
 	f.DeleteDeprecated()
+
+	var errs []error
+	for index, err := range fetchErrs {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.dataSource[group.indexes[index]].Name, err))
+		}
+	}
+	fetchErr := errors.Join(errs...)
+	group.health.finish(start, fetchErr)
+	if fetchErr != nil {
+		f.logger.Debug("Fetch for handler failed", "handler", f.handler, "err", fetchErr)
+	}
 }
 
 func (f *AsyncFetcher) Describe(desc chan<- *prometheus.Desc) {
 	f.exporter.Describe(desc)
 }
 
+// Collect serves the metrics of the last fetch, and the number of series retained per data source.
 func (f *AsyncFetcher) Collect(metrics chan<- prometheus.Metric) {
 	f.mutex.Lock()
-	copiedMetrics := make([]prometheus.Metric, 0, len(f.metrics))
+	copiedMetrics := make([]prometheus.Metric, 0, len(f.metrics)+len(f.retention))
 	for _, metric := range f.metrics {
 		copiedMetrics = append(copiedMetrics, metric.AsPrometheusMetric())
 	}
+	for _, metric := range f.staleSeries() {
+		copiedMetrics = append(copiedMetrics, metric.AsPrometheusMetric())
+	}
 	f.mutex.Unlock()
 
 	for _, metric := range copiedMetrics {
@@ -121,6 +270,28 @@ func (f *AsyncFetcher) Collect(metrics chan<- prometheus.Metric) {
 	}
 }
 
+// staleSeries returns the number of series retained per data source, while retention is enabled. They are counted per
+// data source, not per name, so that two data sources of the same name, like QueueStats of two VPNs, are told apart.
+// The caller must hold f.mutex.
+func (f *AsyncFetcher) staleSeries() []semp.PrometheusMetric {
+	if !f.retentionEnabled() {
+		return nil
+	}
+
+	counts := make(map[string]int, len(f.retention))
+	names := make(map[string]string, len(f.retention))
+	for index, retention := range f.retention {
+		dataSource := f.dataSource[index].String()
+		counts[dataSource] += retention.staleSeries
+		names[dataSource] = f.dataSource[index].Name
+	}
+	metrics := make([]semp.PrometheusMetric, 0, len(counts))
+	for dataSource, count := range counts {
+		metrics = append(metrics, f.exporter.semp.NewMetric(semp.MetricDesc["Global"]["stale_series"], prometheus.GaugeValue, float64(count), names[dataSource], dataSource))
+	}
+	return metrics
+}
+
 func (f *AsyncFetcher) DeprecateAll() {
 	f.mutex.Lock()
 	for key, metric := range f.metrics {
@@ -130,20 +301,71 @@ func (f *AsyncFetcher) DeprecateAll() {
 	f.mutex.Unlock()
 }
 
-func (f *AsyncFetcher) Merge(cache []semp.PrometheusMetric) {
+// deprecateDataSources deprecates the series emitted by the data sources with the given indexes.
+func (f *AsyncFetcher) deprecateDataSources(indexes []int) {
+	f.mutex.Lock()
+	for key, metric := range f.metrics {
+		if index, ok := f.sources[key]; ok && slices.Contains(indexes, index) {
+			metric.Deprecate()
+			f.metrics[key] = metric
+		}
+	}
+	f.mutex.Unlock()
+}
+
+func (f *AsyncFetcher) Merge(cache []dataSourceMetric) {
 	f.mutex.Lock()
 	for _, metric := range cache {
-		f.metrics[metric.Name()] = metric
+		name := metric.metric.Name()
+		f.metrics[name] = metric.metric
+		f.sources[name] = metric.dataSource
 	}
 	f.mutex.Unlock()
 }
 
+func (f *AsyncFetcher) retentionEnabled() bool {
+	return f.conf != nil && (f.conf.PrefetchRetainIntervals > 0 || f.conf.PrefetchRetainMaxAge > 0)
+}
+
+// updateRetention decides for the data sources with the given indexes whether the series they did not emit in the
+// fetch that ended at now are retained, fetchErrs holding their errors in the same order: a failed data source keeps
+// them for up to PrefetchRetainIntervals failed fetches and PrefetchRetainMaxAge since its last successful fetch,
+// whichever of the limits are set.
+func (f *AsyncFetcher) updateRetention(indexes []int, fetchErrs []error, now time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i, index := range indexes {
+		retention := &f.retention[index]
+		if i >= len(fetchErrs) || fetchErrs[i] == nil {
+			*retention = dataSourceRetention{lastSuccess: now}
+			continue
+		}
+
+		retention.failures++
+		retention.retained = f.retentionEnabled() && !retention.lastSuccess.IsZero() &&
+			(f.conf.PrefetchRetainIntervals <= 0 || retention.failures <= f.conf.PrefetchRetainIntervals) &&
+			(f.conf.PrefetchRetainMaxAge <= 0 || now.Sub(retention.lastSuccess) <= f.conf.PrefetchRetainMaxAge)
+	}
+}
+
+// DeleteDeprecated deletes the series that were not emitted by the last fetch, except those of data sources whose
+// series are retained. The up metric is never retained, the last fetch always reports it.
 func (f *AsyncFetcher) DeleteDeprecated() {
 	f.mutex.Lock()
+	for index := range f.retention {
+		f.retention[index].staleSeries = 0
+	}
 	for key, metric := range f.metrics {
-		if metric.IsDeprecated() {
-			delete(f.metrics, key)
+		if !metric.IsDeprecated() {
+			continue
+		}
+		if index, ok := f.sources[key]; ok && f.retention[index].retained && metric.Desc() != semp.MetricDesc["Global"]["up"] {
+			f.retention[index].staleSeries++
+			continue
 		}
+		delete(f.metrics, key)
+		delete(f.sources, key)
 	}
 	f.mutex.Unlock()
 }
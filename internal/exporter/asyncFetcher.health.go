@@ -0,0 +1,123 @@
+package exporter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	prefetchLastSuccessDesc = prometheus.NewDesc(
+		"solace_exporter_prefetch_last_success_timestamp_seconds",
+		"Timestamp of the last prefetch in which all data sources were up; 0 if there was none yet.",
+		[]string{"handler", "broker", "interval"}, nil)
+	prefetchDurationDesc = prometheus.NewDesc(
+		"solace_exporter_prefetch_duration_seconds",
+		"Duration of the last finished prefetch.",
+		[]string{"handler", "broker", "interval"}, nil)
+	prefetchErrorsDesc = prometheus.NewDesc(
+		"solace_exporter_prefetch_errors_total",
+		"Prefetches in which at least one data source failed.",
+		[]string{"handler", "broker", "interval"}, nil)
+	prefetchRunningDesc = prometheus.NewDesc(
+		"solace_exporter_prefetch_running",
+		"Whether a prefetch is currently running.",
+		[]string{"handler", "broker", "interval"}, nil)
+)
+
+// prefetchHealth exposes the health of all running AsyncFetchers on /metrics.
+var prefetchHealth = &prefetchHealthCollector{fetchers: map[prefetchHealthKey]*AsyncFetcher{}}
+
+func init() {
+	prometheus.MustRegister(prefetchHealth)
+}
+
+// fetchHealth records the outcome of the fetches of one fetchGroup. It is updated by the fetch loop and read by
+// prefetchHealthCollector concurrently.
+type fetchHealth struct {
+	lastSuccess  atomic.Int64 // unix nanos
+	lastDuration atomic.Int64 // nanos
+	errors       atomic.Uint64
+	running      atomic.Bool
+}
+
+func (h *fetchHealth) start() time.Time {
+	h.running.Store(true)
+	return time.Now()
+}
+
+func (h *fetchHealth) finish(start time.Time, err error) {
+	now := time.Now()
+	h.lastDuration.Store(int64(now.Sub(start)))
+	if err != nil {
+		h.errors.Add(1)
+	} else {
+		h.lastSuccess.Store(now.UnixNano())
+	}
+	h.running.Store(false)
+}
+
+type prefetchHealthKey struct {
+	handler string
+	broker  string
+}
+
+// prefetchHealthCollector collects the fetchHealth of each fetch group of the AsyncFetchers whose context is not done
+// yet, labeled with the interval of the group. A fetcher that replaces another one for the same handler and broker,
+// e.g. after a config reload, takes over its series.
+type prefetchHealthCollector struct {
+	mu       sync.Mutex
+	fetchers map[prefetchHealthKey]*AsyncFetcher
+}
+
+// register adds f until ctx is done.
+func (c *prefetchHealthCollector) register(ctx context.Context, f *AsyncFetcher) {
+	key := prefetchHealthKey{handler: f.handler, broker: f.conf.ScrapeURI}
+
+	c.mu.Lock()
+	c.fetchers[key] = f
+	c.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.fetchers[key] == f {
+			delete(c.fetchers, key)
+		}
+	})
+}
+
+func (c *prefetchHealthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- prefetchLastSuccessDesc
+	ch <- prefetchDurationDesc
+	ch <- prefetchErrorsDesc
+	ch <- prefetchRunningDesc
+}
+
+func (c *prefetchHealthCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, f := range c.fetchers {
+		for _, group := range f.groups {
+			health := &group.health
+			var lastSuccess float64
+			if nanos := health.lastSuccess.Load(); nanos > 0 {
+				lastSuccess = float64(nanos) / float64(time.Second)
+			}
+			var running float64
+			if health.running.Load() {
+				running = 1
+			}
+			interval := group.interval.String()
+
+			ch <- prometheus.MustNewConstMetric(prefetchLastSuccessDesc, prometheus.GaugeValue, lastSuccess, key.handler, key.broker, interval)
+			ch <- prometheus.MustNewConstMetric(prefetchDurationDesc, prometheus.GaugeValue, time.Duration(health.lastDuration.Load()).Seconds(), key.handler, key.broker, interval)
+			ch <- prometheus.MustNewConstMetric(prefetchErrorsDesc, prometheus.CounterValue, float64(health.errors.Load()), key.handler, key.broker, interval)
+			ch <- prometheus.MustNewConstMetric(prefetchRunningDesc, prometheus.GaugeValue, running, key.handler, key.broker, interval)
+		}
+	}
+}
@@ -2,11 +2,14 @@ package exporter
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
 	"solace_exporter/internal/semp"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -14,7 +17,6 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
-	"golang.org/x/sync/semaphore"
 )
 
 func TestDeprecateAllAndDeleteDeprecated(t *testing.T) {
@@ -22,7 +24,7 @@ func TestDeprecateAllAndDeleteDeprecated(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
 	// Create a dummy Semp to create metrics
-	s := semp.NewSemp(logger, "http://localhost:8080", http.Client{}, nil, false, false)
+	s := semp.NewSemp(logger, "http://localhost:8080", http.Client{}, nil, false, false, nil, semp.RetryPolicy{}, nil)
 	desc := semp.NewSemDesc("test_metric", "test", "help", []string{"label"})
 
 	metric1 := s.NewMetric(desc, prometheus.GaugeValue, 1.0, "val1")
@@ -112,9 +114,8 @@ func TestNewAsyncFetcher(t *testing.T) {
 	}
 
 	ds := []DataSource{{Name: "QueueDetails"}}
-	connections := semaphore.NewWeighted(1)
 
-	fetcher := NewAsyncFetcher(ctx, "getQueueDetailsSemp1", ds, conf, logger, connections)
+	fetcher := NewAsyncFetcher(ctx, "getQueueDetailsSemp1", ds, conf, logger)
 
 	// state 0: ok
 	time.Sleep(100 * time.Millisecond) // Let it fetch
@@ -160,3 +161,251 @@ func TestNewAsyncFetcher(t *testing.T) {
 	// expect solace_up to be 1
 	checkUp(1.0)
 }
+
+func TestAsyncFetcherHealth(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`<rpc-reply semp-version="soltr/9_1_1VMR"><rpc><show><queue><queues><queue><name>q1</name><info><message-vpn>default</message-vpn></info></queue></queues></queue></show></rpc><execute-result code="ok"/></rpc-reply>`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	conf := &Config{PrefetchInterval: 50 * time.Millisecond, Timeout: 5 * time.Second, ScrapeURI: server.URL}
+	fetcher := NewAsyncFetcher(ctx, "healthTest", []DataSource{{Name: "QueueDetails"}}, conf, logger)
+
+	time.Sleep(100 * time.Millisecond)
+	lastSuccess := fetcher.groups[0].health.lastSuccess.Load()
+	if lastSuccess == 0 {
+		t.Fatal("no successful fetch recorded")
+	}
+	if got := fetcher.groups[0].health.errors.Load(); got != 0 {
+		t.Errorf("got %d fetch errors, want 0", got)
+	}
+
+	failing.Store(true)
+	time.Sleep(200 * time.Millisecond)
+	if fetcher.groups[0].health.errors.Load() == 0 {
+		t.Error("failed fetches were not counted")
+	}
+	if got := fetcher.groups[0].health.lastSuccess.Load(); got != lastSuccess {
+		t.Error("a failed fetch updated the last success timestamp")
+	}
+	if count := testutil.CollectAndCount(prefetchHealth, "solace_exporter_prefetch_errors_total"); count == 0 {
+		t.Error("the health of a running fetcher is not collected")
+	}
+
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	prefetchHealth.mu.Lock()
+	_, registered := prefetchHealth.fetchers[prefetchHealthKey{handler: "/healthTest", broker: server.URL}]
+	prefetchHealth.mu.Unlock()
+	if registered {
+		t.Error("the health of a stopped fetcher is still collected")
+	}
+}
+
+// TestPrefetchHealthPerFetchGroup checks that the fetch groups of a fetcher report their health separately, so that a
+// group succeeding does not hide the failures of another one.
+func TestPrefetchHealthPerFetchGroup(t *testing.T) {
+	t.Parallel()
+
+	fast := &fetchGroup{interval: time.Minute}
+	slow := &fetchGroup{interval: 10 * time.Minute}
+	slow.health.finish(slow.health.start(), errors.New("down"))
+	fast.health.finish(fast.health.start(), nil)
+
+	collector := &prefetchHealthCollector{fetchers: map[prefetchHealthKey]*AsyncFetcher{}}
+	collector.register(t.Context(), &AsyncFetcher{
+		handler: "/groups",
+		conf:    &Config{ScrapeURI: "http://broker"},
+		groups:  []*fetchGroup{fast, slow},
+	})
+
+	expected := `
+# HELP solace_exporter_prefetch_errors_total Prefetches in which at least one data source failed.
+# TYPE solace_exporter_prefetch_errors_total counter
+solace_exporter_prefetch_errors_total{broker="http://broker",handler="/groups",interval="10m0s"} 1
+solace_exporter_prefetch_errors_total{broker="http://broker",handler="/groups",interval="1m0s"} 0
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "solace_exporter_prefetch_errors_total"); err != nil {
+		t.Error(err)
+	}
+	if slow.health.lastSuccess.Load() != 0 {
+		t.Error("the success of one group was recorded for another")
+	}
+}
+
+func TestAsyncFetcherRetention(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	s := semp.NewSemp(logger, "http://localhost:8080", http.Client{}, nil, false, false, nil, semp.RetryPolicy{}, nil)
+	queue := semp.NewSemDesc("queue_msg_spooled", "test", "help", []string{"queue_name"})
+	up := semp.MetricDesc["Global"]["up"]
+
+	tests := []struct {
+		name         string
+		conf         Config
+		failures     int
+		age          time.Duration
+		wantRetained bool
+	}{
+		{name: "disabled", conf: Config{}, failures: 1, wantRetained: false},
+		{name: "within intervals", conf: Config{PrefetchRetainIntervals: 2}, failures: 2, wantRetained: true},
+		{name: "intervals exceeded", conf: Config{PrefetchRetainIntervals: 2}, failures: 3, wantRetained: false},
+		{name: "within max age", conf: Config{PrefetchRetainMaxAge: time.Minute}, failures: 5, age: 30 * time.Second, wantRetained: true},
+		{name: "max age exceeded", conf: Config{PrefetchRetainMaxAge: time.Minute}, failures: 1, age: 2 * time.Minute, wantRetained: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := &AsyncFetcher{
+				dataSource: []DataSource{{Name: "QueueStats"}, {Name: "VpnStats"}},
+				conf:       &tt.conf,
+				logger:     logger,
+				metrics:    make(map[string]semp.PrometheusMetric),
+				sources:    make(map[string]int),
+				retention:  make([]dataSourceRetention, 2),
+			}
+			now := time.Now()
+
+			// A successful fetch of both data sources.
+			f.Merge([]dataSourceMetric{
+				{dataSource: 0, metric: s.NewMetric(queue, prometheus.GaugeValue, 1, "q1")},
+				{dataSource: 0, metric: s.NewMetric(queue, prometheus.GaugeValue, 1, "q2")},
+				{dataSource: 0, metric: s.NewMetric(up, prometheus.GaugeValue, 1, "", "QueueStats")},
+				{dataSource: 1, metric: s.NewMetric(up, prometheus.GaugeValue, 1, "", "VpnStats")},
+			})
+			f.updateRetention([]int{0, 1}, []error{nil, nil}, now.Add(-tt.age))
+			f.DeleteDeprecated()
+
+			// QueueStats fails after the first page, VpnStats succeeds.
+			for i := range tt.failures {
+				f.DeprecateAll()
+				f.Merge([]dataSourceMetric{
+					{dataSource: 0, metric: s.NewMetric(queue, prometheus.GaugeValue, 2, "q1")},
+					{dataSource: 0, metric: s.NewMetric(up, prometheus.GaugeValue, 0, "page 2 failed", "QueueStats")},
+					{dataSource: 1, metric: s.NewMetric(up, prometheus.GaugeValue, 1, "", "VpnStats")},
+				})
+				f.updateRetention([]int{0, 1}, []error{errors.New("page 2 failed"), nil}, now.Add(time.Duration(i)*time.Millisecond))
+				f.DeleteDeprecated()
+			}
+
+			_, retained := f.metrics[`solace_queue_msg_spooled{queue_name="q2"}`]
+			if retained != tt.wantRetained {
+				t.Errorf("got series of the failed data source retained %v, want %v", retained, tt.wantRetained)
+			}
+			if _, ok := f.metrics[`solace_queue_msg_spooled{queue_name="q1"}`]; !ok {
+				t.Error("the series emitted before the failure was dropped")
+			}
+			if _, ok := f.metrics[`solace_up{error="",endpoint="QueueStats"}`]; ok {
+				t.Error("the up metric of the last successful fetch was retained")
+			}
+			if wantStale := map[bool]int{true: 1, false: 0}[tt.wantRetained]; f.retention[0].staleSeries != wantStale {
+				t.Errorf("got %d stale series, want %d", f.retention[0].staleSeries, wantStale)
+			}
+			if f.retention[1].retained {
+				t.Error("the successful data source is retained")
+			}
+		})
+	}
+}
+
+func TestAsyncFetcherStaleSeriesPerDataSource(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	s := semp.NewSemp(logger, "http://localhost:8080", http.Client{}, nil, false, false, nil, semp.RetryPolicy{}, nil)
+
+	f := &AsyncFetcher{
+		dataSource: []DataSource{{Name: "QueueStats", VpnFilter: "a", ItemFilter: "*"}, {Name: "QueueStats", VpnFilter: "b", ItemFilter: "*"}},
+		conf:       &Config{PrefetchRetainIntervals: 1},
+		exporter:   &Exporter{semp: s},
+		retention:  []dataSourceRetention{{staleSeries: 2}, {}},
+	}
+
+	var got []string
+	for _, metric := range f.staleSeries() {
+		got = append(got, metric.Name()+" "+strconv.FormatFloat(testutil.ToFloat64(&mockCollector{metric.AsPrometheusMetric()}), 'g', -1, 64))
+	}
+	slices.Sort(got)
+	want := []string{
+		`solace_stale_series{endpoint="QueueStats",data_source="QueueStats=a|*|"} 2`,
+		`solace_stale_series{endpoint="QueueStats",data_source="QueueStats=b|*|"} 0`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("stale series = %q, want %q", got, want)
+	}
+}
+
+func TestNewFetchGroups(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	conf := &Config{PrefetchInterval: time.Minute, ScrapeURI: "http://localhost:8080"}
+	dataSources := []DataSource{
+		{Name: "QueueDetails"},
+		{Name: "Version", RefreshInterval: 10 * time.Minute},
+		{Name: "QueueStats"},
+		{Name: "Redundancy", RefreshInterval: 10 * time.Minute},
+	}
+	now := time.Now()
+	groups := newFetchGroups(t.Context(), "test", dataSources, conf, logger, now)
+
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	for i, want := range []struct {
+		interval time.Duration
+		indexes  []int
+		names    []string
+	}{
+		{interval: time.Minute, indexes: []int{0, 2}, names: []string{"QueueDetails", "QueueStats"}},
+		{interval: 10 * time.Minute, indexes: []int{1, 3}, names: []string{"Version", "Redundancy"}},
+	} {
+		group := groups[i]
+		if group.interval != want.interval || !slices.Equal(group.indexes, want.indexes) {
+			t.Errorf("group %d = %s %v, want %s %v", i, group.interval, group.indexes, want.interval, want.indexes)
+		}
+		for j, dataSource := range *group.exporter.dataSource {
+			if dataSource.Name != want.names[j] {
+				t.Errorf("group %d data source %d = %s, want %s", i, j, dataSource.Name, want.names[j])
+			}
+		}
+		if jitter := group.next.Sub(now); jitter < 0 || jitter >= group.interval/10 {
+			t.Errorf("group %d first fetch after %s, want less than a tenth of %s", i, jitter, group.interval)
+		}
+	}
+	if got := untilNextFetch(groups, now.Add(time.Minute)); got != 0 {
+		t.Errorf("untilNextFetch = %s for overdue groups, want 0", got)
+	}
+}
+
+func TestFetchGroupScheduleNext(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	group := &fetchGroup{interval: time.Minute, next: start}
+
+	group.scheduleNext(start.Add(time.Second))
+	if want := start.Add(time.Minute); !group.next.Equal(want) {
+		t.Errorf("next = %s, want one interval after the last due time %s", group.next, want)
+	}
+
+	// A fetch that took longer than the interval skips the missed fetches.
+	late := start.Add(3*time.Minute + time.Second)
+	group.scheduleNext(late)
+	if want := late.Add(time.Minute); !group.next.Equal(want) {
+		t.Errorf("next = %s, want %s", group.next, want)
+	}
+
+	if jitter := startJitter(time.Hour); jitter < 0 || jitter >= maxPrefetchStartJitter {
+		t.Errorf("startJitter(1h) = %s, want less than %s", jitter, maxPrefetchStartJitter)
+	}
+}
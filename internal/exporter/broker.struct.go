@@ -0,0 +1,288 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"solace_exporter/internal/secret"
+
+	"gopkg.in/ini.v1"
+)
+
+// ErrUnknownTarget is returned by Config.ForTarget when no [broker.<name>] section matches the requested target.
+var ErrUnknownTarget = errors.New("unknown broker target")
+
+// brokerCredentialKeys are the keys of a [broker.<name>] section that define how to authenticate to the broker. If a
+// section sets none of them, the broker inherits the [solace] credentials as a whole; otherwise it uses only its own,
+// so a broker with basic auth never silently picks up a half of the global OAuth settings.
+var brokerCredentialKeys = []string{"username", "password", "oAuthTokenURL", "oAuthClientID", "oAuthClientSecret", "oAuthClientScope", "oAuthIssuer"}
+
+// BrokerConfig holds the connection settings of one named [broker.<name>] section. A scrape request selects it via
+// `?target=<name>`, so credentials live in the exporter config instead of the Prometheus scrape config.
+type BrokerConfig struct {
+	Name              string
+	ScrapeURI         string
+	BackupScrapeURI   string
+	Username          string
+	Password          string `json:"-"`
+	OAuthTokenURL     string
+	OAuthClientID     string
+	OAuthClientSecret string `json:"-"`
+	OAuthClientScope  string
+	OAuthIssuer       string
+	IsHWBroker        bool
+	DetectHWBroker    bool
+	Timeout           time.Duration
+	oAuthToken        *oAuthTokenCache
+	authType          AuthType
+}
+
+// ForTarget returns a per-request copy of conf pointing at the named broker. An empty target returns a plain
+// Clone, i.e. the broker of the [solace] section. The OAuth token cache of the broker is shared, like in Clone.
+func (conf *Config) ForTarget(target string) (*Config, error) {
+	c := conf.Clone()
+	if target == "" {
+		return c, nil
+	}
+
+	broker, ok := conf.Brokers[target]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTarget, target)
+	}
+
+	c.ScrapeURI = broker.ScrapeURI
+	c.BackupScrapeURI = broker.BackupScrapeURI
+	c.Username = broker.Username
+	c.Password = broker.Password
+	c.OAuthTokenURL = broker.OAuthTokenURL
+	c.OAuthClientID = broker.OAuthClientID
+	c.OAuthClientSecret = broker.OAuthClientSecret
+	c.OAuthClientScope = broker.OAuthClientScope
+	c.OAuthIssuer = broker.OAuthIssuer
+	c.IsHWBroker = broker.IsHWBroker
+	c.DetectHWBroker = broker.DetectHWBroker
+	c.Timeout = broker.Timeout
+	c.oAuthToken = broker.oAuthToken
+	c.authType = broker.authType
+	c.applyEndpoint()
+
+	return c, nil
+}
+
+// BrokerNames returns the names of all configured [broker.<name>] sections in sorted order.
+func (conf *Config) BrokerNames() []string {
+	names := make([]string, 0, len(conf.Brokers))
+	for name := range conf.Brokers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// resolveSecrets resolves "vault:<path>#<field>" references among the broker credentials in place and determines the
+// auth type afterward, see Config.ResolveSecrets.
+func (broker *BrokerConfig) resolveSecrets(ctx context.Context, resolver *secret.Resolver) error {
+	fields := []struct {
+		name string
+		val  *string
+	}{
+		{"username", &broker.Username},
+		{"password", &broker.Password},
+		{"oAuthClientSecret", &broker.OAuthClientSecret},
+	}
+
+	for _, f := range fields {
+		resolved, err := resolver.Resolve(ctx, *f.val)
+		if err != nil {
+			return fmt.Errorf("broker %q: resolving %s: %w", broker.Name, f.name, err)
+		}
+		*f.val = resolved
+	}
+
+	return broker.DetermineAuthType()
+}
+
+// DetermineAuthType sets the auth type of the broker from its credential fields, see Config.DetermineAuthType.
+func (broker *BrokerConfig) DetermineAuthType() error {
+	authType, err := determineAuthType(broker.Username, broker.Password, broker.OAuthTokenURL, broker.OAuthClientID, broker.OAuthClientSecret, broker.OAuthClientScope)
+	if err != nil {
+		return fmt.Errorf("broker %q: %w", broker.Name, err)
+	}
+	broker.authType = authType
+
+	return nil
+}
+
+// parseBrokers reads all [broker.<name>] sections. Timeout and isHWBroker default to the [solace] values, the
+// credentials are inherited only if the section configures none of brokerCredentialKeys.
+func parseBrokers(cfg *ini.File, conf *Config) (map[string]*BrokerConfig, error) {
+	brokers := make(map[string]*BrokerConfig)
+	if cfg == nil {
+		return brokers, nil
+	}
+
+	for _, section := range cfg.Sections() {
+		if !strings.HasPrefix(section.Name(), "broker.") {
+			continue
+		}
+		sectionName := section.Name()
+		brokerName := strings.TrimPrefix(sectionName, "broker.")
+
+		broker := &BrokerConfig{
+			Name:           brokerName,
+			IsHWBroker:     conf.IsHWBroker,
+			DetectHWBroker: conf.DetectHWBroker,
+			Timeout:        conf.Timeout,
+			oAuthToken:     &oAuthTokenCache{},
+		}
+
+		broker.ScrapeURI = iniKeyValue(cfg, sectionName, "scrapeUri")
+		if broker.ScrapeURI == "" {
+			return nil, fmt.Errorf("broker %q: config param \"scrapeUri\" is mandetory", brokerName)
+		}
+		broker.BackupScrapeURI = iniKeyValue(cfg, sectionName, "backupScrapeUri")
+
+		hasOwnCredentials := false
+		for _, key := range brokerCredentialKeys {
+			if iniKeyValue(cfg, sectionName, key) != "" {
+				hasOwnCredentials = true
+				break
+			}
+		}
+		if hasOwnCredentials {
+			broker.Username = iniKeyValue(cfg, sectionName, "username")
+			broker.Password = iniKeyValue(cfg, sectionName, "password")
+			broker.OAuthTokenURL = iniKeyValue(cfg, sectionName, "oAuthTokenURL")
+			broker.OAuthClientID = iniKeyValue(cfg, sectionName, "oAuthClientID")
+			broker.OAuthClientSecret = iniKeyValue(cfg, sectionName, "oAuthClientSecret")
+			broker.OAuthClientScope = iniKeyValue(cfg, sectionName, "oAuthClientScope")
+			broker.OAuthIssuer = iniKeyValue(cfg, sectionName, "oAuthIssuer")
+		} else {
+			broker.Username = conf.Username
+			broker.Password = conf.Password
+			broker.OAuthTokenURL = conf.OAuthTokenURL
+			broker.OAuthClientID = conf.OAuthClientID
+			broker.OAuthClientSecret = conf.OAuthClientSecret
+			broker.OAuthClientScope = conf.OAuthClientScope
+			broker.OAuthIssuer = conf.OAuthIssuer
+		}
+
+		if v := iniKeyValue(cfg, sectionName, "isHWBroker"); v != "" {
+			isHWBroker, detect, err := ParseIsHWBroker(v)
+			if err != nil {
+				return nil, fmt.Errorf("broker %q: config param \"isHWBroker\" is invalid: %w", brokerName, err)
+			}
+			broker.IsHWBroker, broker.DetectHWBroker = isHWBroker, detect
+		}
+		if v := iniKeyValue(cfg, sectionName, "timeout"); v != "" {
+			timeout, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("broker %q: config param \"timeout\" is invalid: %w", brokerName, err)
+			}
+			if timeout <= 0 {
+				return nil, fmt.Errorf("broker %q: config param \"timeout\" must be positive", brokerName)
+			}
+			broker.Timeout = timeout
+		}
+
+		// Same fail-fast presence check as for [solace]; ResolveSecrets runs it again after vault resolution.
+		if err := broker.DetermineAuthType(); err != nil {
+			return nil, err
+		}
+
+		brokers[brokerName] = broker
+	}
+
+	return brokers, nil
+}
+
+// scrapeSettings is the part of Config that decides which broker a prefetch loop scrapes and how.
+type scrapeSettings struct {
+	ScrapeURI               string
+	BackupScrapeURI         string
+	Username                string
+	Password                string
+	DefaultVpn              string
+	VpnDiscoveryInterval    time.Duration
+	SslVerify               bool
+	Timeout                 time.Duration
+	PrefetchInterval        time.Duration
+	PrefetchRetainIntervals int64
+	PrefetchRetainMaxAge    time.Duration
+	ParallelSempConnections int64
+	SempRequestsPerSecond   int64
+	SempRetries             int64
+	SempRetryBackoff        time.Duration
+	CircuitBreakerFailures  int64
+	CircuitBreakerCooldown  time.Duration
+	ScrapeCacheTTL          time.Duration
+	logBrokerToSlowWarnings bool
+	IsHWBroker              bool
+	DetectHWBroker          bool
+	SempPageSize            int64
+	OAuthTokenURL           string
+	OAuthClientID           string
+	OAuthClientSecret       string
+	OAuthClientScope        string
+	OAuthIssuer             string
+}
+
+func (conf *Config) scrapeSettings() scrapeSettings {
+	return scrapeSettings{
+		ScrapeURI:               conf.ScrapeURI,
+		BackupScrapeURI:         conf.BackupScrapeURI,
+		Username:                conf.Username,
+		Password:                conf.Password,
+		DefaultVpn:              conf.DefaultVpn,
+		VpnDiscoveryInterval:    conf.VpnDiscoveryInterval,
+		SslVerify:               conf.SslVerify,
+		Timeout:                 conf.Timeout,
+		PrefetchInterval:        conf.PrefetchInterval,
+		PrefetchRetainIntervals: conf.PrefetchRetainIntervals,
+		PrefetchRetainMaxAge:    conf.PrefetchRetainMaxAge,
+		ParallelSempConnections: conf.ParallelSempConnections,
+		SempRequestsPerSecond:   conf.SempRequestsPerSecond,
+		SempRetries:             conf.SempRetries,
+		SempRetryBackoff:        conf.SempRetryBackoff,
+		CircuitBreakerFailures:  conf.CircuitBreakerFailures,
+		CircuitBreakerCooldown:  conf.CircuitBreakerCooldown,
+		ScrapeCacheTTL:          conf.ScrapeCacheTTL,
+		logBrokerToSlowWarnings: conf.logBrokerToSlowWarnings,
+		IsHWBroker:              conf.IsHWBroker,
+		DetectHWBroker:          conf.DetectHWBroker,
+		SempPageSize:            conf.SempPageSize,
+		OAuthTokenURL:           conf.OAuthTokenURL,
+		OAuthClientID:           conf.OAuthClientID,
+		OAuthClientSecret:       conf.OAuthClientSecret,
+		OAuthClientScope:        conf.OAuthClientScope,
+		OAuthIssuer:             conf.OAuthIssuer,
+	}
+}
+
+// SameScrapeSettings reports whether conf and other scrape the same brokers in the same way, ignoring listener and
+// exporter auth settings. A config reload uses it to decide whether running prefetch loops can be kept.
+func (conf *Config) SameScrapeSettings(other *Config) bool {
+	if conf.scrapeSettings() != other.scrapeSettings() || len(conf.Brokers) != len(other.Brokers) ||
+		!sameEndpoints(conf.Endpoints, other.Endpoints) {
+		return false
+	}
+
+	for name, broker := range conf.Brokers {
+		otherBroker, ok := other.Brokers[name]
+		if !ok {
+			return false
+		}
+		a, b := *broker, *otherBroker
+		a.oAuthToken, b.oAuthToken = nil, nil
+		if a != b {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,111 @@
+package exporter
+
+import (
+	"fmt"
+	"path"
+	"solace_exporter/internal/semp"
+	"sort"
+	"strings"
+)
+
+// CheckEndpoints validates every data source of endpoints against the known scrape targets, without contacting a
+// broker. It reports unknown targets, hardware only targets on software brokers and vice versa (for [solace] and
+// every [broker.<name>] section that does not detect its type), metric filters on SEMP v1 targets, metric filters a SEMP v2 target does not know
+// and, with VPN discovery, malformed VPN globs of per VPN SEMP v2 targets.
+// Each problem is one line, ordered by endpoint name; an empty result means the endpoints are valid.
+func (conf *Config) CheckEndpoints(endpoints map[string][]DataSource) []string {
+	endpointNames := make([]string, 0, len(endpoints))
+	for name := range endpoints {
+		endpointNames = append(endpointNames, name)
+	}
+	sort.Strings(endpointNames)
+
+	type broker struct {
+		name       string
+		isHWBroker bool
+	}
+	// The type of brokers with isHWBroker=auto is unknown until they are scraped.
+	var brokers []broker
+	if !conf.DetectHWBroker {
+		brokers = append(brokers, broker{name: "[solace]", isHWBroker: conf.IsHWBroker})
+	}
+	for _, name := range conf.BrokerNames() {
+		if !conf.Brokers[name].DetectHWBroker {
+			brokers = append(brokers, broker{name: "[broker." + name + "]", isHWBroker: conf.Brokers[name].IsHWBroker})
+		}
+	}
+
+	var problems []string
+	for _, endpointName := range endpointNames {
+		for _, dataSource := range endpoints[endpointName] {
+			report := func(format string, args ...any) {
+				problems = append(problems, fmt.Sprintf("endpoint %q: %s: ", endpointName, dataSource.Name)+fmt.Sprintf(format, args...))
+			}
+
+			descriptor, ok := semp.LookupDataSource(dataSource.Name)
+			if !ok {
+				report("unknown scrape target. Please check documentation for valid targets")
+				continue
+			}
+
+			for _, b := range brokers {
+				if !descriptor.Platform.Supports(b.isHWBroker) {
+					report("%s only scrape target, but %s has isHWBroker=%t", descriptor.Platform, b.name, b.isHWBroker)
+				}
+			}
+
+			if err := descriptor.CheckMetricFilter(dataSource.MetricFilter); err != nil {
+				report("%s", err)
+			}
+
+			if descriptor.PerVpn() && conf.VpnDiscoveryInterval > 0 {
+				if _, err := path.Match(dataSource.VpnFilter, ""); err != nil {
+					report("invalid vpnFilter glob %q: %s", dataSource.VpnFilter, err)
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// VersionRequirements lists the data sources of endpoints, and their metrics, that not every SolOS version supports.
+// Each line names a version and what needs at least that version, ordered by version; older brokers skip them, see
+// semp.Capability. An empty result means all data sources work with every broker version.
+func (conf *Config) VersionRequirements(endpoints map[string][]DataSource) []string {
+	var requirements = make(map[string][]string)
+	var versions []semp.Version
+	var seen = make(map[string]bool)
+	for _, dataSources := range endpoints {
+		for _, dataSource := range dataSources {
+			descriptor, ok := semp.LookupDataSource(dataSource.Name)
+			if !ok || seen[descriptor.Name] {
+				continue
+			}
+			seen[descriptor.Name] = true
+
+			for _, capability := range descriptor.Capabilities() {
+				target := descriptor.Name
+				if capability.Metric != "" {
+					target += " metric " + capability.Metric
+				}
+				version := capability.MinVersion.String()
+				if _, ok := requirements[version]; !ok {
+					versions = append(versions, capability.MinVersion)
+				}
+				requirements[version] = append(requirements[version], target)
+			}
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Compare(versions[j]) < 0
+	})
+	lines := make([]string, 0, len(versions))
+	for _, version := range versions {
+		targets := requirements[version.String()]
+		sort.Strings(targets)
+		lines = append(lines, fmt.Sprintf("SolOS %s or later: %s", version, strings.Join(targets, ", ")))
+	}
+	return lines
+}
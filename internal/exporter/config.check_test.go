@@ -0,0 +1,89 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckEndpoints(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                 string
+		isHWBroker           bool
+		vpnDiscoveryInterval time.Duration
+		brokers              map[string]*BrokerConfig
+		endpoints            map[string][]DataSource
+		want                 []string // substrings, one per expected problem line, in order
+	}{
+		{
+			name: "valid endpoints",
+			endpoints: map[string][]DataSource{
+				"std": {{Name: "Version"}, {Name: "HealthV1"}, {Name: "MqttSession"}},
+				"v2":  {{Name: "QueueStatsV2", MetricFilter: []string{"solace_queue_msg_shutdown_discarded", "spooledMsgCount"}}},
+			},
+		},
+		{
+			name: "unknown targets are reported per endpoint in name order",
+			endpoints: map[string][]DataSource{
+				"b": {{Name: "QueueStat"}},
+				"a": {{Name: "Version"}, {Name: "MqttSessionV1"}},
+			},
+			want: []string{`endpoint "a": MqttSessionV1: unknown scrape target`, `endpoint "b": QueueStat: unknown scrape target`},
+		},
+		{
+			name:       "software only target on hardware broker",
+			isHWBroker: true,
+			endpoints:  map[string][]DataSource{"std": {{Name: "Health"}, {Name: "Disk"}}},
+			want:       []string{`Health: software only scrape target, but [solace] has isHWBroker=true`},
+		},
+		{
+			name:      "hardware only target on a software broker target",
+			brokers:   map[string]*BrokerConfig{"sw": {Name: "sw"}},
+			endpoints: map[string][]DataSource{"hw": {{Name: "Alarm"}}},
+			want: []string{
+				`Alarm: appliance only scrape target, but [solace] has isHWBroker=false`,
+				`Alarm: appliance only scrape target, but [broker.sw] has isHWBroker=false`,
+			},
+		},
+		{
+			name: "metric filters",
+			endpoints: map[string][]DataSource{
+				"f": {
+					{Name: "QueueStatsV2", MetricFilter: []string{"solace_queue_typo"}},
+					{Name: "QueueStats", MetricFilter: []string{"solace_queue_msg_spooled"}},
+				},
+			},
+			want: []string{
+				`QueueStatsV2: invalid metric filter: item "solace_queue_typo" is not valid`,
+				`QueueStats: metric filters are only supported by SEMP v2 targets`,
+			},
+		},
+		{
+			name:                 "vpn globs with vpn discovery",
+			vpnDiscoveryInterval: time.Minute,
+			endpoints: map[string][]DataSource{
+				"v2": {{Name: "QueueStatsV2", VpnFilter: "prod-[a"}, {Name: "QueueStatsV2", VpnFilter: "prod-*"}, {Name: "Vpn", VpnFilter: "prod-[a"}},
+			},
+			want: []string{`QueueStatsV2: invalid vpnFilter glob "prod-[a"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &Config{IsHWBroker: tt.isHWBroker, VpnDiscoveryInterval: tt.vpnDiscoveryInterval, Brokers: tt.brokers}
+			got := conf.CheckEndpoints(tt.endpoints)
+			if len(got) != len(tt.want) {
+				t.Fatalf("CheckEndpoints() = %q, want %d problems", got, len(tt.want))
+			}
+			for i := range got {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
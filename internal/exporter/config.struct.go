@@ -52,15 +52,27 @@ type Config struct {
 	Pkcs12File              string `json:"-"`
 	Pkcs12Pass              string `json:"-"`
 	ScrapeURI               string
+	BackupScrapeURI         string
 	Username                string
 	Password                string `json:"-"`
 	DefaultVpn              string
+	VpnDiscoveryInterval    time.Duration
 	SslVerify               bool
 	Timeout                 time.Duration
 	PrefetchInterval        time.Duration
+	PrefetchRetainIntervals int64
+	PrefetchRetainMaxAge    time.Duration
 	ParallelSempConnections int64
+	SempRequestsPerSecond   int64
+	SempRetries             int64
+	SempRetryBackoff        time.Duration
+	CircuitBreakerFailures  int64
+	CircuitBreakerCooldown  time.Duration
+	ScrapeCacheTTL          time.Duration
+	ReadinessProbeBroker    bool
 	logBrokerToSlowWarnings bool
 	IsHWBroker              bool
+	DetectHWBroker          bool
 	SempPageSize            int64
 	OAuthTokenURL           string
 	OAuthClientID           string
@@ -72,10 +84,15 @@ type Config struct {
 	ExporterAuth            ExporterAuthConfig
 	SecretBackend           string
 	SecretCacheTTL          time.Duration
+	Brokers                 map[string]*BrokerConfig
+	Endpoints               map[string]*EndpointConfig
+	// endpoint is the endpoint whose settings ForEndpoint applied, so that ForTarget keeps them.
+	endpoint *EndpointConfig
 }
 
 // Clone returns a shallow copy of Config safe to mutate per request. Scalar fields are copied by value; oAuthToken
-// is shared by pointer on purpose so the cached OAuth token is reused across requests.
+// is shared by pointer on purpose so the cached OAuth token is reused across requests. Brokers and Endpoints are shared
+// as well and must be treated as read-only.
 func (conf *Config) Clone() *Config {
 	c := *conf
 	return &c
@@ -108,6 +125,12 @@ func (conf *Config) ResolveSecrets(ctx context.Context, resolver *secret.Resolve
 		*f.val = resolved
 	}
 
+	for _, name := range conf.BrokerNames() {
+		if err := conf.Brokers[name].resolveSecrets(ctx, resolver); err != nil {
+			return err
+		}
+	}
+
 	// Determine auth type AFTER vault resolution so that vault-backed
 	// username/password/oAuthClientSecret are checked against their
 	// actual values, not the raw "vault:..." references.
@@ -118,23 +141,33 @@ func (conf *Config) ResolveSecrets(ctx context.Context, resolver *secret.Resolve
 // fields. It must be called after ResolveSecrets (or after manually
 // setting the credential fields) so that vault refs have been resolved.
 func (conf *Config) DetermineAuthType() error {
-	anyOAuth := len(conf.OAuthClientID) > 0 || len(conf.OAuthClientSecret) > 0 || len(conf.OAuthTokenURL) > 0 || len(conf.OAuthClientScope) > 0
-	allOAuth := len(conf.OAuthClientID) > 0 && len(conf.OAuthClientSecret) > 0 && len(conf.OAuthTokenURL) > 0 && len(conf.OAuthClientScope) > 0
+	authType, err := determineAuthType(conf.Username, conf.Password, conf.OAuthTokenURL, conf.OAuthClientID, conf.OAuthClientSecret, conf.OAuthClientScope)
+	if err != nil {
+		return err
+	}
+	conf.authType = authType
+
+	return nil
+}
+
+// determineAuthType picks OAuth when all four OAuth fields are set and basic auth when username and password are
+// set. Shared by the [solace] section and the [broker.<name>] sections.
+func determineAuthType(username, password, oAuthTokenURL, oAuthClientID, oAuthClientSecret, oAuthClientScope string) (AuthType, error) {
+	anyOAuth := len(oAuthClientID) > 0 || len(oAuthClientSecret) > 0 || len(oAuthTokenURL) > 0 || len(oAuthClientScope) > 0
+	allOAuth := len(oAuthClientID) > 0 && len(oAuthClientSecret) > 0 && len(oAuthTokenURL) > 0 && len(oAuthClientScope) > 0
 
 	if anyOAuth && !allOAuth {
-		return errors.New("incomplete OAuth configuration: oAuthClientID, oAuthClientSecret, oAuthTokenURL and oAuthClientScope must all be set")
+		return 0, errors.New("incomplete OAuth configuration: oAuthClientID, oAuthClientSecret, oAuthTokenURL and oAuthClientScope must all be set")
 	}
 
 	switch {
 	case allOAuth:
-		conf.authType = AuthTypeOAuth
-	case len(conf.Username) > 0 && len(conf.Password) > 0:
-		conf.authType = AuthTypeBasic
+		return AuthTypeOAuth, nil
+	case len(username) > 0 && len(password) > 0:
+		return AuthTypeBasic, nil
 	default:
-		return errors.New("either basic auth (username+password) or OAuth (oAuthClientID+oAuthClientSecret+oAuthTokenURL+oAuthClientScope) must be configured")
+		return 0, errors.New("either basic auth (username+password) or OAuth (oAuthClientID+oAuthClientSecret+oAuthTokenURL+oAuthClientScope) must be configured")
 	}
-
-	return nil
 }
 
 const (
@@ -202,6 +235,7 @@ func ParseConfig(configFile string) (map[string][]DataSource, *Config, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	conf.BackupScrapeURI = parseConfigStringOptional(cfg, "solace", "backupScrapeUri", "SOLACE_BACKUP_SCRAPE_URI", "")
 	conf.DefaultVpn = parseConfigStringOptional(cfg, "solace", "defaultVpn", "SOLACE_DEFAULT_VPN", "default")
 	conf.Timeout, err = parseConfigDurationOptional(cfg, "solace", "timeout", "SOLACE_TIMEOUT", 5*time.Second)
 	if err != nil {
@@ -211,6 +245,18 @@ func ParseConfig(configFile string) (map[string][]DataSource, *Config, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	conf.PrefetchRetainIntervals, err = parseConfigIntOptional(cfg, "solace", "prefetchRetainIntervals", "PREFETCH_RETAIN_INTERVALS", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.PrefetchRetainMaxAge, err = parseConfigDurationOptional(cfg, "solace", "prefetchRetainMaxAge", "PREFETCH_RETAIN_MAX_AGE", 0*time.Second)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.VpnDiscoveryInterval, err = parseConfigDurationOptional(cfg, "solace", "vpnDiscoveryInterval", "SOLACE_VPN_DISCOVERY_INTERVAL", 0*time.Second)
+	if err != nil {
+		return nil, nil, err
+	}
 	conf.SslVerify, err = parseConfigBoolOptional(cfg, "solace", "sslVerify", "SOLACE_SSL_VERIFY", false)
 	if err != nil {
 		return nil, nil, err
@@ -219,14 +265,42 @@ func ParseConfig(configFile string) (map[string][]DataSource, *Config, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	conf.logBrokerToSlowWarnings, err = parseConfigBoolOptional(cfg, "solace", "logBrokerToSlowWarnings", "SOLACE_LOG_BROKER_IS_SLOW_WARNING", true)
+	conf.SempRequestsPerSecond, err = parseConfigIntOptional(cfg, "solace", "sempRequestsPerSecond", "SOLACE_SEMP_REQUESTS_PER_SECOND", 10)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.SempRetries, err = parseConfigIntOptional(cfg, "solace", "sempRetries", "SOLACE_SEMP_RETRIES", 2)
 	if err != nil {
 		return nil, nil, err
 	}
-	conf.IsHWBroker, err = parseConfigBoolOptional(cfg, "solace", "isHWBroker", "SOLACE_IS_HW_BROKER", false)
+	conf.SempRetryBackoff, err = parseConfigDurationOptional(cfg, "solace", "sempRetryBackoff", "SOLACE_SEMP_RETRY_BACKOFF", 200*time.Millisecond)
 	if err != nil {
 		return nil, nil, err
 	}
+	conf.CircuitBreakerFailures, err = parseConfigIntOptional(cfg, "solace", "circuitBreakerFailures", "SOLACE_CIRCUIT_BREAKER_FAILURES", 5)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.CircuitBreakerCooldown, err = parseConfigDurationOptional(cfg, "solace", "circuitBreakerCooldown", "SOLACE_CIRCUIT_BREAKER_COOLDOWN", 30*time.Second)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.ScrapeCacheTTL, err = parseConfigDurationOptional(cfg, "solace", "scrapeCacheTTL", "SOLACE_SCRAPE_CACHE_TTL", 0*time.Second)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.ReadinessProbeBroker, err = parseConfigBoolOptional(cfg, "solace", "readinessProbeBroker", "SOLACE_READINESS_PROBE_BROKER", false)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.logBrokerToSlowWarnings, err = parseConfigBoolOptional(cfg, "solace", "logBrokerToSlowWarnings", "SOLACE_LOG_BROKER_IS_SLOW_WARNING", true)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.IsHWBroker, conf.DetectHWBroker, err = ParseIsHWBroker(parseConfigStringOptional(cfg, "solace", "isHWBroker", "SOLACE_IS_HW_BROKER", "auto"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("config param \"isHWBroker\" and env param \"SOLACE_IS_HW_BROKER\" is invalid: %w", err)
+	}
 	conf.SempPageSize, err = parseConfigIntOptional(cfg, "solace", "sempPageSize", "SOLACE_SEMP_PAGE_SIZE", 100)
 	if err != nil {
 		return nil, nil, err
@@ -256,36 +330,64 @@ func ParseConfig(configFile string) (map[string][]DataSource, *Config, error) {
 		conf.ParallelSempConnections = 2
 	}
 
+	if conf.SempRetries < 0 {
+		conf.SempRetries = 0
+	}
+
 	if conf.SempPageSize < 1 {
 		conf.SempPageSize = 100
 	}
 
+	conf.Brokers, err = parseBrokers(cfg, conf)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	endpoints := make(map[string][]DataSource)
+	conf.Endpoints = make(map[string]*EndpointConfig)
 	if cfg != nil {
 		var scrapeTargetRe = regexp.MustCompile(`^(\w+)(\.\d+)?$`)
 		for _, section := range cfg.Sections() {
 			if strings.HasPrefix(section.Name(), "endpoint.") {
 				endpointName := strings.TrimPrefix(section.Name(), "endpoint.")
 
+				endpoint, err := parseEndpointSettings(section, endpointName)
+				if err != nil {
+					return nil, nil, err
+				}
+				conf.Endpoints[endpointName] = endpoint
+
 				var dataSource []DataSource
 				for _, key := range section.Keys() {
+					if endpointSettingKeys[key.Name()] {
+						continue
+					}
 					scrapeTarget := scrapeTargetRe.ReplaceAllString(key.Name(), `$1`)
 
 					parts := strings.Split(key.String(), "|")
-					if len(parts) < 2 {
-						return nil, nil, fmt.Errorf("one or two | expected at endpoint %q. Found key %q value %q. Expected: VPN wildcard | item wildcard | Optional metric filter for v2 apis", endpointName, key.Name(), key.String())
+					if len(parts) < 2 || len(parts) > 4 {
+						return nil, nil, fmt.Errorf("one to three | expected at endpoint %q. Found key %q value %q. Expected: VPN wildcard | item wildcard | Optional metric filter for v2 apis | Optional refresh interval", endpointName, key.Name(), key.String())
 					}
 
 					var metricFilter []string
-					if len(parts) == 3 && len(strings.TrimSpace(parts[2])) > 0 {
+					if len(parts) >= 3 && len(strings.TrimSpace(parts[2])) > 0 {
 						metricFilter = strings.Split(parts[2], ",")
 					}
 
+					var refreshInterval time.Duration
+					if len(parts) == 4 && len(strings.TrimSpace(parts[3])) > 0 {
+						refreshInterval, err = time.ParseDuration(strings.TrimSpace(parts[3]))
+						if err != nil || refreshInterval <= 0 {
+							return nil, nil, fmt.Errorf("invalid refresh interval at endpoint %q. Found key %q value %q. Expected a positive duration like 5m", endpointName, key.Name(), key.String())
+						}
+					}
+
 					dataSource = append(dataSource, DataSource{
-						Name:         scrapeTarget,
-						VpnFilter:    parts[0],
-						ItemFilter:   parts[1],
-						MetricFilter: metricFilter,
+						Name:            scrapeTarget,
+						VpnFilter:       parts[0],
+						ItemFilter:      parts[1],
+						MetricFilter:    metricFilter,
+						RefreshInterval: refreshInterval,
 					})
 				}
 
@@ -325,6 +427,17 @@ func parseConfigBoolOptional(cfg *ini.File, iniSection string, iniKey string, en
 	return val, nil
 }
 
+// ParseIsHWBroker parses an isHWBroker setting: a boolean, or "auto" to detect the broker type. For auto, isHWBroker is
+// false until detected.
+func ParseIsHWBroker(value string) (isHWBroker bool, detect bool, err error) {
+	if strings.EqualFold(strings.TrimSpace(value), "auto") {
+		return false, true, nil
+	}
+
+	isHWBroker, err = strconv.ParseBool(value)
+	return isHWBroker, false, err
+}
+
 func parseConfigDuration(cfg *ini.File, iniSection string, iniKey string, envKey string) (time.Duration, error) {
 	s, err := parseConfigString(cfg, iniSection, iniKey, envKey)
 	if err != nil {
@@ -1,6 +1,7 @@
 package exporter
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -58,6 +59,15 @@ func TestParseConfigBasicAuthFromEnv(t *testing.T) {
 	if conf.SempPageSize != 100 {
 		t.Errorf("default SempPageSize = %d, want 100", conf.SempPageSize)
 	}
+	if conf.SempRequestsPerSecond != 10 {
+		t.Errorf("default SempRequestsPerSecond = %d, want 10", conf.SempRequestsPerSecond)
+	}
+	if conf.SempRetries != 2 || conf.SempRetryBackoff != 200*time.Millisecond {
+		t.Errorf("default SempRetries/SempRetryBackoff = %d/%s, want 2/200ms", conf.SempRetries, conf.SempRetryBackoff)
+	}
+	if conf.CircuitBreakerFailures != 5 || conf.CircuitBreakerCooldown != 30*time.Second {
+		t.Errorf("default CircuitBreakerFailures/CircuitBreakerCooldown = %d/%s, want 5/30s", conf.CircuitBreakerFailures, conf.CircuitBreakerCooldown)
+	}
 	if len(endpoints) != 0 {
 		t.Errorf("expected no endpoints without a config file, got %v", endpoints)
 	}
@@ -141,3 +151,195 @@ Vpn=*|*
 		t.Errorf("endpoint 'std' has %d datasources, want 2 (%v)", len(ds), ds)
 	}
 }
+
+func TestParseConfigBrokersFromIni(t *testing.T) {
+	clearSolaceEnv(t)
+	dir := t.TempDir()
+	iniPath := filepath.Join(dir, "solace.ini")
+	ini := `[solace]
+scrapeUri=http://broker:8080
+backupScrapeUri=http://broker-backup:8080
+username=monitor
+password=secret
+timeout=5s
+
+[broker.own]
+scrapeUri=http://own:8080
+backupScrapeUri=http://own-backup:8080
+username=own-user
+password=own-pass
+isHWBroker=true
+timeout=12s
+
+[broker.inherited]
+scrapeURI=http://inherited:8080
+`
+	if err := os.WriteFile(iniPath, []byte(ini), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, conf, err := ParseConfig(iniPath)
+	if err != nil {
+		t.Fatalf("ParseConfig error: %v", err)
+	}
+	if got := conf.BrokerNames(); len(got) != 2 || got[0] != "inherited" || got[1] != "own" {
+		t.Fatalf("BrokerNames() = %v, want [inherited own]", got)
+	}
+
+	own, err := conf.ForTarget("own")
+	if err != nil {
+		t.Fatalf("ForTarget(own) error: %v", err)
+	}
+	if own.ScrapeURI != "http://own:8080" || own.Username != "own-user" || own.Password != "own-pass" || !own.IsHWBroker || own.Timeout != 12*time.Second {
+		t.Errorf("unexpected broker config for own: %+v", struct {
+			S, U, P string
+			H       bool
+			T       time.Duration
+		}{own.ScrapeURI, own.Username, own.Password, own.IsHWBroker, own.Timeout})
+	}
+	if own.DetectHWBroker {
+		t.Error("broker with isHWBroker=true must not detect its type")
+	}
+	if own.BackupScrapeURI != "http://own-backup:8080" {
+		t.Errorf("BackupScrapeURI of own = %q, want http://own-backup:8080", own.BackupScrapeURI)
+	}
+	if own.oAuthToken == conf.oAuthToken {
+		t.Error("broker must not share the OAuth token cache of the [solace] broker")
+	}
+
+	inherited, err := conf.ForTarget("inherited")
+	if err != nil {
+		t.Fatalf("ForTarget(inherited) error: %v", err)
+	}
+	if inherited.ScrapeURI != "http://inherited:8080" || inherited.Username != "monitor" || inherited.Password != "secret" || inherited.Timeout != 5*time.Second {
+		t.Errorf("broker without credentials must inherit [solace] settings, got %+v", struct{ S, U, P string }{inherited.ScrapeURI, inherited.Username, inherited.Password})
+	}
+	if !inherited.DetectHWBroker || inherited.IsHWBroker {
+		t.Error("broker without isHWBroker must detect its type, like the [solace] broker by default")
+	}
+	if inherited.BackupScrapeURI != "" {
+		t.Errorf("the backup of the [solace] broker is no mate of inherited, got BackupScrapeURI %q", inherited.BackupScrapeURI)
+	}
+
+	// The [solace] broker must be untouched by target selection.
+	if conf.ScrapeURI != "http://broker:8080" || conf.BackupScrapeURI != "http://broker-backup:8080" || conf.Username != "monitor" {
+		t.Errorf("base config was mutated: %+v", struct{ S, U string }{conf.ScrapeURI, conf.Username})
+	}
+
+	if _, err := conf.ForTarget("missing"); !errors.Is(err, ErrUnknownTarget) {
+		t.Errorf("ForTarget(missing) error = %v, want ErrUnknownTarget", err)
+	}
+}
+
+func TestParseConfigBrokerWithPartialCredentialsFails(t *testing.T) {
+	clearSolaceEnv(t)
+	dir := t.TempDir()
+	iniPath := filepath.Join(dir, "solace.ini")
+	// Setting only a username must not fall back to the [solace] password.
+	ini := `[solace]
+scrapeUri=http://broker:8080
+username=monitor
+password=secret
+
+[broker.half]
+scrapeUri=http://half:8080
+username=half-user
+`
+	if err := os.WriteFile(iniPath, []byte(ini), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := ParseConfig(iniPath); err == nil {
+		t.Fatal("expected error for broker with incomplete credentials, got nil")
+	}
+}
+
+func TestParseConfigEndpointSettingsFromIni(t *testing.T) {
+	clearSolaceEnv(t)
+	dir := t.TempDir()
+	iniPath := filepath.Join(dir, "solace.ini")
+	ini := `[solace]
+scrapeUri=http://broker:8080
+username=monitor
+password=secret
+timeout=5s
+prefetchInterval=30s
+
+[broker.other]
+scrapeUri=http://other:8080
+timeout=12s
+
+[endpoint.det]
+prefetchInterval=5m
+timeout=20s
+sempPageSize=500
+QueueDetails=*|*
+Version=*|*||30s
+
+[endpoint.std]
+Health=*|*
+`
+	if err := os.WriteFile(iniPath, []byte(ini), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints, conf, err := ParseConfig(iniPath)
+	if err != nil {
+		t.Fatalf("ParseConfig error: %v", err)
+	}
+	det := endpoints["det"]
+	if len(det) != 2 {
+		t.Fatalf("endpoint 'det' has %d datasources, want 2 without its settings (%v)", len(det), det)
+	}
+	if det[0].RefreshInterval != 0 || det[1].RefreshInterval != 30*time.Second {
+		t.Errorf("refresh intervals = %s/%s, want 0s/30s", det[0].RefreshInterval, det[1].RefreshInterval)
+	}
+
+	detConf := conf.ForEndpoint("det")
+	if detConf.PrefetchInterval != 5*time.Minute || detConf.Timeout != 20*time.Second || detConf.SempPageSize != 500 {
+		t.Errorf("endpoint settings not applied: %s/%s/%d", detConf.PrefetchInterval, detConf.Timeout, detConf.SempPageSize)
+	}
+	// The endpoint timeout wins over the timeout of the broker target.
+	otherConf, err := detConf.ForTarget("other")
+	if err != nil {
+		t.Fatalf("ForTarget(other) error: %v", err)
+	}
+	if otherConf.ScrapeURI != "http://other:8080" || otherConf.Timeout != 20*time.Second {
+		t.Errorf("ForTarget(other) = %s/%s, want http://other:8080/20s", otherConf.ScrapeURI, otherConf.Timeout)
+	}
+
+	stdConf := conf.ForEndpoint("std")
+	if stdConf.PrefetchInterval != 30*time.Second || stdConf.Timeout != 5*time.Second || stdConf.SempPageSize != 100 {
+		t.Errorf("endpoint without settings must inherit [solace]: %s/%s/%d", stdConf.PrefetchInterval, stdConf.Timeout, stdConf.SempPageSize)
+	}
+	if otherConf, _ := stdConf.ForTarget("other"); otherConf.Timeout != 12*time.Second {
+		t.Errorf("ForTarget(other).Timeout = %s, want the broker timeout 12s", otherConf.Timeout)
+	}
+}
+
+func TestParseConfigInvalidEndpointSettingsFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+	}{
+		{name: "negative prefetch interval", endpoint: "prefetchInterval=-1s\nHealth=*|*"},
+		{name: "zero timeout", endpoint: "timeout=0s\nHealth=*|*"},
+		{name: "invalid page size", endpoint: "sempPageSize=many\nHealth=*|*"},
+		{name: "invalid refresh interval", endpoint: "Health=*|*||often"},
+		{name: "too many parts", endpoint: "Health=*|*||1m|more"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearSolaceEnv(t)
+			iniPath := filepath.Join(t.TempDir(), "solace.ini")
+			ini := "[solace]\nscrapeUri=http://broker:8080\n\n[endpoint.broken]\n" + tt.endpoint + "\n"
+			if err := os.WriteFile(iniPath, []byte(ini), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, _, err := ParseConfig(iniPath); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package exporter
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 type DataSource struct {
@@ -10,8 +11,14 @@ type DataSource struct {
 	VpnFilter    string
 	ItemFilter   string
 	MetricFilter []string
+	// RefreshInterval overrides the prefetch interval of the endpoint for this data source; 0 keeps it.
+	RefreshInterval time.Duration
 }
 
 func (dataSource DataSource) String() string {
-	return fmt.Sprintf("%s=%s|%s|%s", dataSource.Name, dataSource.VpnFilter, dataSource.ItemFilter, strings.Join(dataSource.MetricFilter, ","))
+	s := fmt.Sprintf("%s=%s|%s|%s", dataSource.Name, dataSource.VpnFilter, dataSource.ItemFilter, strings.Join(dataSource.MetricFilter, ","))
+	if dataSource.RefreshInterval > 0 {
+		s += "|" + dataSource.RefreshInterval.String()
+	}
+	return s
 }
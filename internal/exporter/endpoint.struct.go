@@ -0,0 +1,107 @@
+package exporter
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// endpointSettingKeys are the keys of an [endpoint.<name>] section that configure the endpoint instead of naming a
+// data source.
+var endpointSettingKeys = map[string]bool{"prefetchInterval": true, "timeout": true, "sempPageSize": true}
+
+// EndpointConfig holds the scrape settings an [endpoint.<name>] section sets for itself. Unset settings are inherited
+// from the [solace] section, or from the [broker.<name>] section of the scrape target.
+type EndpointConfig struct {
+	Name string
+	// PrefetchInterval is nil if not set, since 0s is a valid value that disables prefetching for the endpoint.
+	PrefetchInterval *time.Duration
+	// Timeout and SempPageSize are 0 if not set.
+	Timeout      time.Duration
+	SempPageSize int64
+}
+
+// ForEndpoint returns a copy of conf with the settings of the named endpoint applied. They also take precedence over
+// the settings of a broker target selected later by ForTarget.
+func (conf *Config) ForEndpoint(name string) *Config {
+	c := conf.Clone()
+	endpoint, ok := conf.Endpoints[name]
+	if !ok {
+		return c
+	}
+
+	c.endpoint = endpoint
+	c.applyEndpoint()
+	return c
+}
+
+func (conf *Config) applyEndpoint() {
+	if conf.endpoint == nil {
+		return
+	}
+	if conf.endpoint.PrefetchInterval != nil {
+		conf.PrefetchInterval = *conf.endpoint.PrefetchInterval
+	}
+	if conf.endpoint.Timeout > 0 {
+		conf.Timeout = conf.endpoint.Timeout
+	}
+	if conf.endpoint.SempPageSize > 0 {
+		conf.SempPageSize = conf.endpoint.SempPageSize
+	}
+}
+
+// parseEndpointSettings reads the endpointSettingKeys of an [endpoint.<name>] section.
+func parseEndpointSettings(section *ini.Section, endpointName string) (*EndpointConfig, error) {
+	endpoint := &EndpointConfig{Name: endpointName}
+
+	if key, err := section.GetKey("prefetchInterval"); err == nil {
+		interval, err := time.ParseDuration(key.String())
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q: config param \"prefetchInterval\" is invalid: %w", endpointName, err)
+		}
+		if interval < 0 {
+			return nil, fmt.Errorf("endpoint %q: config param \"prefetchInterval\" must not be negative", endpointName)
+		}
+		endpoint.PrefetchInterval = &interval
+	}
+	if key, err := section.GetKey("timeout"); err == nil {
+		timeout, err := time.ParseDuration(key.String())
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q: config param \"timeout\" is invalid: %w", endpointName, err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("endpoint %q: config param \"timeout\" must be positive", endpointName)
+		}
+		endpoint.Timeout = timeout
+	}
+	if key, err := section.GetKey("sempPageSize"); err == nil {
+		pageSize, err := strconv.ParseInt(key.String(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %q: config param \"sempPageSize\" is invalid: %w", endpointName, err)
+		}
+		if pageSize < 1 {
+			return nil, fmt.Errorf("endpoint %q: config param \"sempPageSize\" must be positive", endpointName)
+		}
+		endpoint.SempPageSize = pageSize
+	}
+
+	return endpoint, nil
+}
+
+// sameEndpoints reports whether a and b configure the same endpoint settings.
+func sameEndpoints(a, b map[string]*EndpointConfig) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, endpoint := range a {
+		other, ok := b[name]
+		if !ok || endpoint.Timeout != other.Timeout || endpoint.SempPageSize != other.SempPageSize ||
+			(endpoint.PrefetchInterval == nil) != (other.PrefetchInterval == nil) ||
+			(endpoint.PrefetchInterval != nil && *endpoint.PrefetchInterval != *other.PrefetchInterval) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,208 +1,307 @@
 package exporter
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"solace_exporter/internal/semp"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 // CollectPrometheusMetric fetches the stats from configured Solace location and delivers them
 // as Prometheus metrics. It implements prometheus.Collector.
-func (e *Exporter) CollectPrometheusMetric(ch chan<- semp.PrometheusMetric) {
-	var up float64 // set per dataSource in the switch below before it is read
-	var err error
-	var vpnName string
-
-	for _, dataSource := range *e.dataSource {
-		switch dataSource.Name {
-		case "Version", "VersionV1":
-			up, err = e.semp.GetVersionSemp1(ch)
-		case "Health", "HealthV1":
-			if !e.config.IsHWBroker {
-				up, err = e.semp.GetHealthSemp1(ch)
-			} else {
-				up = 0
-				err = errors.New("Software only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-				e.logger.Error("Software only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-			}
-		case "StorageElement", "StorageElementV1":
-			if !e.config.IsHWBroker {
-				up, err = e.semp.GetStorageElementSemp1(ch, dataSource.ItemFilter)
-			} else {
-				up = 0
-				err = errors.New("Software only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-				e.logger.Error("Software only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-			}
-		case "Disk", "DiskV1":
-			if e.config.IsHWBroker {
-				up, err = e.semp.GetDiskSemp1(ch)
-			} else {
-				up = 0
-				err = errors.New("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-				e.logger.Error("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-			}
-		case "Raid", "RaidV1":
-			if e.config.IsHWBroker {
-				up, err = e.semp.GetRaidSemp1(ch)
-			} else {
-				up = 0
-				err = errors.New("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-				e.logger.Error("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-			}
-		case "Memory", "MemoryV1":
-			up, err = e.semp.GetMemorySemp1(ch)
-		case "Interface", "InterfaceV1":
-			up, err = e.semp.GetInterfaceSemp1(ch, dataSource.ItemFilter)
-		case "InterfaceHW", "InterfaceHWV1":
-			if e.config.IsHWBroker {
-				up, err = e.semp.GetInterfaceHWSemp1(ch, dataSource.ItemFilter)
-			} else {
-				up = 0
-				err = errors.New("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-				e.logger.Error("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-			}
-		case "GlobalStats", "GlobalStatsV1":
-			up, err = e.semp.GetGlobalStatsSemp1(ch)
-		case "GlobalSystemInfo", "GlobalSystemInfoV1":
-			up, err = e.semp.GetGlobalSystemInfoSemp1(ch)
-		case "Spool", "SpoolV1":
-			up, err = e.semp.GetSpoolSemp1(ch)
-		case "SpoolStats", "SpoolStatsV1":
-			up, err = e.semp.GetSpoolStatsSemp1(ch)
-		case "Redundancy", "RedundancyV1":
-			up, err = e.semp.GetRedundancySemp1(ch)
-		case "Alarm", "AlarmV1":
-			if e.config.IsHWBroker {
-				up, err = e.semp.GetAlarmSemp1(ch)
-			} else {
-				up = 0
-				err = errors.New("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-				e.logger.Error("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
+// The data sources run concurrently, at most parallelSempConnections of the broker at once, see
+// semp.Semp.AcquireDataSource; the broker's limiter also bounds their SEMP requests by parallelSempConnections and
+// sempRequestsPerSecond. A failing data source only affects its own up metric, unless it reports an unrecoverable
+// error (up < 0): then the other data sources are cancelled, so that e.g. wrong credentials are not sent once per data
+// source, and a single up metric with endpoint "global" is reported.
+// A data source the broker is too old for, see semp.Capability, reports solace_datasource_unsupported instead of up.
+// Once ctx is done, the data sources stop at their next SEMP page and pending requests are aborted.
+// The returned error joins the errors of all data sources that failed; it is nil if all of them are up.
+func (e *Exporter) CollectPrometheusMetric(ctx context.Context, ch chan<- semp.PrometheusMetric) error {
+	var tagged = make(chan dataSourceMetric, capMetricChan)
+	var errs []error
+	go func() {
+		defer close(tagged)
+		errs = e.collectDataSources(ctx, tagged)
+	}()
+
+	for metric := range tagged {
+		ch <- metric.metric
+	}
+
+	// tagged is closed after errs was set.
+	var joined []error
+	for index, err := range errs {
+		if err != nil {
+			joined = append(joined, fmt.Errorf("%s: %w", (*e.dataSource)[index].Name, err))
+		}
+	}
+	return errors.Join(joined...)
+}
+
+// dataSourceMetric is a metric tagged with the index in Exporter.dataSource of the data source that emitted it.
+type dataSourceMetric struct {
+	dataSource int
+	metric     semp.PrometheusMetric
+}
+
+// collectDataSources is CollectPrometheusMetric, but tags every metric with the data source that emitted it, including
+// its up metric. It returns the error of every data source by index, nil if the data source is up.
+func (e *Exporter) collectDataSources(ctx context.Context, ch chan<- dataSourceMetric) []error {
+	var wg sync.WaitGroup
+	var failedGlobally atomic.Bool
+	var errs = make([]error, len(*e.dataSource))
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	// Of an HA pair, all data sources of the scrape use the same active node.
+	var activeNode = sync.OnceValues(func() (haNode, error) {
+		return e.activeNode(ctx)
+	})
+
+	for index, dataSource := range *e.dataSource {
+		release, err := e.semp.AcquireDataSource(ctx)
+		if err != nil {
+			// ctx is done: cancelled by a global error, or the scrape was abandoned.
+			errs[index] = context.Cause(ctx)
+			if !failedGlobally.Load() {
+				ch <- dataSourceMetric{dataSource: index, metric: e.semp.NewMetric(semp.MetricDesc["Global"]["up"], prometheus.GaugeValue, 0, errs[index].Error(), dataSource.Name)}
 			}
-		case "Environment", "EnvironmentV1":
-			if e.config.IsHWBroker {
-				up, err = e.semp.GetEnvironmentSemp1(ch)
-			} else {
-				up = 0
-				err = errors.New("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-				e.logger.Error("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
+			continue
+		}
+		wg.Go(func() {
+			defer release()
+			var dataSourceCh = make(chan semp.PrometheusMetric, capMetricChan)
+			var forwarded = make(chan struct{})
+			go func() {
+				defer close(forwarded)
+				for metric := range dataSourceCh {
+					ch <- dataSourceMetric{dataSource: index, metric: metric}
+				}
+			}()
+			defer func() {
+				close(dataSourceCh)
+				<-forwarded
+			}()
+
+			if failedGlobally.Load() {
+				errs[index] = context.Cause(ctx)
+				return
 			}
-		case "Hardware", "HardwareV1":
-			if e.config.IsHWBroker {
-				up, err = e.semp.GetHardwareSemp1(ch)
-			} else {
-				up = 0
-				err = errors.New("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-				e.logger.Error("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
+
+			up, err := e.collectDataSourceSafely(ctx, dataSourceCh, dataSource, activeNode)
+
+			var endpoint = dataSource.Name
+			var unsupported *semp.UnsupportedError
+			if errors.As(err, &unsupported) {
+				// Skipped on purpose, which is neither up nor an error.
+				dataSourceCh <- e.semp.NewMetric(semp.MetricDesc["Global"]["unsupported"], prometheus.GaugeValue, 1, endpoint, unsupported.MinVersion.String(), unsupported.BrokerVersion.String())
+				return
 			}
-		case "ClockDetail", "ClockDetailV1":
-			if e.config.IsHWBroker {
-			    up, err = e.semp.GetClockDetailSemp1(ch)
+			if up < 1 {
+				if err != nil {
+					errs[index] = err
+				} else {
+					errs[index] = errors.New("down")
+				}
+
+				if up < 0 && failedGlobally.CompareAndSwap(false, true) {
+					// Unrecoverable error that would be repeated on all dataSources. Only the first one is reported.
+					cancel(fmt.Errorf("skipped after the unrecoverable error of %s: %w", dataSource.Name, errs[index]))
+					endpoint = "global"
+				} else if failedGlobally.Load() {
+					// Cancelled by, or failed like, the data source that reported the global error.
+					errs[index] = context.Cause(ctx)
+					return
+				}
+
+				if err != nil {
+					dataSourceCh <- e.semp.NewMetric(semp.MetricDesc["Global"]["up"], prometheus.GaugeValue, 0, err.Error(), endpoint)
+				} else {
+					dataSourceCh <- e.semp.NewMetric(semp.MetricDesc["Global"]["up"], prometheus.GaugeValue, 0, "Unknown", endpoint)
+				}
 			} else {
-				up = 0
-				err = errors.New("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-				e.logger.Error("Hardware only scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
+				dataSourceCh <- e.semp.NewMetric(semp.MetricDesc["Global"]["up"], prometheus.GaugeValue, 1, "", endpoint)
 			}
-		case "ReplicationStats", "ReplicationStatsV1":
-			up, err = e.semp.GetReplicationStatsSemp1(ch)
-		case "ConfigSyncRouter", "ConfigSyncRouterV1":
-			up, err = e.semp.GetConfigSyncRouterSemp1(ch)
-		case "ConfigSync", "ConfigSyncV1":
-			up, err = e.semp.GetConfigSyncSemp1(ch)
-		case "Vpn", "VpnV1":
-			up, err = e.semp.GetVpnSemp1(ch, dataSource.VpnFilter, e.config.SempPageSize)
-		case "VpnReplication", "VpnReplicationV1":
-			up, err = e.semp.GetVpnReplicationSemp1(ch, dataSource.VpnFilter)
-		case "ConfigSyncVpn", "ConfigSyncVpnV1":
-			up, err = e.semp.GetConfigSyncVpnSemp1(ch, dataSource.VpnFilter, e.config.SempPageSize)
-		case "Bridge", "BridgeV1":
-			up, err = e.semp.GetBridgeSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "BridgeRemote", "BridgeRemoteV1":
-			up, err = e.semp.GetBridgeRemoteSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter)
-		case "BridgeDetail", "BridgeDetailV1":
-			up, err = e.semp.GetBridgeDetailSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "BridgeClientCert", "BridgeClientCertV1":
-			up, err = e.semp.GetBridgeClientCertSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "VpnSpool", "VpnSpoolV1":
-			up, err = e.semp.GetVpnSpoolSemp1(ch, dataSource.VpnFilter, e.config.SempPageSize)
-		case "Client", "ClientV1":
-			up, err = e.semp.GetClientSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter)
-		case "ClientProfile", "ClientProfileV1":
-			up, err = e.semp.GetClientProfileSemp1(ch, dataSource.VpnFilter)
-		case "ClientSlowSubscriber", "ClientSlowSubscriberV1":
-			up, err = e.semp.GetClientSlowSubscriberSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter)
-		case "ClientStats", "ClientStatsV1":
-			up, err = e.semp.GetClientStatsSemp1(ch, dataSource.ItemFilter, e.config.SempPageSize)
-		case "ClientConnections", "ClientConnectionsV1":
-			up, err = e.semp.GetClientConnectionStatsSemp1(ch, dataSource.ItemFilter)
-		case "ClientMessageSpoolStats", "ClientMessageSpoolStatsV1":
-			up, err = e.semp.GetClientMessageSpoolStatsSemp1(ch, dataSource.VpnFilter, e.config.SempPageSize)
-		case "ClientMessageSpoolEgress", "ClientMessageSpoolEgressV1":
-			up, err = e.semp.GetClientMessageSpoolEgressSemp1(ch, dataSource.ItemFilter)
-		case "ClusterLinks", "ClusterLinksV1":
-			up, err = e.semp.GetClusterLinksSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter)
-		case "VpnStats", "VpnStatsV1":
-			up, err = e.semp.GetVpnStatsSemp1(ch, dataSource.VpnFilter, e.config.SempPageSize)
-		case "BridgeStats", "BridgeStatsV1":
-			up, err = e.semp.GetBridgeStatsSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "QueueRates", "QueueRatesV1":
-			up, err = e.semp.GetQueueRatesSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "QueueStats", "QueueStatsV1":
-			up, err = e.semp.GetQueueStatsSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "QueueStatsV2":
-			up = 0 // reset before getVpnName so its failure isn't reported with the previous datasource's up value
-			vpnName, err = e.getVpnName(dataSource.VpnFilter)
-			if err == nil {
-				up, err = e.semp.GetQueueStatsSemp2(ch, vpnName, dataSource.ItemFilter, dataSource.MetricFilter)
-			}
-		case "QueueDetails", "QueueDetailsV1":
-			up, err = e.semp.GetQueueDetailsSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "TopicEndpointRates", "TopicEndpointRatesV1":
-			up, err = e.semp.GetTopicEndpointRatesSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "TopicEndpointStats", "TopicEndpointStatsV1":
-			up, err = e.semp.GetTopicEndpointStatsSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "TopicEndpointDetails", "TopicEndpointDetailsV1":
-			up, err = e.semp.GetTopicEndpointDetailsSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "RestConsumerStats", "RestConsumerStatsV1":
-			up, err = e.semp.GetRestConsumerStatsSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "RdpStats", "RdpStatsV1":
-			up, err = e.semp.GetRdpStatsSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		case "RdpInfo", "RdpInfoV1":
-			up, err = e.semp.GetRdpInfoSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter)
-		case "MqttSession":
-			up, err = e.semp.GetMqttSessionSemp1(ch, dataSource.VpnFilter, dataSource.ItemFilter, e.config.SempPageSize)
-		default:
-			up = 0
-			err = errors.New("Unknown scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
-			e.logger.Error("Unknown scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
+		})
+	}
+
+	wg.Wait()
+	return errs
+}
+
+// collectDataSourceSafely is collectDataSource, but reports a panic as error of this data source. A malformed or
+// unexpected broker reply must neither crash the exporter nor stop the other data sources of the scrape.
+func (e *Exporter) collectDataSourceSafely(ctx context.Context, ch chan<- semp.PrometheusMetric, dataSource DataSource, activeNode func() (haNode, error)) (up float64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.logger.Error("recovered from panic while scraping broker", "panic", r, "dataSource", dataSource.Name, "scrapeURI", e.config.ScrapeURI)
+			up, err = 0, fmt.Errorf("recovered from panic: %v", r)
 		}
+	}()
+
+	return e.collectDataSource(ctx, ch, dataSource, activeNode)
+}
 
-		var endpoint = dataSource.Name
-		if up < 1 {
-			if up < 0 {
-				endpoint = "global"
+// collectDataSource scrapes dataSource through its registered descriptor, after checking that the descriptor supports
+// the configured or detected broker type. Of an HA pair, activeNode selects the node to scrape, see collectFromNodes.
+func (e *Exporter) collectDataSource(ctx context.Context, ch chan<- semp.PrometheusMetric, dataSource DataSource, activeNode func() (haNode, error)) (float64, error) {
+	descriptor, ok := semp.LookupDataSource(dataSource.Name)
+	if !ok {
+		err := errors.New("Unknown scrape target: \"" + dataSource.Name + "\". Please check documentation for valid targets.")
+		e.logger.Error(err.Error())
+		return 0, err
+	}
+	if !e.config.DetectHWBroker {
+		if err := e.checkPlatform(dataSource.Name, descriptor, e.config.IsHWBroker); err != nil {
+			return 0, err
+		}
+	}
+
+	query := semp.DataSourceQuery{
+		VpnFilter:    dataSource.VpnFilter,
+		ItemFilter:   dataSource.ItemFilter,
+		MetricFilter: dataSource.MetricFilter,
+		PageSize:     e.config.SempPageSize,
+	}
+	if descriptor.PerVpn() && !e.discoversVpns(dataSource.VpnFilter) {
+		vpnName, err := e.getVpnName(dataSource.VpnFilter)
+		if err != nil {
+			return 0, err
+		}
+		query.VpnFilter = vpnName
+	}
+
+	if e.nodes != nil {
+		return e.collectFromNodes(ctx, ch, descriptor, query, activeNode)
+	}
+	return e.collectFrom(ctx, ch, e.semp, descriptor, query)
+}
+
+// collectFrom scrapes the data source of descriptor from the broker of brokerSemp. It skips the data source, or some
+// of its metrics, if the broker is too old for them, see semp.Capability. The metrics are removed from the metric
+// filter, so that their fields are not requested either. A per VPN SEMP v2 data source with a VPN
+// glob is scraped from every matching VPN, see collectFromVpns.
+func (e *Exporter) collectFrom(ctx context.Context, ch chan<- semp.PrometheusMetric, brokerSemp *semp.Semp, descriptor *semp.DataSourceDescriptor, query semp.DataSourceQuery) (float64, error) {
+	// An unhealthy broker gets no requests at all until its circuit breaker lets a probe through.
+	if err := brokerSemp.CheckCircuit(); err != nil {
+		return 0, err
+	}
+
+	dataSourceSemp := brokerSemp.ForDataSource(e.endpoint, descriptor.Name)
+	defer dataSourceSemp.ObserveSeries()
+
+	var info semp.BrokerInfo
+	var infoErr error
+	var capabilities = descriptor.Capabilities()
+	if e.config.DetectHWBroker || len(capabilities) > 0 {
+		info, infoErr = dataSourceSemp.DetectBroker(ctx)
+	}
+
+	if e.config.DetectHWBroker {
+		isHWBroker := info.IsHWBroker
+		if infoErr != nil {
+			if descriptor.Platform != semp.PlatformAny {
+				return 0, fmt.Errorf("detecting the broker platform: %w", infoErr)
 			}
+			// The data source runs on both platforms, and will report the error of an unhealthy broker itself.
+			isHWBroker = e.config.IsHWBroker
+		}
+		if err := e.checkPlatform(descriptor.Name, descriptor, isHWBroker); err != nil {
+			return 0, err
+		}
+		dataSourceSemp = dataSourceSemp.ForHWBroker(isHWBroker)
+	}
 
+	// Without the broker version, the data source is scraped anyway and reports the error of an unhealthy broker.
+	if len(capabilities) > 0 && infoErr == nil {
+		unsupportedMetrics, err := descriptor.CheckVersion(info.Version)
+		if err != nil {
+			e.logger.Debug("skipping data source unsupported by the broker", "err", err, "scrapeURI", brokerSemp.BrokerURI())
+			return 0, err
+		}
+		if len(unsupportedMetrics) > 0 {
+			// The fields of the unsupported metrics are not requested, and their series dropped should the data
+			// source report them anyway.
+			query.MetricFilter, err = descriptor.SupportedMetricFilter(query.MetricFilter, info.Version)
 			if err != nil {
-				ch <- e.semp.NewMetric(semp.MetricDesc["Global"]["up"], prometheus.GaugeValue, 0, err.Error(), endpoint)
-			} else {
-				ch <- e.semp.NewMetric(semp.MetricDesc["Global"]["up"], prometheus.GaugeValue, 0, "Unknown", endpoint)
+				e.logger.Debug("skipping data source whose metric filter the broker does not support", "err", err, "scrapeURI", brokerSemp.BrokerURI())
+				return 0, err
 			}
+			filtered, wait := withoutMetrics(ch, unsupportedMetrics)
+			defer wait()
+			ch = filtered
+		}
+	}
+
+	var up float64
+	var err error
+	if descriptor.PerVpn() && e.discoversVpns(query.VpnFilter) {
+		up, err = e.collectFromVpns(ctx, ch, dataSourceSemp, descriptor, query)
+	} else {
+		up, err = descriptor.Collect(ctx, dataSourceSemp, ch, query)
+	}
+	if errors.Is(err, semp.ErrCircuitOpen) {
+		// The circuit opened during the scrape. It is not an error of all data sources like an unreachable broker.
+		up = 0
+	}
+	return up, err
+}
 
-			if up < 0 {
-				// Unrecoverable error that will be repeated on all dataSources
-				break
+// withoutMetrics returns a channel that forwards all metrics to ch, except the series of descs, and a func that closes
+// the channel and waits until all metrics are forwarded.
+func withoutMetrics(ch chan<- semp.PrometheusMetric, descs []*semp.Desc) (chan<- semp.PrometheusMetric, func()) {
+	var filtered = make(chan semp.PrometheusMetric, capMetricChan)
+	var forwarded = make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for metric := range filtered {
+			if !slices.ContainsFunc(descs, metric.IsOf) {
+				ch <- metric
 			}
-		} else {
-			ch <- e.semp.NewMetric(semp.MetricDesc["Global"]["up"], prometheus.GaugeValue, 1, "", endpoint)
 		}
+	}()
+
+	return filtered, func() {
+		close(filtered)
+		<-forwarded
+	}
+}
+
+// checkPlatform returns an error if the data source of descriptor, requested as name, does not support the broker type.
+func (e *Exporter) checkPlatform(name string, descriptor *semp.DataSourceDescriptor, isHWBroker bool) error {
+	if descriptor.Platform.Supports(isHWBroker) {
+		return nil
 	}
+
+	var kind = "Hardware"
+	if descriptor.Platform == semp.PlatformSoftware {
+		kind = "Software"
+	}
+	err := errors.New(kind + " only scrape target: \"" + name + "\". Please check documentation for valid targets.")
+	e.logger.Error(err.Error())
+	return err
 }
 
+// Collect implements prometheus.Collector. Identical concurrent scrapes share one collection, see scrapeCoalescer. A
+// scrape cancelled while waiting for it reports the cause as global error, like an unrecoverable error of a data source.
 func (e *Exporter) Collect(pch chan<- prometheus.Metric) {
+	metrics, err := scrapes.collect(e.ctx, e.scrapeKey(), e.config.ScrapeCacheTTL, e.collectDistinct)
+	if err != nil {
+		metrics = []semp.PrometheusMetric{e.semp.NewMetric(semp.MetricDesc["Global"]["up"], prometheus.GaugeValue, 0, err.Error(), "global")}
+	}
+	for _, metric := range metrics {
+		pch <- metric.AsPrometheusMetric()
+	}
+}
+
+// collectDistinct collects all data sources and drops duplicate series, keeping the most current value.
+func (e *Exporter) collectDistinct(ctx context.Context) []semp.PrometheusMetric {
 	var ch = make(chan semp.PrometheusMetric, capMetricChan)
 	var wg sync.WaitGroup
 
@@ -217,7 +316,7 @@ func (e *Exporter) Collect(pch chan<- prometheus.Metric) {
 				e.logger.Error("recovered from panic while scraping broker", "panic", r, "scrapeURI", e.config.ScrapeURI)
 			}
 		}()
-		e.CollectPrometheusMetric(ch)
+		_ = e.CollectPrometheusMetric(ctx, ch)
 	}
 	go collectWorker()
 
@@ -226,29 +325,18 @@ func (e *Exporter) Collect(pch chan<- prometheus.Metric) {
 		close(ch)
 	}()
 
-	// Ensure `checkedMetricChan` and `uncheckedMetricChan` are drained in case of an early return.
-	defer func() {
-		if ch != nil {
-			for range ch {
-			}
-		}
-	}()
-
 	// read from chanel until the channel is closed
 	var distinctMetrics = make(map[string]semp.PrometheusMetric)
-
-	for {
-		metric, ok := <-ch
-		if !ok {
-			for _, metric := range distinctMetrics {
-				pch <- metric.AsPrometheusMetric()
-			}
-
-			return
-		}
+	for metric := range ch {
 		// using a map to filter duplicates and use always most current received value
 		distinctMetrics[metric.Name()] = metric
 	}
+
+	metrics := make([]semp.PrometheusMetric, 0, len(distinctMetrics))
+	for _, metric := range distinctMetrics {
+		metrics = append(metrics, metric)
+	}
+	return metrics
 }
 
 func (e *Exporter) getVpnName(vpnFilter string) (string, error) {
@@ -0,0 +1,347 @@
+package exporter
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"solace_exporter/internal/semp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestCollectRejectsWithoutScraping checks the data sources that fail before any SEMP call: unknown names and
+// targets that do not fit the broker type.
+func TestCollectRejectsWithoutScraping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		dataSource DataSource
+		isHWBroker bool
+		wantErr    string
+	}{
+		{name: "unknown", dataSource: DataSource{Name: "QueueStat"}, wantErr: `Unknown scrape target: "QueueStat"`},
+		{name: "hardware only", dataSource: DataSource{Name: "DiskV1"}, wantErr: `Hardware only scrape target: "DiskV1"`},
+		{name: "software only", dataSource: DataSource{Name: "Health"}, isHWBroker: true, wantErr: `Software only scrape target: "Health"`},
+		{name: "semp v2 without default vpn", dataSource: DataSource{Name: "QueueStatsV2", VpnFilter: "*"}, wantErr: "defaultVpn is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &Config{ScrapeURI: "http://127.0.0.1:0", Timeout: time.Second, IsHWBroker: tt.isHWBroker, authType: AuthTypeBasic}
+			metrics := collectAll(t, conf, []DataSource{tt.dataSource})
+			if len(metrics) != 1 {
+				t.Fatalf("got %d metrics, want only the up metric", len(metrics))
+			}
+			if name := metrics[0].Name(); !strings.HasPrefix(name, "solace_up{") || !strings.Contains(name, tt.wantErr) {
+				t.Errorf("got %s, want solace_up with error %q", name, tt.wantErr)
+			}
+		})
+	}
+}
+
+func collectAll(t *testing.T, conf *Config, dataSources []DataSource) []semp.PrometheusMetric {
+	t.Helper()
+
+	return collectAllContext(t.Context(), conf, dataSources)
+}
+
+func collectAllContext(ctx context.Context, conf *Config, dataSources []DataSource) []semp.PrometheusMetric {
+	exp := NewExporter(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)), conf, "test", &dataSources)
+	ch := make(chan semp.PrometheusMetric, capMetricChan)
+	exp.CollectPrometheusMetric(ctx, ch)
+	close(ch)
+
+	var metrics []semp.PrometheusMetric
+	for metric := range ch {
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+// TestCollectRunsDataSourcesInParallel checks that the data sources of one scrape run concurrently, but never use
+// more than parallelSempConnections connections to the broker.
+func TestCollectRunsDataSourcesInParallel(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`<rpc-reply semp-version="soltr/9_1_1VMR"><rpc/><execute-result code="ok"/></rpc-reply>`))
+	}))
+	defer server.Close()
+
+	conf := &Config{ScrapeURI: server.URL, Timeout: 5 * time.Second, ParallelSempConnections: 2, authType: AuthTypeBasic}
+	dataSources := []DataSource{{Name: "Version"}, {Name: "Memory"}, {Name: "Spool"}, {Name: "SpoolStats"}}
+
+	start := time.Now()
+	metrics := collectAll(t, conf, dataSources)
+	elapsed := time.Since(start)
+
+	ups := 0
+	for _, metric := range metrics {
+		if strings.HasPrefix(metric.Name(), "solace_up{") {
+			ups++
+		}
+	}
+	if ups != len(dataSources) {
+		t.Errorf("got %d up metrics, want one per data source (%d)", ups, len(dataSources))
+	}
+	if got := maxInFlight.Load(); got != 2 {
+		t.Errorf("max parallel SEMP connections = %d, want 2", got)
+	}
+	if elapsed >= 400*time.Millisecond {
+		t.Errorf("scrape took %s, data sources did not run in parallel", elapsed)
+	}
+}
+
+// TestCollectReportsGlobalFailureOnce checks that an unrecoverable error (like an unreachable broker) is reported as a
+// single up metric with endpoint "global", however many data sources ran into it.
+func TestCollectReportsGlobalFailureOnce(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	conf := &Config{ScrapeURI: server.URL, Timeout: 5 * time.Second, ParallelSempConnections: 4, authType: AuthTypeBasic}
+	metrics := collectAll(t, conf, []DataSource{{Name: "Version"}, {Name: "Memory"}, {Name: "Spool"}, {Name: "SpoolStats"}})
+
+	if len(metrics) != 1 {
+		t.Fatalf("got %d metrics, want a single up metric", len(metrics))
+	}
+	if name := metrics[0].Name(); !strings.Contains(name, `endpoint="global"`) {
+		t.Errorf("got %s, want endpoint=\"global\"", name)
+	}
+}
+
+// TestCollectStopsPagingWhenCancelled checks that a cancelled scrape does not fetch the remaining SEMP pages.
+func TestCollectStopsPagingWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// Prometheus gives up on the scrape while the first page is served.
+		cancel()
+		_, _ = w.Write([]byte(`<rpc-reply semp-version="soltr/9_1_1VMR"><rpc><show><message-vpn><vpn><name>default</name></vpn></message-vpn></show></rpc>` +
+			`<more-cookie><rpc><show><message-vpn><vpn-name>*</vpn-name></message-vpn></show></rpc></more-cookie><execute-result code="ok"/></rpc-reply>`))
+	}))
+	defer server.Close()
+
+	conf := &Config{ScrapeURI: server.URL, Timeout: 5 * time.Second, SempPageSize: 1, authType: AuthTypeBasic}
+	metrics := collectAllContext(ctx, conf, []DataSource{{Name: "Vpn", VpnFilter: "*"}})
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("got %d SEMP requests, want 1: paging must stop after the scrape was cancelled", got)
+	}
+	var up string
+	for _, metric := range metrics {
+		if strings.HasPrefix(metric.Name(), "solace_up{") {
+			up = metric.Name()
+		}
+	}
+	if !strings.Contains(up, "context canceled") {
+		t.Errorf("got up metric %q, want the cancellation as error", up)
+	}
+}
+
+// TestCollectShortCircuitsOpenCircuit checks that once the circuit breaker of a broker is open, a scrape sends no
+// request and reports every data source as down with error circuit_open.
+func TestCollectShortCircuitsOpenCircuit(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	conf := &Config{ScrapeURI: server.URL, Timeout: 5 * time.Second, CircuitBreakerFailures: 1, CircuitBreakerCooldown: time.Minute, authType: AuthTypeBasic}
+	dataSources := []DataSource{{Name: "Version"}, {Name: "Memory"}}
+	collectAll(t, conf, dataSources)
+	sent := requests.Load()
+
+	metrics := collectAll(t, conf, dataSources)
+	if got := requests.Load(); got != sent {
+		t.Errorf("got %d requests while the circuit was open, want none", got-sent)
+	}
+	if len(metrics) != len(dataSources) {
+		t.Fatalf("got %d metrics, want one up metric per data source", len(metrics))
+	}
+	for _, metric := range metrics {
+		if name := metric.Name(); !strings.Contains(name, "circuit_open") || strings.Contains(name, `endpoint="global"`) {
+			t.Errorf("got %s, want up 0 with error circuit_open", name)
+		}
+	}
+}
+
+// TestCollectGatesOnDetectedPlatform checks that with isHWBroker=auto the data sources are gated by the platform the
+// broker reports, not by the unset IsHWBroker.
+func TestCollectGatesOnDetectedPlatform(t *testing.T) {
+	t.Parallel()
+
+	var otherRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "<version/>") {
+			otherRequests.Add(1)
+			http.Error(w, "unexpected command", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`<rpc-reply semp-version="soltr/9_8"><rpc><show><version><current-load>soltr_9.8.0.12</current-load>` +
+			`</version></show></rpc><execute-result code="ok"/></rpc-reply>`))
+	}))
+	t.Cleanup(server.Close)
+
+	// The broker is an appliance, so Health is rejected while Version, which runs everywhere, is scraped.
+	conf := &Config{ScrapeURI: server.URL, Timeout: time.Second, DetectHWBroker: true, authType: AuthTypeBasic}
+	metrics := collectAll(t, conf, []DataSource{{Name: "Health"}, {Name: "Version"}})
+
+	var healthRejected, versionUp bool
+	for _, metric := range metrics {
+		name := metric.Name()
+		healthRejected = healthRejected || strings.HasPrefix(name, `solace_up{error="Software only scrape target: "Health"`)
+		versionUp = versionUp || name == `solace_up{error="",endpoint="Version"}`
+	}
+	if !healthRejected || !versionUp {
+		t.Errorf("want Health rejected and Version up on a detected appliance, got %d metrics", len(metrics))
+	}
+	if n := otherRequests.Load(); n != 0 {
+		t.Errorf("got %d requests for the rejected data source, want none", n)
+	}
+}
+
+func TestCollectSkipsWhatTheBrokerVersionDoesNotSupport(t *testing.T) {
+	t.Parallel()
+
+	var mqttRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case strings.Contains(string(body), "<version/>"):
+			_, _ = w.Write([]byte(`<rpc-reply semp-version="soltr/7_1VMR"><rpc><show><version><current-load>soltr_7.1.0.42</current-load>` +
+				`</version></show></rpc><execute-result code="ok"/></rpc-reply>`))
+		case strings.Contains(string(body), "<replication/>"):
+			_, _ = w.Write([]byte(`<rpc-reply><rpc><show><message-vpn><replication><message-vpns><message-vpn><vpn-name>default</vpn-name>` +
+				`<admin-state>enabled</admin-state><config-state>active</config-state></message-vpn></message-vpns></replication>` +
+				`</message-vpn></show></rpc><execute-result code="ok"/></rpc-reply>`))
+		default:
+			mqttRequests.Add(1)
+			http.Error(w, "unexpected command", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// MqttSession needs SolOS 7.1.1, and the transaction replication mode of VpnReplication 7.2.
+	conf := &Config{ScrapeURI: server.URL, Timeout: time.Second, authType: AuthTypeBasic}
+	metrics := collectAll(t, conf, []DataSource{{Name: "MqttSession", VpnFilter: "*", ItemFilter: "*"}, {Name: "VpnReplication", VpnFilter: "*"}})
+
+	var names []string
+	for _, metric := range metrics {
+		names = append(names, metric.Name())
+	}
+	for _, want := range []string{
+		`solace_datasource_unsupported{endpoint="MqttSession",min_version="7.1.1",broker_version="7.1.0.42"}`,
+		`solace_up{error="",endpoint="VpnReplication"}`,
+		`solace_vpn_replication_admin_state{vpn_name="default"}`,
+	} {
+		if !slices.Contains(names, want) {
+			t.Errorf("missing %s in %v", want, names)
+		}
+	}
+	for _, name := range names {
+		if strings.HasPrefix(name, "solace_vpn_replication_transaction_replication_mode") || strings.HasPrefix(name, "solace_up") && strings.HasSuffix(name, `endpoint="MqttSession"}`) {
+			t.Errorf("unexpected %s", name)
+		}
+	}
+	if n := mqttRequests.Load(); n != 0 {
+		t.Errorf("got %d requests for the unsupported data source, want none", n)
+	}
+}
+
+// TestCollectDoesNotRequestUnsupportedFields checks that the fields of the metrics a broker is too old for are removed
+// from the select of the request, not only their series from the result.
+func TestCollectDoesNotRequestUnsupportedFields(t *testing.T) {
+	t.Parallel()
+
+	var selects []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`<rpc-reply semp-version="soltr/10_3VMR"><rpc><show><version><current-load>soltr_10.3.0.32</current-load>` +
+				`</version></show></rpc><execute-result code="ok"/></rpc-reply>`))
+			return
+		}
+		mu.Lock()
+		selects = append(selects, r.URL.Query().Get("select"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"data":[],"meta":{"responseCode":200}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	// The partition count of QueueDetailsV2 needs SolOS 10.4.
+	conf := &Config{ScrapeURI: server.URL, Timeout: time.Second, authType: AuthTypeBasic}
+	collectAll(t, conf, []DataSource{{Name: "QueueDetailsV2", VpnFilter: "default", ItemFilter: "*", MetricFilter: []string{"solace_queue_partitions", "solace_queue_binds_max"}}})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(selects) != 1 {
+		t.Fatalf("got %d SEMP v2 requests, want 1", len(selects))
+	}
+	if strings.Contains(selects[0], "partitionCount") || !strings.Contains(selects[0], "maxBindCount") {
+		t.Errorf("select=%s, want maxBindCount without partitionCount", selects[0])
+	}
+}
+
+// TestCollectStopsAfterGlobalFailure checks that an unrecoverable error cancels the other data sources of the scrape,
+// so that wrong credentials are sent once instead of once per data source.
+func TestCollectStopsAfterGlobalFailure(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	conf := &Config{ScrapeURI: server.URL, Timeout: 5 * time.Second, ParallelSempConnections: 1, SempRetries: 2, authType: AuthTypeBasic}
+	dataSources := []DataSource{{Name: "Version"}, {Name: "Memory"}, {Name: "Spool"}, {Name: "SpoolStats"}}
+	exp := NewExporter(t.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), conf, "test", &dataSources)
+	ch := make(chan semp.PrometheusMetric, capMetricChan)
+	err := exp.CollectPrometheusMetric(t.Context(), ch)
+	close(ch)
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("broker got %d requests, want 1", got)
+	}
+	if len(ch) != 1 {
+		t.Errorf("got %d metrics, want a single up metric", len(ch))
+	}
+	if err == nil || !strings.Contains(err.Error(), "skipped after the unrecoverable error") {
+		t.Errorf("err = %v, want the skipped data sources", err)
+	}
+}
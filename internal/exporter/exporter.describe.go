@@ -6,12 +6,34 @@ import (
 	"solace_exporter/internal/semp"
 )
 
-// Describe describes all the metrics ever exported by the Solace exporter. It
-// implements prometheus.Collector.
+// Describe describes all the metrics ever exported by the Solace exporter, which are the up metric and the metrics of
+// all registered data sources. Of an HA pair, the metrics of the data sources are described once per node.
+// It implements prometheus.Collector.
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
-	for _, metricDescItems := range semp.MetricDesc {
-		for _, m := range metricDescItems {
-			ch <- m.AsPrometheusDesc()
+	described := make(map[*semp.Desc]bool)
+	describe := func(descriptions semp.Descriptions) {
+		for _, m := range descriptions {
+			if !described[m] {
+				described[m] = true
+				ch <- m.AsPrometheusDesc()
+			}
+		}
+	}
+
+	describe(semp.MetricDesc["Global"])
+	for _, descriptor := range semp.DataSources() {
+		for _, descriptions := range descriptor.Metrics {
+			if e.nodes == nil {
+				describe(descriptions)
+				continue
+			}
+			for _, node := range e.nodes {
+				nodeDescriptions := make(semp.Descriptions, len(descriptions))
+				for key, m := range descriptions {
+					nodeDescriptions[key] = m.ForNode(node.name)
+				}
+				describe(nodeDescriptions)
+			}
 		}
 	}
 }
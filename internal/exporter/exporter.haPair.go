@@ -0,0 +1,92 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"solace_exporter/internal/semp"
+	"sync"
+)
+
+// Values of the node label of the series of an HA pair.
+const (
+	nodePrimary = "primary"
+	nodeBackup  = "backup"
+)
+
+var errNoActiveNode = errors.New("no node of the HA pair is local active")
+
+// haNode is one node of an HA pair. Its semp labels all series with the name of the node.
+type haNode struct {
+	name string
+	semp *semp.Semp
+}
+
+// activeNode returns the node of the HA pair that reports itself local active in its redundancy state. The backup is
+// only asked if the primary is not active.
+func (e *Exporter) activeNode(ctx context.Context) (haNode, error) {
+	var errs []error
+	for _, node := range e.nodes {
+		if err := node.semp.CheckCircuit(); err != nil {
+			errs = append(errs, node.wrap(err))
+			continue
+		}
+		active, err := node.semp.ForDataSource(e.endpoint, "Redundancy").IsLocalActive(ctx)
+		if err != nil {
+			errs = append(errs, node.wrap(err))
+			continue
+		}
+		if active {
+			return node, nil
+		}
+	}
+
+	if len(errs) < len(e.nodes) {
+		errs = append(errs, errNoActiveNode)
+	}
+	return haNode{}, errors.Join(errs...)
+}
+
+// collectFromNodes scrapes the data source of descriptor from an HA pair: PerNode data sources from both nodes, all
+// others from the node activeNode returns. The data source is only up if it is up on every node scraped.
+func (e *Exporter) collectFromNodes(ctx context.Context, ch chan<- semp.PrometheusMetric, descriptor *semp.DataSourceDescriptor, query semp.DataSourceQuery, activeNode func() (haNode, error)) (float64, error) {
+	if !descriptor.PerNode {
+		node, err := activeNode()
+		if err != nil {
+			if errors.Is(err, errNoActiveNode) || errors.Is(err, semp.ErrCircuitOpen) {
+				return 0, err
+			}
+			// Neither node answered, which fails all data sources like an unreachable standalone broker.
+			return -1, err
+		}
+		up, err := e.collectFrom(ctx, ch, node.semp, descriptor, query)
+		return up, node.wrap(err)
+	}
+
+	ups := make([]float64, len(e.nodes))
+	errs := make([]error, len(e.nodes))
+	var wg sync.WaitGroup
+	for index, node := range e.nodes {
+		wg.Go(func() {
+			up, err := e.collectFrom(ctx, ch, node.semp, descriptor, query)
+			ups[index], errs[index] = up, node.wrap(err)
+		})
+	}
+	wg.Wait()
+
+	up := slices.Min(ups)
+	if up < 0 && slices.Max(ups) >= 0 {
+		// One unreachable node only fails the data sources scraped from it.
+		up = 0
+	}
+	return up, errors.Join(errs...)
+}
+
+// wrap adds the node to err, nil stays nil.
+func (node haNode) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s node %s: %w", node.name, node.semp.BrokerURI(), err)
+}
@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// haNodeServer fakes one node of an HA pair, which is local active for the redundancy role if active is set.
+func haNodeServer(t *testing.T, role string, active bool, queueRequests *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	primaryActivity, backupActivity := "Mate Active", "Mate Active"
+	if active == (role == "Primary") {
+		primaryActivity = "Local Active"
+	}
+	if active == (role == "Backup") {
+		backupActivity = "Local Active"
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch command := string(body); {
+		case strings.Contains(command, "<redundancy"):
+			_, _ = w.Write([]byte(`<rpc-reply><rpc><show><redundancy><config-status>Enabled</config-status>` +
+				`<redundancy-status>Up</redundancy-status><active-standby-role>` + role + `</active-standby-role>` +
+				`<mate-router-name>mate</mate-router-name><virtual-routers>` +
+				`<primary><status><activity>` + primaryActivity + `</activity></status></primary>` +
+				`<backup><status><activity>` + backupActivity + `</activity></status></backup>` +
+				`</virtual-routers></redundancy></show></rpc><execute-result code="ok"/></rpc-reply>`))
+		case strings.Contains(command, "<queue"):
+			queueRequests.Add(1)
+			_, _ = w.Write([]byte(`<rpc-reply><rpc><show><queue><queues><queue><name>q1</name>` +
+				`<info><message-vpn>default</message-vpn></info></queue></queues></queue></show></rpc>` +
+				`<execute-result code="ok"/></rpc-reply>`))
+		default:
+			http.Error(w, "unexpected command", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestCollectHAPair checks that node data sources are scraped from both nodes of an HA pair and message spool data
+// sources only from the active one, each series labeled with the node it came from.
+func TestCollectHAPair(t *testing.T) {
+	t.Parallel()
+
+	var primaryQueueRequests, backupQueueRequests atomic.Int32
+	// After a failover, the backup is active.
+	primary := haNodeServer(t, "Primary", false, &primaryQueueRequests)
+	backup := haNodeServer(t, "Backup", true, &backupQueueRequests)
+
+	conf := &Config{ScrapeURI: primary.URL, BackupScrapeURI: backup.URL, Timeout: time.Second, SempPageSize: 100, authType: AuthTypeBasic}
+	metrics := collectAll(t, conf, []DataSource{
+		{Name: "Redundancy", VpnFilter: "*", ItemFilter: "*"},
+		{Name: "QueueDetails", VpnFilter: "*", ItemFilter: "*"},
+	})
+
+	names := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		names[metric.Name()] = true
+	}
+	for _, want := range []string{
+		`solace_system_redundancy_local_active{mate_name="mate",node="primary"}`,
+		`solace_system_redundancy_local_active{mate_name="mate",node="backup"}`,
+		`solace_queue_spool_quota_bytes{vpn_name="default",queue_name="q1",node="backup"}`,
+		`solace_up{error="",endpoint="Redundancy"}`,
+		`solace_up{error="",endpoint="QueueDetails"}`,
+	} {
+		if !names[want] {
+			t.Errorf("missing %s in %v", want, names)
+		}
+	}
+	if primaryQueueRequests.Load() != 0 || backupQueueRequests.Load() != 1 {
+		t.Errorf("queue requests to primary/backup = %d/%d, want 0/1", primaryQueueRequests.Load(), backupQueueRequests.Load())
+	}
+
+	// The node labeled series must match the descriptions of the exporter.
+	dataSources := []DataSource{{Name: "QueueDetails", VpnFilter: "*", ItemFilter: "*"}}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewExporter(t.Context(), slog.New(slog.NewTextHandler(io.Discard, nil)), conf, "test", &dataSources))
+	if _, err := registry.Gather(); err != nil {
+		t.Errorf("gathering the HA pair failed: %v", err)
+	}
+}
+
+// TestCollectHAPairWithoutActiveNode checks that message spool data sources fail while neither node is active, and
+// node data sources are still scraped.
+func TestCollectHAPairWithoutActiveNode(t *testing.T) {
+	t.Parallel()
+
+	var queueRequests atomic.Int32
+	primary := haNodeServer(t, "Primary", false, &queueRequests)
+	backup := haNodeServer(t, "Backup", false, &queueRequests)
+
+	conf := &Config{ScrapeURI: primary.URL, BackupScrapeURI: backup.URL, Timeout: time.Second, SempPageSize: 100, authType: AuthTypeBasic}
+	metrics := collectAll(t, conf, []DataSource{
+		{Name: "Redundancy", VpnFilter: "*", ItemFilter: "*"},
+		{Name: "QueueDetails", VpnFilter: "*", ItemFilter: "*"},
+	})
+
+	var redundancyUp, queueDown bool
+	for _, metric := range metrics {
+		name := metric.Name()
+		redundancyUp = redundancyUp || name == `solace_up{error="",endpoint="Redundancy"}`
+		queueDown = queueDown || strings.HasPrefix(name, `solace_up{error="`+errNoActiveNode.Error()) && strings.HasSuffix(name, `endpoint="QueueDetails"}`)
+	}
+	if !redundancyUp || !queueDown {
+		t.Errorf("want Redundancy up and QueueDetails down without an active node, got %d metrics", len(metrics))
+	}
+	if queueRequests.Load() != 0 {
+		t.Errorf("got %d queue requests without an active node, want none", queueRequests.Load())
+	}
+}
@@ -0,0 +1,30 @@
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"solace_exporter/internal/semp"
+)
+
+// probeDataSource is a single cheap SEMP request (`show version`) that every broker answers.
+var probeDataSource = DataSource{Name: "Version", VpnFilter: "*", ItemFilter: "*"}
+
+// ProbeBroker checks that the broker of conf is reachable with its credentials, by scraping probeDataSource. It goes
+// through the limiter and circuit breaker of the broker like any scrape; its SEMP requests are instrumented with the
+// endpoint "readyz".
+func ProbeBroker(ctx context.Context, logger *slog.Logger, conf *Config) error {
+	dataSource := []DataSource{probeDataSource}
+	exp := NewExporter(ctx, logger, conf, "readyz", &dataSource)
+
+	var ch = make(chan semp.PrometheusMetric, capMetricChan)
+	var err error
+	go func() {
+		defer close(ch)
+		err = exp.CollectPrometheusMetric(ctx, ch)
+	}()
+	for range ch {
+	}
+
+	// ch is closed after err was set.
+	return err
+}
@@ -3,29 +3,60 @@ package exporter
 import (
 	"context"
 	"log/slog"
+	"net/http"
 	"solace_exporter/internal/semp"
 )
 
 // Exporter collects Solace stats from the given URI and exports them using
 // the prometheus metrics package.
 type Exporter struct {
+	// ctx bounds the SEMP calls of Collect, which gets no context from prometheus.Collector. It is the context of the
+	// scrape request, so that an abandoned scrape stops calling the broker.
+	ctx        context.Context
 	config     *Config
+	endpoint   string
 	dataSource *[]DataSource
 	logger     *slog.Logger
 	semp       *semp.Semp
+	// nodes are the primary and the backup node of an HA pair, nil for a standalone broker.
+	nodes []haNode
 }
 
-// NewExporter returns an initialized Exporter.
-func NewExporter(ctx context.Context, logger *slog.Logger, conf *Config, dataSource *[]DataSource) *Exporter {
+// NewExporter returns an initialized Exporter. Its Collect stops calling the broker once ctx is done. endpoint is the
+// URL path the metrics are served on, a label of the exporter's self-instrumentation.
+func NewExporter(ctx context.Context, logger *slog.Logger, conf *Config, endpoint string, dataSource *[]DataSource) *Exporter {
 	httpVisitor, err := conf.httpVisitor(ctx)
 	if err != nil {
 		logger.Error("Failed to create HTTP visitor for exporter", "err", err)
 	}
 
+	primary := conf.newSemp(logger, conf.ScrapeURI, httpVisitor)
+	var nodes []haNode
+	if conf.BackupScrapeURI != "" {
+		nodes = []haNode{
+			{name: nodePrimary, semp: primary.ForNode(nodePrimary)},
+			{name: nodeBackup, semp: conf.newSemp(logger, conf.BackupScrapeURI, httpVisitor).ForNode(nodeBackup)},
+		}
+	}
+
 	return &Exporter{
+		ctx:        ctx,
 		logger:     logger,
 		config:     conf,
+		endpoint:   endpoint,
 		dataSource: dataSource,
-		semp:       semp.NewSemp(logger, conf.ScrapeURI, conf.newHTTPClient(), httpVisitor, conf.logBrokerToSlowWarnings, conf.IsHWBroker),
+		semp:       primary,
+		nodes:      nodes,
 	}
 }
+
+// newSemp returns a Semp for the broker at scrapeURI, with the credentials and SEMP settings of conf.
+func (conf *Config) newSemp(logger *slog.Logger, scrapeURI string, httpVisitor func(*http.Request)) *semp.Semp {
+	// One limiter and circuit breaker per broker, shared by every exporter that scrapes it.
+	limiter := semp.BrokerLimiter(scrapeURI, conf.ParallelSempConnections, conf.SempRequestsPerSecond)
+
+	retry := semp.RetryPolicy{Retries: conf.SempRetries, Backoff: conf.SempRetryBackoff}
+	breaker := semp.BrokerCircuitBreaker(scrapeURI, conf.CircuitBreakerFailures, conf.CircuitBreakerCooldown)
+
+	return semp.NewSemp(logger, scrapeURI, conf.newHTTPClient(), httpVisitor, conf.logBrokerToSlowWarnings, conf.IsHWBroker, limiter, retry, breaker)
+}
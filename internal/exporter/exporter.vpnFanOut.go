@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"solace_exporter/internal/semp"
+	"strings"
+	"sync"
+)
+
+// discoversVpns reports whether per VPN SEMP v2 data sources with vpnFilter are fanned out across the discovered
+// message VPNs, see collectFromVpns. Otherwise they scrape the single VPN getVpnName returns.
+func (e *Exporter) discoversVpns(vpnFilter string) bool {
+	return e.config.VpnDiscoveryInterval > 0 && strings.ContainsAny(vpnFilter, "*?[")
+}
+
+// collectFromVpns scrapes the SEMP v2 data source of descriptor once for every message VPN of the broker that matches
+// the VPN filter of query, a glob. Of a broker with many VPNs, at most parallelSempConnections VPNs are scraped at once,
+// by all fanned out data sources together, see semp.Semp.AcquireVpnScrape. The data source is only up if it is up for
+// every VPN.
+func (e *Exporter) collectFromVpns(ctx context.Context, ch chan<- semp.PrometheusMetric, dataSourceSemp *semp.Semp, descriptor *semp.DataSourceDescriptor, query semp.DataSourceQuery) (float64, error) {
+	vpnNames, err := dataSourceSemp.DiscoverVpns(ctx, e.config.VpnDiscoveryInterval)
+	if err != nil {
+		return 0, err
+	}
+	var matching []string
+	for _, vpnName := range vpnNames {
+		match, err := path.Match(query.VpnFilter, vpnName)
+		if err != nil {
+			return 0, fmt.Errorf("invalid vpnFilter %q: %w", query.VpnFilter, err)
+		}
+		if match {
+			matching = append(matching, vpnName)
+		}
+	}
+	if len(matching) == 0 {
+		return 1, nil
+	}
+
+	ups := make([]float64, len(matching))
+	errs := make([]error, len(matching))
+	var wg sync.WaitGroup
+	for index, vpnName := range matching {
+		release, err := dataSourceSemp.AcquireVpnScrape(ctx)
+		if err != nil {
+			ups[index], errs[index] = -1, err
+			continue
+		}
+		wg.Go(func() {
+			defer release()
+			vpnQuery := query
+			vpnQuery.VpnFilter = vpnName
+			up, err := descriptor.Collect(ctx, dataSourceSemp, ch, vpnQuery)
+			if err != nil {
+				err = fmt.Errorf("vpn %s: %w", vpnName, err)
+			}
+			ups[index], errs[index] = up, err
+		})
+	}
+	wg.Wait()
+
+	return slices.Min(ups), errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package exporter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCollectFansOutAcrossDiscoveredVpns(t *testing.T) {
+	t.Parallel()
+
+	var discoveries, inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/SEMP/v2/monitor/msgVpns" {
+			discoveries.Add(1)
+			_, _ = w.Write([]byte(`{"data":[{"msgVpnName":"test"},{"msgVpnName":"prod-b"},{"msgVpnName":"prod-a"}],"meta":{"responseCode":200}}`))
+			return
+		}
+
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		vpnName := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/SEMP/v2/monitor/msgVpns/"), "/queues")
+		_, _ = fmt.Fprintf(w, `{"data":[{"queueName":"q1","msgVpnName":%q,"spooledMsgCount":1}],"meta":{"responseCode":200}}`, vpnName)
+	}))
+	t.Cleanup(server.Close)
+
+	conf := &Config{ScrapeURI: server.URL, Timeout: time.Second, ParallelSempConnections: 2, VpnDiscoveryInterval: time.Minute, authType: AuthTypeBasic}
+	for range 2 {
+		metrics := collectAll(t, conf, []DataSource{{Name: "QueueStatsV2", VpnFilter: "prod-*", ItemFilter: "*"}})
+
+		var vpns []string
+		var up bool
+		for _, metric := range metrics {
+			name := metric.Name()
+			up = up || name == `solace_up{error="",endpoint="QueueStatsV2"}`
+			if strings.HasPrefix(name, "solace_queue_byte_spooled{") {
+				vpns = append(vpns, name)
+			}
+		}
+		slices.Sort(vpns)
+		want := []string{`solace_queue_byte_spooled{vpn_name="prod-a",queue_name="q1"}`, `solace_queue_byte_spooled{vpn_name="prod-b",queue_name="q1"}`}
+		if !up || !slices.Equal(vpns, want) {
+			t.Errorf("got up %v and %q, want up and %q", up, vpns, want)
+		}
+	}
+
+	if n := discoveries.Load(); n != 1 {
+		t.Errorf("got %d discoveries, want 1: the VPNs must be cached for vpnDiscoveryInterval", n)
+	}
+	if n := maxInFlight.Load(); n > 2 {
+		t.Errorf("got %d VPNs scraped at once, want at most parallelSempConnections", n)
+	}
+}
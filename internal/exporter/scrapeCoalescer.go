@@ -0,0 +1,215 @@
+package exporter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"solace_exporter/internal/semp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+var scrapesCoalescedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "solace_exporter_scrapes_coalesced_total",
+	Help: "Scrapes answered without an own collection: shared with an identical in-flight scrape, or from the scrape cache.",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(scrapesCoalescedTotal)
+}
+
+// scrapes coalesces the synchronous scrapes of all exporters.
+var scrapes = newScrapeCoalescer()
+
+type cachedScrape struct {
+	metrics   []semp.PrometheusMetric
+	expiresAt time.Time
+}
+
+// sharedScrape is the context of an in-flight collection, which is cancelled once no scrape waits for it anymore, or
+// at the latest deadline of the scrapes waiting for it. Without a timer, a waiter has no deadline.
+type sharedScrape struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	waiters  int
+	deadline time.Time
+	timer    *time.Timer
+}
+
+// scrapeCoalescer lets identical concurrent scrapes (same broker, credentials, endpoint and data sources, like the two
+// scrapes of an HA Prometheus pair) share one collection, and optionally caches its result. It follows the pattern of
+// secret.Resolver: concurrent collections of one key are collapsed into one (sf), and a caller whose own ctx is done
+// returns right away. Unlike there, the shared collection keeps running only as long as any caller still waits for it.
+type scrapeCoalescer struct {
+	mu     sync.Mutex
+	cache  map[string]cachedScrape
+	shared map[string]*sharedScrape
+
+	sf singleflight.Group
+}
+
+func newScrapeCoalescer() *scrapeCoalescer {
+	return &scrapeCoalescer{
+		cache:  map[string]cachedScrape{},
+		shared: map[string]*sharedScrape{},
+	}
+}
+
+// collect returns the metrics of the scrape identified by key: from the cache, from an identical in-flight scrape, or
+// by calling collectMetrics. A collected result is cached for ttl; 0 disables the cache. If ctx is done first, it
+// returns the cause.
+func (c *scrapeCoalescer) collect(ctx context.Context, key string, ttl time.Duration, collectMetrics func(ctx context.Context) []semp.PrometheusMetric) ([]semp.PrometheusMetric, error) {
+	if metrics, found := c.cacheGet(key); found {
+		scrapesCoalescedTotal.WithLabelValues("cached").Inc()
+		return metrics, nil
+	}
+
+	shared := c.join(ctx, key)
+	defer c.leave(key, shared)
+
+	type result struct {
+		metrics   []semp.PrometheusMetric
+		fromCache bool
+	}
+	// Only the closure of the caller that wins the singleflight race runs.
+	var collected bool
+	ch := c.sf.DoChan(key, func() (interface{}, error) {
+		// Re-check the cache: another scrape may have populated it between our check above and acquiring the
+		// singleflight slot.
+		if metrics, found := c.cacheGet(key); found {
+			return result{metrics: metrics, fromCache: true}, nil
+		}
+
+		collected = true
+		metrics := collectMetrics(shared.ctx)
+		if shared.ctx.Err() == nil {
+			c.cacheSet(key, metrics, ttl)
+		}
+		return result{metrics: metrics}, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	case out := <-ch:
+		res := out.Val.(result)
+		if res.fromCache {
+			scrapesCoalescedTotal.WithLabelValues("cached").Inc()
+		} else if !collected {
+			scrapesCoalescedTotal.WithLabelValues("shared").Inc()
+		}
+		return res.metrics, nil
+	}
+}
+
+// join registers the caller as waiter of the collection of key. The first waiter creates its context, detached from
+// the caller's cancellation, so the other waiters are not cut off. It is bounded by the latest deadline of its waiters:
+// a later waiter with more time left extends it, and one without a deadline lifts it.
+func (c *scrapeCoalescer) join(ctx context.Context, key string) *sharedScrape {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deadline, hasDeadline := ctx.Deadline()
+	shared, ok := c.shared[key]
+	if ok && shared.ctx.Err() != nil {
+		// Its deadline passed while waiters remained; its result is truncated, so start a new collection.
+		c.sf.Forget(key)
+		ok = false
+	}
+	if !ok {
+		shared = &sharedScrape{}
+		shared.ctx, shared.cancel = context.WithCancel(context.WithoutCancel(ctx))
+		if hasDeadline {
+			shared.deadline = deadline
+			shared.timer = time.AfterFunc(time.Until(deadline), shared.cancel)
+		}
+		c.shared[key] = shared
+	} else if shared.timer != nil {
+		if !hasDeadline {
+			shared.timer.Stop()
+			shared.timer = nil
+		} else if deadline.After(shared.deadline) {
+			shared.deadline = deadline
+			shared.timer.Reset(time.Until(deadline))
+		}
+	}
+	shared.waiters++
+	return shared
+}
+
+// leave unregisters a waiter. The last one cancels the collection, which has either finished or is not needed anymore.
+// A cancelled collection may still be in flight; it is forgotten, so that the next scrape of key does not get its
+// truncated result but starts an own collection.
+func (c *scrapeCoalescer) leave(key string, shared *sharedScrape) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	shared.waiters--
+	if shared.waiters > 0 {
+		return
+	}
+	if shared.timer != nil {
+		shared.timer.Stop()
+	}
+	shared.cancel()
+	if c.shared[key] == shared {
+		delete(c.shared, key)
+		c.sf.Forget(key)
+	}
+}
+
+func (c *scrapeCoalescer) cacheGet(key string) ([]semp.PrometheusMetric, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, found := c.cache[key]
+	if !found {
+		return nil, false
+	}
+	if time.Now().After(cached.expiresAt) {
+		delete(c.cache, key)
+		return nil, false
+	}
+	return cached.metrics, true
+}
+
+func (c *scrapeCoalescer) cacheSet(key string, metrics []semp.PrometheusMetric, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ttl <= 0 {
+		delete(c.cache, key)
+		return
+	}
+
+	now := time.Now()
+	// Per-request credentials make many keys possible; drop the expired ones instead of letting the cache grow.
+	for cachedKey, cached := range c.cache {
+		if now.After(cached.expiresAt) {
+			delete(c.cache, cachedKey)
+		}
+	}
+	c.cache[key] = cachedScrape{metrics: metrics, expiresAt: now.Add(ttl)}
+}
+
+// scrapeKey identifies the scrapes that may share a collection: same broker, credentials, scrape settings, endpoint and
+// data sources in any order. It is hashed, so the credentials are not kept as map keys.
+func (e *Exporter) scrapeKey() string {
+	conf := e.config
+	hash := sha256.New()
+	_, _ = fmt.Fprintf(hash, "%q %q %q %q %q %q %q %v %v %v %v %d %q %v %q",
+		conf.ScrapeURI, conf.BackupScrapeURI, conf.Username, conf.Password, conf.OAuthClientID, conf.OAuthClientSecret, conf.OAuthTokenURL,
+		conf.IsHWBroker, conf.DetectHWBroker, conf.SslVerify, conf.Timeout, conf.SempPageSize, conf.DefaultVpn, conf.VpnDiscoveryInterval, e.endpoint)
+	dataSources := make([]string, len(*e.dataSource))
+	for index, dataSource := range *e.dataSource {
+		dataSources[index] = dataSource.String()
+	}
+	sort.Strings(dataSources)
+	for _, dataSource := range dataSources {
+		_, _ = fmt.Fprintf(hash, " %q", dataSource)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
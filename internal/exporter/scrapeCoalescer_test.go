@@ -0,0 +1,219 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"solace_exporter/internal/semp"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingCollect returns a collectMetrics func that counts its calls and blocks until release is closed or its ctx
+// is done, which it reports on cancelled.
+func blockingCollect(calls *atomic.Int32, release <-chan struct{}, cancelled chan<- struct{}) func(ctx context.Context) []semp.PrometheusMetric {
+	return func(ctx context.Context) []semp.PrometheusMetric {
+		calls.Add(1)
+		select {
+		case <-release:
+			return []semp.PrometheusMetric{{}}
+		case <-ctx.Done():
+			close(cancelled)
+			return nil
+		}
+	}
+}
+
+func TestScrapeCoalescerSharesInFlightCollection(t *testing.T) {
+	t.Parallel()
+
+	c := newScrapeCoalescer()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	collectMetrics := blockingCollect(&calls, release, make(chan struct{}))
+
+	var wg sync.WaitGroup
+	results := make([][]semp.PrometheusMetric, 2)
+	for i := range results {
+		wg.Go(func() {
+			results[i], _ = c.collect(t.Context(), "key", 0, collectMetrics)
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d collections, want 1 shared", got)
+	}
+	for i, metrics := range results {
+		if len(metrics) != 1 {
+			t.Errorf("scrape %d got %d metrics, want 1", i, len(metrics))
+		}
+	}
+}
+
+func TestScrapeCoalescerCache(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		wantCalls int32
+	}{
+		{name: "disabled", ttl: 0, wantCalls: 2},
+		{name: "enabled", ttl: time.Minute, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newScrapeCoalescer()
+			var calls atomic.Int32
+			release := make(chan struct{})
+			close(release)
+			collectMetrics := blockingCollect(&calls, release, make(chan struct{}))
+
+			for range 2 {
+				if metrics, _ := c.collect(t.Context(), "key", tt.ttl, collectMetrics); len(metrics) != 1 {
+					t.Errorf("got %d metrics, want 1", len(metrics))
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("got %d collections, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// TestScrapeCoalescerCancelsWhenAllScrapesLeft checks that a shared collection survives the cancellation of the scrape
+// that started it, as long as another scrape waits for it, and is cancelled once none does.
+func TestScrapeCoalescerCancelsWhenAllScrapesLeft(t *testing.T) {
+	t.Parallel()
+
+	c := newScrapeCoalescer()
+	var calls atomic.Int32
+	cancelled := make(chan struct{})
+	collectMetrics := blockingCollect(&calls, make(chan struct{}), cancelled)
+
+	firstCtx, cancelFirst := context.WithCancel(t.Context())
+	secondCtx, cancelSecond := context.WithCancel(t.Context())
+	var wg sync.WaitGroup
+	wg.Go(func() { c.collect(firstCtx, "key", 0, collectMetrics) })
+	time.Sleep(20 * time.Millisecond)
+	wg.Go(func() { c.collect(secondCtx, "key", 0, collectMetrics) })
+	time.Sleep(20 * time.Millisecond)
+
+	cancelFirst()
+	select {
+	case <-cancelled:
+		t.Fatal("collection cancelled while a scrape still waits for it")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancelSecond()
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("collection not cancelled after all scrapes left")
+	}
+	wg.Wait()
+}
+
+// TestScrapeCoalescerExtendsDeadline checks that a shared collection runs until the latest deadline of its scrapes, not
+// only until the deadline of the scrape that started it, and that the scrape timed out before reports its deadline.
+func TestScrapeCoalescerExtendsDeadline(t *testing.T) {
+	t.Parallel()
+
+	c := newScrapeCoalescer()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	cancelled := make(chan struct{})
+	collectMetrics := blockingCollect(&calls, release, cancelled)
+
+	firstCtx, cancelFirst := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancelFirst()
+	secondCtx, cancelSecond := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancelSecond()
+
+	var wg sync.WaitGroup
+	var firstErr error
+	wg.Go(func() { _, firstErr = c.collect(firstCtx, "key", 0, collectMetrics) })
+	time.Sleep(10 * time.Millisecond)
+	var secondMetrics []semp.PrometheusMetric
+	wg.Go(func() { secondMetrics, _ = c.collect(secondCtx, "key", 0, collectMetrics) })
+
+	select {
+	case <-cancelled:
+		t.Fatal("collection cancelled at the deadline of the first scrape")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	wg.Wait()
+
+	if !errors.Is(firstErr, context.DeadlineExceeded) {
+		t.Errorf("first scrape got error %v, want its deadline", firstErr)
+	}
+	if len(secondMetrics) != 1 {
+		t.Errorf("second scrape got %d metrics, want 1", len(secondMetrics))
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("got %d collections, want 1 shared", got)
+	}
+}
+
+func TestScrapeKey(t *testing.T) {
+	t.Parallel()
+
+	newExporter := func(password string, dataSources ...DataSource) *Exporter {
+		return &Exporter{config: &Config{ScrapeURI: "http://broker:8080", Username: "admin", Password: password}, endpoint: "solace", dataSource: &dataSources}
+	}
+	queues := DataSource{Name: "QueueStats", VpnFilter: "*", ItemFilter: "*"}
+	clients := DataSource{Name: "ClientStats", VpnFilter: "*", ItemFilter: "*"}
+
+	if newExporter("a", queues, clients).scrapeKey() != newExporter("a", clients, queues).scrapeKey() {
+		t.Error("the order of the data sources changed the scrape key")
+	}
+	if newExporter("a", queues).scrapeKey() == newExporter("b", queues).scrapeKey() {
+		t.Error("scrapes with different credentials got the same scrape key")
+	}
+	if newExporter("a", queues).scrapeKey() == newExporter("a", clients).scrapeKey() {
+		t.Error("scrapes of different data sources got the same scrape key")
+	}
+}
+
+// TestScrapeCoalescerRescrapesAfterCancelledCollection checks that a scrape following one that timed out does not
+// attach to the cancelled collection still in flight, but starts an own one.
+func TestScrapeCoalescerRescrapesAfterCancelledCollection(t *testing.T) {
+	t.Parallel()
+
+	c := newScrapeCoalescer()
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	// The first collection ignores its cancellation, so it is still in flight when the second scrape starts.
+	stuck := func(context.Context) []semp.PrometheusMetric {
+		<-release
+		return nil
+	}
+
+	firstCtx, cancelFirst := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancelFirst()
+	if metrics, err := c.collect(firstCtx, "key", 0, stuck); metrics != nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("timed out scrape got %d metrics and error %v, want none and the deadline", len(metrics), err)
+	}
+
+	var calls atomic.Int32
+	secondCtx, cancelSecond := context.WithTimeout(t.Context(), time.Second)
+	defer cancelSecond()
+	metrics, _ := c.collect(secondCtx, "key", 0, func(context.Context) []semp.PrometheusMetric {
+		calls.Add(1)
+		return []semp.PrometheusMetric{{}}
+	})
+	if calls.Load() != 1 {
+		t.Errorf("second scrape collected %d times, want 1", calls.Load())
+	}
+	if len(metrics) != 1 {
+		t.Errorf("second scrape got %d metrics, want 1", len(metrics))
+	}
+}
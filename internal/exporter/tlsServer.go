@@ -2,40 +2,31 @@ package exporter
 
 import (
 	"crypto/tls"
+	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
-	"github.com/prometheus/common/promslog"
 	"software.sslmate.com/src/go-pkcs12"
 )
 
-func ListenAndServeTLS(conf *Config) {
-	promlogConfig := promslog.Config{
-		Level:  promslog.NewLevel(),
-		Format: promslog.NewFormat(),
-	}
-	_ = promlogConfig.Level.Set("info")
-	_ = promlogConfig.Format.Set("logfmt")
-
-	logger := promslog.New(&promlogConfig)
-
+// NewTLSServer returns the HTTPS server for conf.ListenAddr with the configured certificate, serving
+// http.DefaultServeMux with security headers. Start it with ListenAndServeTLS("", "").
+func NewTLSServer(conf *Config) (*http.Server, error) {
 	var tlsCert tls.Certificate
 
 	if strings.ToUpper(conf.CertType) == CertTypePKCS12 {
 		// Read byte data from pkcs12 keystore
 		p12Data, err := os.ReadFile(conf.Pkcs12File)
 		if err != nil {
-			logger.Error("Error reading PKCS12 file", "err", err)
-			return
+			return nil, fmt.Errorf("reading PKCS12 file: %w", err)
 		}
 
 		// Extract cert and key from pkcs12 keystore
 		privateKey, leafCert, caCerts, err := pkcs12.DecodeChain(p12Data, conf.Pkcs12Pass)
 		if err != nil {
-			logger.Error("PKCS12 - Error decoding chain", "err", err)
-			return
+			return nil, fmt.Errorf("PKCS12 - decoding chain: %w", err)
 		}
 
 		certBytes := [][]byte{leafCert.Raw}
@@ -50,8 +41,7 @@ func ListenAndServeTLS(conf *Config) {
 		var err error
 		tlsCert, err = tls.LoadX509KeyPair(conf.Certificate, conf.PrivateKey)
 		if err != nil {
-			logger.Error("PEM - Error loading keypair", "err", err)
-			return
+			return nil, fmt.Errorf("PEM - loading keypair: %w", err)
 		}
 	}
 
@@ -85,16 +75,11 @@ func ListenAndServeTLS(conf *Config) {
 		http.DefaultServeMux.ServeHTTP(w, r)
 	})
 
-	httpServer := &http.Server{
+	return &http.Server{
 		Addr:              conf.ListenAddr,
 		Handler:           hstsHandler,
 		TLSConfig:         cfg,
 		TLSNextProto:      make(map[string]func(*http.Server, *tls.Conn, http.Handler)),
 		ReadHeaderTimeout: 5 * time.Second,
-	}
-
-	if err := httpServer.ListenAndServeTLS("", ""); err != nil {
-		logger.Error("Error starting HTTP server", "err", err)
-		os.Exit(2)
-	}
+	}, nil
 }
@@ -0,0 +1,192 @@
+package semp
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Circuit states, as exported by solace_exporter_broker_circuit_state.
+const (
+	circuitClosed   = 0
+	circuitOpen     = 1
+	circuitHalfOpen = 2
+)
+
+var circuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "solace_exporter_broker_circuit_state",
+	Help: "State of the circuit breaker of the broker: 0 closed (scraping), 1 open (not scraping), 2 half-open (probing).",
+}, []string{"broker"})
+
+func init() {
+	prometheus.MustRegister(circuitState)
+}
+
+// ErrCircuitOpen is returned instead of sending a SEMP request while the circuit breaker of the broker is open.
+var ErrCircuitOpen = errors.New("circuit_open")
+
+// CircuitBreaker stops all SEMP requests to a broker after failures consecutive requests failed, so an overloaded
+// broker is not hammered further. After cooldown a single request probes the broker (half-open): if it succeeds, the
+// circuit closes again, otherwise it stays open for another cooldown. All Semp instances of a broker share one
+// CircuitBreaker, see BrokerCircuitBreaker.
+type CircuitBreaker struct {
+	brokerUse
+	brokerURI string
+
+	mu                  sync.Mutex
+	failures            int64
+	cooldown            time.Duration
+	state               int
+	consecutiveFailures int64
+	openedAt            time.Time
+	probing             bool
+}
+
+var circuitBreakers = brokerRegistry[*CircuitBreaker]{
+	maxIdle: brokerIdleTimeout,
+	evict: func(brokerURI string, _ *CircuitBreaker) {
+		circuitState.DeleteLabelValues(brokerURI)
+	},
+}
+
+// BrokerCircuitBreaker returns the CircuitBreaker of the broker at brokerURI, shared by sync scrapes, prefetch loops
+// and named targets. failures below 1 disable it (nil is returned). Changed settings (after a config reload) apply to
+// the CircuitBreaker in place, without changing its state.
+func BrokerCircuitBreaker(brokerURI string, failures int64, cooldown time.Duration) *CircuitBreaker {
+	if failures < 1 {
+		return nil
+	}
+
+	breaker := circuitBreakers.get(brokerURI, func() *CircuitBreaker {
+		circuitState.WithLabelValues(brokerURI).Set(circuitClosed)
+		return &CircuitBreaker{brokerURI: brokerURI}
+	})
+
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	breaker.failures, breaker.cooldown = failures, cooldown
+	return breaker
+}
+
+// allow returns ErrCircuitOpen if no request may be sent to the broker now. Otherwise the caller must report the
+// outcome of its request with done.
+func (breaker *CircuitBreaker) allow() error {
+	if breaker == nil {
+		return nil
+	}
+	breaker.touch()
+
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	switch breaker.state {
+	case circuitOpen:
+		if time.Since(breaker.openedAt) < breaker.cooldown {
+			return ErrCircuitOpen
+		}
+		breaker.setState(circuitHalfOpen)
+		breaker.probing = true
+		return nil
+	case circuitHalfOpen:
+		if breaker.probing {
+			return ErrCircuitOpen
+		}
+		breaker.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// check returns ErrCircuitOpen if allow would reject a request now, without taking the probe of a half-open circuit.
+func (breaker *CircuitBreaker) check() error {
+	if breaker == nil {
+		return nil
+	}
+	breaker.touch()
+
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	if (breaker.state == circuitOpen && time.Since(breaker.openedAt) < breaker.cooldown) || (breaker.state == circuitHalfOpen && breaker.probing) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// done records the outcome of a request that allow let through. Errors that say nothing about the broker, like a
+// cancelled scrape or a rejection by the limiter, are ignored.
+func (breaker *CircuitBreaker) done(ctx context.Context, err error) {
+	if breaker == nil {
+		return
+	}
+
+	outcome := brokerOutcomeOf(ctx, err)
+
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	wasProbe := breaker.state == circuitHalfOpen && breaker.probing
+	if wasProbe {
+		breaker.probing = false
+	}
+
+	switch outcome {
+	case outcomeUnknown:
+		// Nothing learned about the broker. A half-open circuit lets the next request probe instead.
+	case outcomeFailed:
+		breaker.consecutiveFailures++
+		if wasProbe || (breaker.state == circuitClosed && breaker.consecutiveFailures >= breaker.failures) {
+			breaker.openedAt = time.Now()
+			breaker.setState(circuitOpen)
+		}
+	default:
+		breaker.consecutiveFailures = 0
+		if breaker.state != circuitClosed {
+			breaker.setState(circuitClosed)
+		}
+	}
+}
+
+func (breaker *CircuitBreaker) setState(state int) {
+	breaker.state = state
+	circuitState.WithLabelValues(breaker.brokerURI).Set(float64(state))
+}
+
+type brokerOutcome int
+
+const (
+	// outcomeUnknown is an error that is not the broker's fault, like a cancelled scrape or a rejection by the limiter.
+	outcomeUnknown brokerOutcome = iota
+	outcomeHealthy
+	outcomeFailed
+)
+
+// brokerOutcomeOf tells from the result of a request whether the broker is healthy. It failed if the request failed
+// transiently, was not answered in time, could not reach the broker, or got a server error.
+func brokerOutcomeOf(ctx context.Context, err error) brokerOutcome {
+	if err == nil {
+		return outcomeHealthy
+	}
+	if ctx.Err() != nil {
+		return outcomeUnknown
+	}
+
+	var transient *transientError
+	var transportErr *url.Error
+	var statusErr *httpStatusError
+	switch {
+	case errors.As(err, &transient), errors.As(err, &transportErr):
+		return outcomeFailed
+	case errors.As(err, &statusErr):
+		if statusErr.status >= 500 {
+			return outcomeFailed
+		}
+		return outcomeHealthy
+	}
+	return outcomeUnknown
+}
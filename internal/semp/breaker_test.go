@@ -0,0 +1,143 @@
+package semp
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newBreakerTestSemp starts a broker answering with the status in status, and returns a Semp without retries whose
+// circuit opens after two failures, and the number of requests the broker received.
+func newBreakerTestSemp(t *testing.T, status *atomic.Int32) (*Semp, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(server.Close)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	breaker := BrokerCircuitBreaker(server.URL, 2, 50*time.Millisecond)
+	return NewSemp(logger, server.URL, http.Client{Timeout: 5 * time.Second}, nil, false, false, nil, RetryPolicy{}, breaker), &requests
+}
+
+func postVersion(t *testing.T, s *Semp) error {
+	t.Helper()
+	body, err := s.postHTTP(t.Context(), s.brokerURI+"/SEMP", "application/xml", "<rpc><show><version/></show></rpc>", "Test", 1)
+	if err == nil {
+		_ = body.Close()
+	}
+	return err
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	t.Parallel()
+
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	s, requests := newBreakerTestSemp(t, &status)
+
+	for range 2 {
+		if err := postVersion(t, s); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("got %v, want the HTTP error while the circuit is closed", err)
+		}
+	}
+	if err := postVersion(t, s); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got %v, want %v after two failures", err, ErrCircuitOpen)
+	}
+	if err := s.CheckCircuit(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("CheckCircuit = %v, want %v", err, ErrCircuitOpen)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d requests, want none while the circuit is open", got)
+	}
+	if got := testutil.ToFloat64(circuitState.WithLabelValues(s.brokerURI)); got != circuitOpen {
+		t.Errorf("circuit state = %v, want open", got)
+	}
+
+	// A failing probe after the cool-down opens the circuit again.
+	time.Sleep(60 * time.Millisecond)
+	if err := postVersion(t, s); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got %v, want the HTTP error of the probe", err)
+	}
+	if err := postVersion(t, s); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got %v, want %v after a failed probe", err, ErrCircuitOpen)
+	}
+
+	// A successful probe closes it.
+	status.Store(http.StatusOK)
+	time.Sleep(60 * time.Millisecond)
+	for range 2 {
+		if err := postVersion(t, s); err != nil {
+			t.Fatalf("got %v, want success after the broker recovered", err)
+		}
+	}
+	if got := testutil.ToFloat64(circuitState.WithLabelValues(s.brokerURI)); got != circuitClosed {
+		t.Errorf("circuit state = %v, want closed", got)
+	}
+}
+
+func TestCircuitBreakerLetsOneProbeThrough(t *testing.T) {
+	t.Parallel()
+
+	breaker := BrokerCircuitBreaker("http://breaker-probe:8080", 1, time.Millisecond)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("allow on closed circuit: %v", err)
+	}
+	breaker.done(t.Context(), &httpStatusError{status: http.StatusServiceUnavailable})
+
+	time.Sleep(5 * time.Millisecond)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("allow after cool-down: %v, want the probe to pass", err)
+	}
+	if err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow during probe = %v, want %v", err, ErrCircuitOpen)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var status atomic.Int32
+	status.Store(http.StatusUnauthorized)
+	s, requests := newBreakerTestSemp(t, &status)
+
+	for range 4 {
+		if err := postVersion(t, s); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("circuit opened on %d", status.Load())
+		}
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("got %d requests, want 4", got)
+	}
+}
+
+func TestBrokerCircuitBreakerDisabled(t *testing.T) {
+	t.Parallel()
+
+	if breaker := BrokerCircuitBreaker("http://breaker-disabled:8080", 0, time.Second); breaker != nil {
+		t.Errorf("got a circuit breaker for 0 failures, want nil")
+	}
+}
+
+func TestBrokerCircuitBreakerIsSharedPerBroker(t *testing.T) {
+	t.Parallel()
+
+	a := BrokerCircuitBreaker("http://breaker-shared:8080", 2, time.Second)
+	a.done(t.Context(), &transientError{})
+	if b := BrokerCircuitBreaker("http://breaker-shared:8080", 1, time.Minute); a != b {
+		t.Fatal("changed settings must apply to the circuit breaker of the broker")
+	}
+	if a.failures != 1 || a.cooldown != time.Minute || a.consecutiveFailures != 1 {
+		t.Errorf("failures %d, cooldown %s, consecutive failures %d, want the changed settings and the failure before", a.failures, a.cooldown, a.consecutiveFailures)
+	}
+}
@@ -0,0 +1,186 @@
+package semp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+// BrokerInfo is what the exporter learns about a broker from `show version`.
+type BrokerInfo struct {
+	IsHWBroker bool
+	Version    Version
+}
+
+// brokerInfoMaxAge is how long DetectBroker reuses a BrokerInfo. The platform of a broker never changes, but its
+// version does with an upgrade.
+const brokerInfoMaxAge = 10 * time.Minute
+
+type brokerInfoEntry struct {
+	brokerUse
+	info    BrokerInfo
+	updated time.Time
+}
+
+// brokerInfos caches the BrokerInfo per broker URI, see DetectBroker.
+var brokerInfos = brokerRegistry[*brokerInfoEntry]{
+	maxIdle: brokerIdleTimeout,
+	evict: func(brokerURI string, _ *brokerInfoEntry) {
+		brokerPlatformInfo.DeletePartialMatch(prometheus.Labels{"broker": brokerURI})
+	},
+}
+
+// brokerDetections makes concurrent data sources of a broker wait for one `show version`, keyed by broker URI.
+var brokerDetections singleflight.Group
+
+var brokerPlatformInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "solace_exporter_broker_platform_info",
+	Help: "Platform the broker reported, software or appliance.",
+}, []string{"broker", "platform"})
+
+func init() {
+	prometheus.MustRegister(brokerPlatformInfo)
+}
+
+// DetectBroker returns the platform and version of the broker. It sends `show version`, unless the broker answered it
+// less than brokerInfoMaxAge ago, to this or to the Version data source. The reply carries the SEMP version of the
+// broker, which software brokers suffix with "VMR", like "soltr/10_4VMR", and the SolOS version in current-load.
+// Concurrent calls for the same broker share one request, which is detached from the cancellation of the caller that
+// sent it, but bounded by its deadline.
+func (semp *Semp) DetectBroker(ctx context.Context) (BrokerInfo, error) {
+	if info, ok := cachedBrokerInfo(semp.brokerURI); ok {
+		return info, nil
+	}
+
+	ch := brokerDetections.DoChan(semp.brokerURI, func() (interface{}, error) {
+		// Another data source may have detected the broker between our check above and acquiring the singleflight
+		// slot.
+		if info, ok := cachedBrokerInfo(semp.brokerURI); ok {
+			return info, nil
+		}
+
+		detectCtx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		target, _, err := semp.fetchVersionSemp1(detectCtx)
+		if err != nil {
+			return BrokerInfo{}, err
+		}
+		return semp.storeBrokerInfo(target)
+	})
+
+	select {
+	case <-ctx.Done():
+		return BrokerInfo{}, ctx.Err()
+	case out := <-ch:
+		return out.Val.(BrokerInfo), out.Err
+	}
+}
+
+// detachedContext returns a context for a request shared by concurrent callers, see DetectBroker: it is not cancelled
+// with ctx, but has its deadline.
+func detachedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		return context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	}
+	return context.WithCancel(context.WithoutCancel(ctx))
+}
+
+// cachedBrokerInfo returns the BrokerInfo of brokerURI if it is younger than brokerInfoMaxAge.
+func cachedBrokerInfo(brokerURI string) (BrokerInfo, bool) {
+	if entry, ok := brokerInfos.load(brokerURI); ok && time.Since(entry.updated) < brokerInfoMaxAge {
+		return entry.info, true
+	}
+	return BrokerInfo{}, false
+}
+
+// storeBrokerInfo caches the BrokerInfo of a reply to `show version`.
+func (semp *Semp) storeBrokerInfo(target *versionSemp1Reply) (BrokerInfo, error) {
+	if target.SempVersion == "" {
+		return BrokerInfo{}, errors.New("the reply to show version has no semp-version")
+	}
+	version, err := ParseVersion(target.RPC.Show.Version.CurrentLoad)
+	if err != nil {
+		return BrokerInfo{}, err
+	}
+
+	info := BrokerInfo{IsHWBroker: !strings.HasSuffix(target.SempVersion, "VMR"), Version: version}
+	previous, known := brokerInfos.store(semp.brokerURI, &brokerInfoEntry{info: info, updated: time.Now()})
+	if !known || previous.info.Version.Compare(version) != 0 {
+		platform := PlatformSoftware
+		if info.IsHWBroker {
+			platform = PlatformHardware
+		}
+		brokerPlatformInfo.WithLabelValues(semp.brokerURI, platform.String()).Set(1)
+		semp.logger.Info("Detected broker", "broker", semp.brokerURI, "platform", platform, "version", version, "sempVersion", target.SempVersion)
+	}
+
+	return info, nil
+}
+
+// ForHWBroker returns a copy of semp for a broker of the given platform.
+func (semp *Semp) ForHWBroker(isHWBroker bool) *Semp {
+	scoped := *semp
+	scoped.isHWBroker = isHWBroker
+	return &scoped
+}
+
+// Version is a SolOS version like 10.4.1.114. Missing trailing parts count as 0.
+type Version []int
+
+// ParseVersion parses a SolOS version, with or without the "soltr_" prefix of current-load.
+func ParseVersion(s string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "soltr_"), ".")
+	version := make(Version, len(parts))
+	for index, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("invalid broker version %q", s)
+		}
+		version[index] = number
+	}
+	return version, nil
+}
+
+// mustParseVersion is ParseVersion for the built-in capabilities.
+func mustParseVersion(s string) Version {
+	version, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+	return version
+}
+
+// Compare returns -1 if version is older than other, 1 if it is newer, and 0 if both are equal.
+func (version Version) Compare(other Version) int {
+	for index := range max(len(version), len(other)) {
+		var a, b int
+		if index < len(version) {
+			a = version[index]
+		}
+		if index < len(other) {
+			b = other[index]
+		}
+		if a != b {
+			if a < b {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func (version Version) String() string {
+	parts := make([]string, len(version))
+	for index, number := range version {
+		parts[index] = strconv.Itoa(number)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,155 @@
+package semp
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDetectBroker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		sempVersion    string
+		wantIsHWBroker bool
+		wantPlatform   string
+	}{
+		{name: "software", sempVersion: "soltr/10_4VMR", wantIsHWBroker: false, wantPlatform: "software"},
+		{name: "appliance", sempVersion: "soltr/9_8", wantIsHWBroker: true, wantPlatform: "appliance"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				_, _ = w.Write([]byte(`<rpc-reply semp-version="` + tt.sempVersion + `"><rpc><show><version><current-load>soltr_10.4.1.114</current-load></version></show></rpc>` +
+					`<execute-result code="ok"/></rpc-reply>`))
+			}))
+			t.Cleanup(server.Close)
+			s := NewSemp(slog.New(slog.NewTextHandler(io.Discard, nil)), server.URL, http.Client{}, nil, false, false, nil, RetryPolicy{}, nil)
+
+			for range 2 {
+				info, err := s.DetectBroker(t.Context())
+				if err != nil {
+					t.Fatalf("DetectBroker error: %v", err)
+				}
+				if info.IsHWBroker != tt.wantIsHWBroker {
+					t.Errorf("DetectBroker().IsHWBroker = %v, want %v", info.IsHWBroker, tt.wantIsHWBroker)
+				}
+				if got := info.Version.String(); got != "10.4.1.114" {
+					t.Errorf("DetectBroker().Version = %s, want 10.4.1.114", got)
+				}
+			}
+			if n := requests.Load(); n != 1 {
+				t.Errorf("got %d requests, want 1: the platform must be cached per broker", n)
+			}
+			if v := testutil.ToFloat64(brokerPlatformInfo.WithLabelValues(server.URL, tt.wantPlatform)); v != 1 {
+				t.Errorf("solace_exporter_broker_platform_info{platform=%q} = %v, want 1", tt.wantPlatform, v)
+			}
+		})
+	}
+}
+
+func TestDetectBrokerErrorIsNotCached(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+	s := NewSemp(slog.New(slog.NewTextHandler(io.Discard, nil)), server.URL, http.Client{}, nil, false, false, nil, RetryPolicy{}, nil)
+
+	for range 2 {
+		if _, err := s.DetectBroker(t.Context()); err == nil {
+			t.Fatal("DetectBroker of a broker rejecting the request: want error")
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("got %d requests, want 2: errors must not be cached", n)
+	}
+}
+
+func TestDetectBrokerConcurrently(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`<rpc-reply semp-version="soltr/10_4VMR"><rpc><show><version><current-load>soltr_10.4.1.114</current-load></version></show></rpc>` +
+			`<execute-result code="ok"/></rpc-reply>`))
+	}))
+	t.Cleanup(server.Close)
+	s := NewSemp(slog.New(slog.NewTextHandler(io.Discard, nil)), server.URL, http.Client{}, nil, false, false, nil, RetryPolicy{}, nil)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			if _, err := s.DetectBroker(t.Context()); err != nil {
+				t.Errorf("DetectBroker error: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if n := requests.Load(); n != 1 {
+		t.Errorf("got %d requests, want 1: concurrent data sources must share the detection", n)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "soltr_10.4.1.114", want: "10.4.1.114"},
+		{in: "9.0", want: "9.0"},
+		{in: "soltr_", wantErr: true},
+		{in: "10.x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		version, err := ParseVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && version.String() != tt.want {
+			t.Errorf("ParseVersion(%q) = %s, want %s", tt.in, version, tt.want)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "9.0", b: "9.0.0.0", want: 0},
+		{a: "7.1", b: "7.1.1", want: -1},
+		{a: "10.4.1", b: "9.13", want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := mustParseVersion(tt.a).Compare(mustParseVersion(tt.b)); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
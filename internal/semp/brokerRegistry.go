@@ -0,0 +1,101 @@
+package semp
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// brokerIdleTimeout is how long the state all Semp instances of a broker share outlives its last use. A scrape request
+// can name any broker (scrapeURI), so the state of the brokers no longer scraped must not pile up.
+const brokerIdleTimeout = time.Hour
+
+// brokerUse records when the shared state of a broker was last used, see brokerRegistry.
+type brokerUse struct {
+	lastUsed atomic.Int64
+}
+
+func (use *brokerUse) touch() {
+	use.lastUsed.Store(time.Now().UnixNano())
+}
+
+func (use *brokerUse) use() *brokerUse {
+	return use
+}
+
+// brokerRegistry holds the state of type V all Semp instances of a broker share, like its Limiter, keyed by broker
+// URI. An entry not used for maxIdle is evicted by a later call, and evict removes the series it exported. A Semp that
+// still holds an evicted entry keeps using it, but a new Semp of the broker gets a new one.
+type brokerRegistry[V interface{ use() *brokerUse }] struct {
+	maxIdle time.Duration
+	evict   func(brokerURI string, value V)
+
+	mu        sync.Mutex
+	entries   map[string]V
+	lastSweep time.Time
+}
+
+// get returns the entry of brokerURI, or stores and returns a new one from create if there is none.
+func (registry *brokerRegistry[V]) get(brokerURI string, create func() V) V {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.sweep()
+
+	value, ok := registry.entries[brokerURI]
+	if !ok {
+		value = create()
+		if registry.entries == nil {
+			registry.entries = make(map[string]V)
+		}
+		registry.entries[brokerURI] = value
+	}
+	value.use().touch()
+	return value
+}
+
+// load returns the entry of brokerURI, if there is one.
+func (registry *brokerRegistry[V]) load(brokerURI string) (V, bool) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.sweep()
+
+	value, ok := registry.entries[brokerURI]
+	if ok {
+		value.use().touch()
+	}
+	return value, ok
+}
+
+// store replaces the entry of brokerURI with value, and returns the entry it replaced, if there was one.
+func (registry *brokerRegistry[V]) store(brokerURI string, value V) (previous V, replaced bool) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.sweep()
+
+	previous, replaced = registry.entries[brokerURI]
+	if registry.entries == nil {
+		registry.entries = make(map[string]V)
+	}
+	registry.entries[brokerURI] = value
+	value.use().touch()
+	return previous, replaced
+}
+
+// sweep evicts the entries idle for maxIdle. It looks at most ten times per maxIdle, so an entry lives up to 10% longer.
+func (registry *brokerRegistry[V]) sweep() {
+	now := time.Now()
+	if now.Sub(registry.lastSweep) < registry.maxIdle/10 {
+		return
+	}
+	registry.lastSweep = now
+
+	for brokerURI, value := range registry.entries {
+		if now.Sub(time.Unix(0, value.use().lastUsed.Load())) < registry.maxIdle {
+			continue
+		}
+		delete(registry.entries, brokerURI)
+		if registry.evict != nil {
+			registry.evict(brokerURI, value)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package semp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBrokerRegistryEvictsIdleEntries(t *testing.T) {
+	t.Parallel()
+
+	var evicted []string
+	registry := brokerRegistry[*Limiter]{
+		maxIdle: 50 * time.Millisecond,
+		evict: func(brokerURI string, _ *Limiter) {
+			evicted = append(evicted, brokerURI)
+		},
+	}
+	create := func() *Limiter { return &Limiter{} }
+
+	idle := registry.get("http://idle:8080", create)
+	used := registry.get("http://used:8080", create)
+	time.Sleep(30 * time.Millisecond)
+	used.touch()
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := registry.load("http://idle:8080"); ok {
+		t.Error("the idle entry was not evicted")
+	}
+	if got, ok := registry.load("http://used:8080"); !ok || got != used {
+		t.Error("the entry used since was evicted")
+	}
+	if len(evicted) != 1 || evicted[0] != "http://idle:8080" {
+		t.Errorf("evicted = %q, want the idle entry", evicted)
+	}
+	if registry.get("http://idle:8080", create) == idle {
+		t.Error("an evicted broker must get a new entry")
+	}
+}
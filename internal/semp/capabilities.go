@@ -0,0 +1,113 @@
+package semp
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Capability is the oldest SolOS version that supports a data source, or one of its metrics. Older brokers reject or
+// omit the RPCs and fields, which would look like an outage.
+type Capability struct {
+	DataSource string
+	// Metric is a key of MetricDesc[DataSource], or empty if the whole data source needs MinVersion.
+	Metric     string
+	MinVersion Version
+}
+
+// capabilities is the built-in table of all data sources and metrics that not every supported SolOS version has. The
+// entry of a data source precedes those of its metrics.
+var capabilities = []Capability{
+	{DataSource: "BridgeV2", MinVersion: mustParseVersion("9.0")},
+	{DataSource: "ClientStatsV2", MinVersion: mustParseVersion("9.0")},
+	{DataSource: "ClusterLinks", MinVersion: mustParseVersion("8.5")},
+	{DataSource: "MqttSession", MinVersion: mustParseVersion("7.1.1")},
+	{DataSource: "MqttSessionV2", MinVersion: mustParseVersion("9.0")},
+	{DataSource: "QueueDetailsV2", MinVersion: mustParseVersion("9.0")},
+	{DataSource: "QueueDetailsV2", Metric: "queue_partitions", MinVersion: mustParseVersion("10.4")},
+	{DataSource: "QueueStatsV2", MinVersion: mustParseVersion("9.0")},
+	{DataSource: "ReplicationStats", MinVersion: mustParseVersion("7.1")},
+	{DataSource: "ReplicationStats", Metric: "system_replication_transitions_to_ineligible", MinVersion: mustParseVersion("7.2")},
+	{DataSource: "TopicEndpointStatsV2", MinVersion: mustParseVersion("9.0")},
+	{DataSource: "VpnReplication", MinVersion: mustParseVersion("7.1")},
+	{DataSource: "VpnReplication", Metric: "vpn_replication_transaction_replication_mode", MinVersion: mustParseVersion("7.2")},
+	{DataSource: "VpnStatsV2", MinVersion: mustParseVersion("9.0")},
+}
+
+// UnsupportedError is returned for a data source the broker is too old for.
+type UnsupportedError struct {
+	DataSource    string
+	MinVersion    Version
+	BrokerVersion Version
+}
+
+func (err *UnsupportedError) Error() string {
+	return fmt.Sprintf("data source %s needs SolOS %s or later, the broker runs %s", err.DataSource, err.MinVersion, err.BrokerVersion)
+}
+
+// Capabilities returns the version requirements of the data source and its metrics, the data source itself first.
+func (descriptor *DataSourceDescriptor) Capabilities() []Capability {
+	var result []Capability
+	for _, capability := range capabilities {
+		if capability.DataSource == descriptor.Name {
+			result = append(result, capability)
+		}
+	}
+	return result
+}
+
+// CheckVersion returns an UnsupportedError if a broker of version does not support the data source, otherwise the
+// descriptions of the metrics it does not report.
+func (descriptor *DataSourceDescriptor) CheckVersion(version Version) ([]*Desc, error) {
+	var unsupported []*Desc
+	for _, capability := range descriptor.Capabilities() {
+		if version.Compare(capability.MinVersion) >= 0 {
+			continue
+		}
+		if capability.Metric == "" {
+			return nil, &UnsupportedError{DataSource: descriptor.Name, MinVersion: capability.MinVersion, BrokerVersion: version}
+		}
+		unsupported = append(unsupported, MetricDesc[descriptor.Name][capability.Metric])
+	}
+	return unsupported, nil
+}
+
+// SupportedMetricFilter returns metricFilter without the entries that select only metrics a broker of version does not
+// support, so that their SEMP v2 fields are not requested from a broker that would reject them. If no entry is left,
+// the data source would report nothing, and an UnsupportedError is returned.
+func (descriptor *DataSourceDescriptor) SupportedMetricFilter(metricFilter []string, version Version) ([]string, error) {
+	var unsupported []*Desc
+	var firstErr *UnsupportedError
+	for _, capability := range descriptor.Capabilities() {
+		if capability.Metric == "" || version.Compare(capability.MinVersion) >= 0 {
+			continue
+		}
+		unsupported = append(unsupported, MetricDesc[descriptor.Name][capability.Metric])
+		if firstErr == nil {
+			firstErr = &UnsupportedError{DataSource: descriptor.Name, MinVersion: capability.MinVersion, BrokerVersion: version}
+		}
+	}
+	if len(metricFilter) == 0 || len(unsupported) == 0 {
+		return metricFilter, nil
+	}
+
+	// A SEMP v2 field stays selected as long as a supported metric reports it.
+	supportedField := func(field string) bool {
+		for _, descriptions := range descriptor.Metrics {
+			for _, desc := range descriptions {
+				if desc.sempV2field == field && !slices.Contains(unsupported, desc) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	supported := slices.DeleteFunc(slices.Clone(metricFilter), func(entry string) bool {
+		return slices.ContainsFunc(unsupported, func(desc *Desc) bool {
+			return entry == desc.fqName || entry == desc.sempV2field && !supportedField(entry)
+		})
+	})
+	if len(supported) == 0 {
+		return nil, firstErr
+	}
+	return supported, nil
+}
@@ -0,0 +1,80 @@
+package semp
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCapabilitiesReferToKnownTargets(t *testing.T) {
+	t.Parallel()
+
+	for _, capability := range capabilities {
+		descriptor, ok := LookupDataSource(capability.DataSource)
+		if !ok || descriptor.Name != capability.DataSource {
+			t.Errorf("capability of unknown data source %q", capability.DataSource)
+			continue
+		}
+		if _, ok := MetricDesc[capability.DataSource][capability.Metric]; capability.Metric != "" && !ok {
+			t.Errorf("capability of unknown metric %s/%s", capability.DataSource, capability.Metric)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	t.Parallel()
+
+	descriptor, _ := LookupDataSource("VpnReplication")
+	mode := MetricDesc["VpnReplication"]["vpn_replication_transaction_replication_mode"]
+
+	tests := []struct {
+		version         string
+		wantUnsupported bool
+		wantMetrics     []*Desc
+	}{
+		{version: "7.0.1", wantUnsupported: true},
+		{version: "7.1.2", wantMetrics: []*Desc{mode}},
+		{version: "10.4.1.114"},
+	}
+
+	for _, tt := range tests {
+		metrics, err := descriptor.CheckVersion(mustParseVersion(tt.version))
+		var unsupported *UnsupportedError
+		if errors.As(err, &unsupported) != tt.wantUnsupported {
+			t.Errorf("CheckVersion(%s) error = %v, want unsupported %v", tt.version, err, tt.wantUnsupported)
+		}
+		if len(metrics) != len(tt.wantMetrics) || (len(metrics) > 0 && metrics[0] != tt.wantMetrics[0]) {
+			t.Errorf("CheckVersion(%s) = %v, want %v", tt.version, metrics, tt.wantMetrics)
+		}
+	}
+}
+
+func TestSupportedMetricFilter(t *testing.T) {
+	t.Parallel()
+
+	descriptor, _ := LookupDataSource("QueueDetailsV2")
+
+	tests := []struct {
+		version         string
+		metricFilter    []string
+		want            []string
+		wantUnsupported bool
+	}{
+		{version: "10.4", metricFilter: []string{"solace_queue_partitions", "owner"}, want: []string{"solace_queue_partitions", "owner"}},
+		{version: "10.3", metricFilter: []string{"solace_queue_partitions", "owner"}, want: []string{"owner"}},
+		{version: "10.3", metricFilter: []string{"partitionCount", "solace_queue_binds_max"}, want: []string{"solace_queue_binds_max"}},
+		{version: "10.3", metricFilter: nil, want: nil},
+		{version: "10.3", metricFilter: []string{"solace_queue_partitions"}, wantUnsupported: true},
+	}
+
+	for _, tt := range tests {
+		got, err := descriptor.SupportedMetricFilter(tt.metricFilter, mustParseVersion(tt.version))
+		var unsupported *UnsupportedError
+		if errors.As(err, &unsupported) != tt.wantUnsupported {
+			t.Errorf("SupportedMetricFilter(%q, %s) error = %v, want unsupported %v", tt.metricFilter, tt.version, err, tt.wantUnsupported)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SupportedMetricFilter(%q, %s) = %q, want %q", tt.metricFilter, tt.version, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package semp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Platform restricts a data source to software or hardware (appliance) brokers.
+type Platform int
+
+const (
+	PlatformAny Platform = iota
+	PlatformSoftware
+	PlatformHardware
+)
+
+func (platform Platform) String() string {
+	switch platform {
+	case PlatformSoftware:
+		return "software"
+	case PlatformHardware:
+		return "appliance"
+	default:
+		return "software, appliance"
+	}
+}
+
+// Supports reports whether a data source of this platform can be scraped from a broker with the given type.
+func (platform Platform) Supports(isHWBroker bool) bool {
+	switch platform {
+	case PlatformSoftware:
+		return !isHWBroker
+	case PlatformHardware:
+		return isHWBroker
+	default:
+		return true
+	}
+}
+
+// DataSourceQuery holds the parameters one data source of a scrape is called with.
+type DataSourceQuery struct {
+	// VpnFilter is a single VPN name for SEMP v2 data sources.
+	VpnFilter    string
+	ItemFilter   string
+	MetricFilter []string
+	PageSize     int64
+}
+
+// DataSourceDescriptor describes one scrape target, which can be used in an endpoint section or as m.<Name> parameter.
+type DataSourceDescriptor struct {
+	Name        string
+	Aliases     []string
+	SempVersion int
+	Platform    Platform
+
+	// The filters the data source honors. All others are ignored.
+	VpnFilter    bool
+	ItemFilter   bool
+	MetricFilter bool
+
+	// CrossVpn SEMP v2 data sources query a collection across all message VPNs, which takes the VPN filter as it is.
+	// All other SEMP v2 data sources query the collection of a single VPN, see PerVpn.
+	CrossVpn bool
+
+	// PerNode data sources report the state of the node itself, like its health and redundancy. Of an HA pair they are
+	// scraped from both nodes, all others only from the active one.
+	PerNode bool
+
+	// Performance describes the load a scrape puts on the broker.
+	Performance string
+	// Metrics are the metric families the data source produces.
+	Metrics []Descriptions
+
+	// Collect scrapes the data source. It must stop when ctx is done.
+	Collect func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error)
+}
+
+// Names returns the name followed by all aliases of the data source.
+func (descriptor *DataSourceDescriptor) Names() []string {
+	return append([]string{descriptor.Name}, descriptor.Aliases...)
+}
+
+// PerVpn reports whether the data source queries the SEMP v2 collection of a single message VPN, like
+// /SEMP/v2/monitor/msgVpns/{msgVpnName}/queues, so its VPN filter must name one VPN.
+func (descriptor *DataSourceDescriptor) PerVpn() bool {
+	return descriptor.SempVersion == 2 && !descriptor.CrossVpn
+}
+
+// CheckMetricFilter returns an error if the data source does not support metricFilter.
+func (descriptor *DataSourceDescriptor) CheckMetricFilter(metricFilter []string) error {
+	if len(metricFilter) == 0 {
+		return nil
+	}
+	if !descriptor.MetricFilter {
+		return fmt.Errorf("metric filters are only supported by SEMP v2 targets and would be ignored")
+	}
+	for _, descriptions := range descriptor.Metrics {
+		if err := descriptions.CheckMetricFilter(metricFilter); err != nil {
+			return fmt.Errorf("invalid metric filter: %w", err)
+		}
+	}
+	return nil
+}
+
+var dataSources = make(map[string]*DataSourceDescriptor)
+
+// RegisterDataSource makes descriptor available under its name and all aliases. It is meant to be called from the
+// init function of the file that implements the data source, and panics if a name is already taken.
+func RegisterDataSource(descriptor *DataSourceDescriptor) {
+	if descriptor.Collect == nil {
+		panic("data source " + descriptor.Name + " has no Collect func")
+	}
+	for _, name := range descriptor.Names() {
+		if _, exists := dataSources[name]; exists {
+			panic("data source " + name + " registered twice")
+		}
+		dataSources[name] = descriptor
+	}
+}
+
+// LookupDataSource returns the data source registered under name or one of its aliases.
+func LookupDataSource(name string) (*DataSourceDescriptor, bool) {
+	descriptor, ok := dataSources[name]
+	return descriptor, ok
+}
+
+// DataSources returns all registered data sources, ordered by name.
+func DataSources() []*DataSourceDescriptor {
+	var descriptors []*DataSourceDescriptor
+	for name, descriptor := range dataSources {
+		if name == descriptor.Name {
+			descriptors = append(descriptors, descriptor)
+		}
+	}
+	sort.Slice(descriptors, func(i, j int) bool {
+		return descriptors[i].Name < descriptors[j].Name
+	})
+	return descriptors
+}
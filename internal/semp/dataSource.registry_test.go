@@ -0,0 +1,92 @@
+package semp
+
+import (
+	"testing"
+)
+
+func TestDataSourceRegistry(t *testing.T) {
+	t.Parallel()
+
+	descriptors := DataSources()
+	if len(descriptors) == 0 {
+		t.Fatal("no data sources registered")
+	}
+
+	for i, descriptor := range descriptors {
+		if i > 0 && descriptors[i-1].Name >= descriptor.Name {
+			t.Errorf("DataSources() not ordered by name: %q before %q", descriptors[i-1].Name, descriptor.Name)
+		}
+		if descriptor.SempVersion != 1 && descriptor.SempVersion != 2 {
+			t.Errorf("%s: unexpected SEMP version %d", descriptor.Name, descriptor.SempVersion)
+		}
+		if descriptor.MetricFilter && descriptor.SempVersion != 2 {
+			t.Errorf("%s: metric filters need SEMP v2", descriptor.Name)
+		}
+		if len(descriptor.Metrics) == 0 {
+			t.Errorf("%s: no metric families", descriptor.Name)
+		}
+		for j, descriptions := range descriptor.Metrics {
+			if len(descriptions) == 0 {
+				t.Errorf("%s: metric family %d is empty, check its MetricDesc key", descriptor.Name, j)
+			}
+		}
+		for _, name := range descriptor.Names() {
+			if got, ok := LookupDataSource(name); !ok || got != descriptor {
+				t.Errorf("LookupDataSource(%q) does not return %s", name, descriptor.Name)
+			}
+		}
+	}
+}
+
+func TestDataSourceCheckMetricFilter(t *testing.T) {
+	t.Parallel()
+
+	v2, _ := LookupDataSource("QueueStatsV2")
+	v1, _ := LookupDataSource("QueueStatsV1")
+
+	tests := []struct {
+		name         string
+		descriptor   *DataSourceDescriptor
+		metricFilter []string
+		wantErr      bool
+	}{
+		{name: "no filter on v1", descriptor: v1},
+		{name: "filter on v1", descriptor: v1, metricFilter: []string{"solace_queue_msg_spooled"}, wantErr: true},
+		{name: "metric name on v2", descriptor: v2, metricFilter: []string{"solace_queue_msg_spooled"}},
+		{name: "semp v2 field on v2", descriptor: v2, metricFilter: []string{"spooledMsgCount"}},
+		{name: "unknown metric on v2", descriptor: v2, metricFilter: []string{"solace_queue_typo"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.descriptor.CheckMetricFilter(tt.metricFilter); (err != nil) != tt.wantErr {
+				t.Errorf("CheckMetricFilter(%q) error = %v, wantErr %v", tt.metricFilter, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlatformSupports(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		platform   Platform
+		isHWBroker bool
+		want       bool
+	}{
+		{PlatformAny, false, true},
+		{PlatformAny, true, true},
+		{PlatformSoftware, false, true},
+		{PlatformSoftware, true, false},
+		{PlatformHardware, false, false},
+		{PlatformHardware, true, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.platform.Supports(tt.isHWBroker); got != tt.want {
+			t.Errorf("%s.Supports(%t) = %t, want %t", tt.platform, tt.isHWBroker, got, tt.want)
+		}
+	}
+}
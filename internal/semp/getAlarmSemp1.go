@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"io"
 	"solace_exporter/internal/semp/types"
@@ -8,8 +9,23 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Alarm",
+		Aliases:     []string{"AlarmV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Platform:    PlatformHardware,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Alarm"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetAlarmSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetAlarmSemp1 Get system Alarm information.
-func (semp *Semp) GetAlarmSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetAlarmSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -25,7 +41,7 @@ func (semp *Semp) GetAlarmSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	}
 
 	command := "<rpc><show><alarm/></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "AlarmSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "AlarmSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape AlarmSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -41,6 +57,7 @@ func (semp *Semp) GetAlarmSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml AlarmSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"regexp"
@@ -12,9 +13,24 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "BridgeClientCert",
+		Aliases:     []string{"BridgeClientCertV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["BridgeClientCert"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetBridgeClientCertSemp1(ctx, ch, query.VpnFilter, query.ItemFilter, query.PageSize)
+		},
+	})
+}
+
 // GetBridgeClientCertSemp1 Get client certificate validity for all bridges
 // SEMPv1 returns an openssl-text style dump (not PEM); the first chain entry is the leaf cert
-func (semp *Semp) GetBridgeClientCertSemp1(ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetBridgeClientCertSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -41,7 +57,10 @@ func (semp *Semp) GetBridgeClientCertSemp1(ch chan<- PrometheusMetric, vpnFilter
 	var page = 1
 	var lastBridgeName = ""
 	for command := fmt.Sprintf("<rpc><show><bridge><bridge-name-pattern>"+itemFilter+"</bridge-name-pattern><vpn-name-pattern>"+vpnFilter+"</vpn-name-pattern><client-certificate/><count/><num-elements>%d</num-elements></bridge></show></rpc>", sempPageSize); command != ""; {
-		body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "BridgeClientCertSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "BridgeClientCertSemp1", page)
 		page++
 
 		if err != nil {
@@ -54,6 +73,7 @@ func (semp *Semp) GetBridgeClientCertSemp1(ch chan<- PrometheusMetric, vpnFilter
 		err = decoder.Decode(&target)
 		if err != nil {
 			semp.logger.Error("Can't decode Xml BridgeClientCertSemp1", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
 			_ = body.Close()
 			return 0, err
 		}
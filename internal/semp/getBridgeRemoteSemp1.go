@@ -1,15 +1,31 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "BridgeRemote",
+		Aliases:     []string{"BridgeRemoteV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Bridge"], MetricDesc["BridgeRemote"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetBridgeRemoteSemp1(ctx, ch, query.VpnFilter, query.ItemFilter)
+		},
+	})
+}
+
 // GetBridgeRemoteSemp1 Get status of bridges for all VPNs
 // Same as GetBridge but adds labels for remote VPN and remote router
-func (semp *Semp) GetBridgeRemoteSemp1(ch chan<- PrometheusMetric, vpnFilter string, itemFilter string) (float64, error) {
+func (semp *Semp) GetBridgeRemoteSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -45,7 +61,7 @@ func (semp *Semp) GetBridgeRemoteSemp1(ch chan<- PrometheusMetric, vpnFilter str
 	}
 
 	command := "<rpc><show><bridge><bridge-name-pattern>" + itemFilter + "</bridge-name-pattern><vpn-name-pattern>" + vpnFilter + "</vpn-name-pattern></bridge></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "BridgeRemoteSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "BridgeRemoteSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape BridgeRemoteSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -56,6 +72,7 @@ func (semp *Semp) GetBridgeRemoteSemp1(ch chan<- PrometheusMetric, vpnFilter str
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml BridgeRemoteSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
     "fmt"
 	"solace_exporter/internal/semp/types"
@@ -8,8 +9,23 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Bridge",
+		Aliases:     []string{"BridgeV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Bridge"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetBridgeSemp1(ctx, ch, query.VpnFilter, query.ItemFilter, query.PageSize)
+		},
+	})
+}
+
 // GetBridgeSemp1 status of bridges for all VPNs
-func (semp *Semp) GetBridgeSemp1(ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetBridgeSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -48,7 +64,10 @@ func (semp *Semp) GetBridgeSemp1(ch chan<- PrometheusMetric, vpnFilter string, i
     var page = 1
 	var lastBridgeName = ""
 	for command := fmt.Sprintf("<rpc><show><bridge><bridge-name-pattern>" + itemFilter + "</bridge-name-pattern><vpn-name-pattern>" + vpnFilter + "</vpn-name-pattern><count/><num-elements>%d</num-elements></bridge></show></rpc>", sempPageSize); command != ""; {
-        body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "BridgeSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+        body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "BridgeSemp1", page)
         page++
 
         if err != nil {
@@ -61,6 +80,7 @@ func (semp *Semp) GetBridgeSemp1(ch chan<- PrometheusMetric, vpnFilter string, i
         err = decoder.Decode(&target)
         if err != nil {
             semp.logger.Error("Can't decode Xml BridgeSemp1", "err", err, "broker", semp.brokerURI)
+            semp.observeDecodeError()
             _ = body.Close()
             return 0, err
         }
@@ -0,0 +1,297 @@
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:         "BridgeV2",
+		SempVersion:  2,
+		VpnFilter:    true,
+		ItemFilter:   true,
+		MetricFilter: true,
+		Performance:  "may harm broker if many bridges, the remote message VPNs and subscriptions need one more request per bridge each",
+		Metrics:      []Descriptions{MetricDesc["BridgeV2"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetBridgeSemp2(ctx, ch, query.VpnFilter, query.ItemFilter, query.MetricFilter, query.PageSize)
+		},
+	})
+}
+
+// bridgeRemoteMsgVpnSemp2 is an object of the remoteMsgVpns collection of a bridge.
+type bridgeRemoteMsgVpnSemp2 struct {
+	RemoteMsgVpnName     string `json:"remoteMsgVpnName"`
+	RemoteMsgVpnLocation string `json:"remoteMsgVpnLocation"`
+	Enabled              bool   `json:"enabled"`
+	Up                   bool   `json:"up"`
+	BoundToQueue         bool   `json:"boundToQueue"`
+}
+
+// GetBridgeSemp2 Get state, connection and counters of each individual bridge of one VPN, with its remote message VPNs
+// from its remoteMsgVpns collection and the number of its remote subscriptions from its remoteSubscriptions collection.
+// itemFilter is a bridge name, or a SEMP v2 where condition. With a metricFilter, the remote message VPNs and
+// subscriptions are only requested if it names one of their metrics.
+func (semp *Semp) GetBridgeSemp2(ctx context.Context, ch chan<- PrometheusMetric, vpnName string, itemFilter string, metricFilter []string, sempPageSize int64) (float64, error) {
+	type Response struct {
+		Bridge []struct {
+			BridgeName          string  `json:"bridgeName"`
+			BridgeVirtualRouter string  `json:"bridgeVirtualRouter"`
+			MsgVpnName          string  `json:"msgVpnName"`
+			RemoteRouterName    string  `json:"remoteRouterName"`
+			RemoteMsgVpnName    string  `json:"remoteMsgVpnName"`
+			Enabled             bool    `json:"enabled"`
+			Establisher         string  `json:"establisher"`
+			InboundState        string  `json:"inboundState"`
+			OutboundState       string  `json:"outboundState"`
+			BoundToQueue        bool    `json:"boundToQueue"`
+			LocalQueueName      string  `json:"localQueueName"`
+			Uptime              float64 `json:"uptime"`
+			RxMsgCount          float64 `json:"rxMsgCount"`
+			TxMsgCount          float64 `json:"txMsgCount"`
+			DataRxMsgCount      float64 `json:"dataRxMsgCount"`
+			DataTxMsgCount      float64 `json:"dataTxMsgCount"`
+			RxByteCount         float64 `json:"rxByteCount"`
+			TxByteCount         float64 `json:"txByteCount"`
+			DataRxByteCount     float64 `json:"dataRxByteCount"`
+			DataTxByteCount     float64 `json:"dataTxByteCount"`
+			RxMsgRate           float64 `json:"rxMsgRate"`
+			TxMsgRate           float64 `json:"txMsgRate"`
+			DiscardedRxMsgCount float64 `json:"discardedRxMsgCount"`
+			DiscardedTxMsgCount float64 `json:"discardedTxMsgCount"`
+		} `json:"data"`
+		Meta struct {
+			Count        int64 `json:"count"`
+			ResponseCode int   `json:"responseCode"`
+			Paging       struct {
+				CursorQuery string `json:"cursorQuery"`
+				NextPageURI string `json:"nextPageUri"`
+			} `json:"paging"`
+			Error struct {
+				Code        int    `json:"code"`
+				Description string `json:"description"`
+				Status      string `json:"status"`
+			} `json:"error"`
+		} `json:"meta"`
+	}
+
+	var getParameter = fmt.Sprintf("count=%d", sempPageSize)
+
+	if len(strings.TrimSpace(itemFilter)) > 0 && itemFilter != "*" {
+		if strings.Contains(itemFilter, "=") {
+			getParameter += "&where=" + queryEscape(itemFilter)
+		} else {
+			getParameter += "&where=" + queryEscape("bridgeName=="+itemFilter)
+		}
+	}
+
+	// Unlike the other SEMP v2 data sources, the mandatory fields are added to the select only, because the labels of
+	// the remote router would otherwise select bridge_connected_remote_info.
+	var fieldsToSelect []string
+	var subCollections = map[string][]string{"remoteSubscriptions": nil, "remoteMsgVpns": nil}
+	if len(metricFilter) > 0 {
+		var err error
+
+		fieldsToSelect, err = getSempV2FieldsToSelect(metricFilter, nil, BridgeV2)
+		if err != nil {
+			semp.logger.Error("Unable to map metric filter", "err", err, "broker", semp.brokerURI)
+			return 0, err
+		}
+
+		var bridgeFields []string
+		bridgeFields, subCollections = selectSubCollections(fieldsToSelect, "remoteSubscriptions", "remoteMsgVpns")
+		if BridgeV2["bridge_queue_operational_state"].isSelected(fieldsToSelect) {
+			bridgeFields = append(bridgeFields, "localQueueName")
+		}
+		for _, field := range []string{"bridgeName", "bridgeVirtualRouter", "msgVpnName", "remoteRouterName", "remoteMsgVpnName"} {
+			if !slices.Contains(bridgeFields, field) {
+				bridgeFields = append(bridgeFields, field)
+			}
+		}
+		getParameter += "&select=" + strings.Join(bridgeFields, ",")
+	}
+	_, remoteSubscriptions := subCollections["remoteSubscriptions"]
+	remoteMsgVpnFields, remoteMsgVpns := subCollections["remoteMsgVpns"]
+
+	// The same states in the same order as the ones of Bridge, with their SEMP v2 names.
+	opStates := []string{"init", "disabled", "enabled", "prepare", "prepare-wait-to-connect",
+		"prepare-fetching-dns", "not-ready", "not-ready-connecting", "not-ready-handshaking", "not-ready-wait-next",
+		"not-ready-wait-reuse", "not-ready-wait-bridge-version-mismatch", "not-ready-wait-cleanup", "ready", "ready-subscribing",
+		"ready-in-sync", "not-applicable", "invalid"}
+
+	var page = 1
+	var lastBridgeName = ""
+	var bridgesURI = semp.brokerURI + "/SEMP/v2/monitor/msgVpns/" + url.PathEscape(vpnName) + "/bridges"
+	for nextURL := bridgesURI + "?" + getParameter; nextURL != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json", "BridgeSemp2", page)
+		page++
+
+		if err != nil {
+			semp.logger.Error("Can't scrape BridgeSemp2", "command", nextURL, "err", err, "broker", semp.brokerURI)
+			return -1, err
+		}
+
+		var response Response
+		err = json.Unmarshal(body, &response)
+		if err != nil {
+			semp.logger.Error("Can't decode BridgeSemp2", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
+			return 0, err
+		}
+		if response.Meta.ResponseCode != 200 {
+			semp.logger.Error("unexpected result", "command", nextURL, "remoteError", response.Meta.Error.Description, "broker", semp.brokerURI)
+			return 0, errors.New("unexpected result: see log")
+		}
+
+		semp.logger.Debug("Result of BridgeSemp2", "results", len(response.Bridge), "page", page-1)
+
+		nextURL = response.Meta.Paging.NextPageURI
+		for _, bridge := range response.Bridge {
+			bridgeKey := bridge.MsgVpnName + "___" + bridge.BridgeName
+			if bridgeKey == lastBridgeName {
+				continue
+			}
+			lastBridgeName = bridgeKey
+
+			// Like Bridge, 0 is enabled.
+			adminState := 0.0
+			if !bridge.Enabled {
+				adminState = 1
+			}
+			queueState := "unbound"
+			if bridge.LocalQueueName == "" {
+				queueState = "not-applicable"
+			} else if bridge.BoundToQueue {
+				queueState = "bound"
+			}
+			var values = []V2Result{
+				{v2Desc: BridgeV2["bridge_admin_state"], valueType: prometheus.GaugeValue, value: adminState},
+				{v2Desc: BridgeV2["bridge_connection_establisher"], valueType: prometheus.GaugeValue, value: encodeMetricMulti(bridge.Establisher, []string{"not-applicable", "local", "remote", "invalid"})},
+				{v2Desc: BridgeV2["bridge_inbound_operational_state"], valueType: prometheus.GaugeValue, value: encodeMetricMulti(bridge.InboundState, opStates)},
+				{v2Desc: BridgeV2["bridge_outbound_operational_state"], valueType: prometheus.GaugeValue, value: encodeMetricMulti(bridge.OutboundState, opStates)},
+				{v2Desc: BridgeV2["bridge_queue_operational_state"], valueType: prometheus.GaugeValue, value: encodeMetricMulti(queueState, []string{"not-applicable", "bound", "unbound"})},
+				{v2Desc: BridgeV2["bridge_connection_uptime_in_seconds"], valueType: prometheus.GaugeValue, value: bridge.Uptime},
+			}
+			for _, v := range values {
+				if v.v2Desc.isSelected(fieldsToSelect) {
+					ch <- semp.NewMetric(v.v2Desc, v.valueType, v.value, bridge.MsgVpnName, bridge.BridgeName)
+				}
+			}
+			if BridgeV2["bridge_connected_remote_info"].isSelected(fieldsToSelect) {
+				ch <- semp.NewMetric(BridgeV2["bridge_connected_remote_info"], prometheus.GaugeValue, 1, bridge.MsgVpnName, bridge.BridgeName, bridge.RemoteMsgVpnName, bridge.RemoteRouterName)
+			}
+
+			// The value types of BridgeStats.
+			var stats = []V2Result{
+				{v2Desc: BridgeV2["bridge_total_client_messages_received"], valueType: prometheus.CounterValue, value: bridge.RxMsgCount},
+				{v2Desc: BridgeV2["bridge_total_client_messages_sent"], valueType: prometheus.CounterValue, value: bridge.TxMsgCount},
+				{v2Desc: BridgeV2["bridge_client_data_messages_received"], valueType: prometheus.GaugeValue, value: bridge.DataRxMsgCount},
+				{v2Desc: BridgeV2["bridge_client_data_messages_sent"], valueType: prometheus.GaugeValue, value: bridge.DataTxMsgCount},
+				{v2Desc: BridgeV2["bridge_total_client_bytes_received"], valueType: prometheus.CounterValue, value: bridge.RxByteCount},
+				{v2Desc: BridgeV2["bridge_total_client_bytes_sent"], valueType: prometheus.CounterValue, value: bridge.TxByteCount},
+				{v2Desc: BridgeV2["bridge_client_data_bytes_received"], valueType: prometheus.GaugeValue, value: bridge.DataRxByteCount},
+				{v2Desc: BridgeV2["bridge_client_data_bytes_sent"], valueType: prometheus.GaugeValue, value: bridge.DataTxByteCount},
+				{v2Desc: BridgeV2["bridge_current_ingress_rate_per_second"], valueType: prometheus.GaugeValue, value: bridge.RxMsgRate},
+				{v2Desc: BridgeV2["bridge_current_egress_rate_per_second"], valueType: prometheus.GaugeValue, value: bridge.TxMsgRate},
+				{v2Desc: BridgeV2["bridge_total_ingress_discards"], valueType: prometheus.CounterValue, value: bridge.DiscardedRxMsgCount},
+				{v2Desc: BridgeV2["bridge_total_egress_discards"], valueType: prometheus.CounterValue, value: bridge.DiscardedTxMsgCount},
+			}
+			for _, v := range stats {
+				if v.v2Desc.isSelected(fieldsToSelect) {
+					ch <- semp.NewMetric(v.v2Desc, v.valueType, v.value, bridge.MsgVpnName, bridge.BridgeName, bridge.RemoteRouterName, bridge.RemoteMsgVpnName)
+				}
+			}
+
+			bridgeURI := bridgesURI + "/" + url.PathEscape(bridge.BridgeName) + "," + url.PathEscape(bridge.BridgeVirtualRouter)
+			if remoteSubscriptions {
+				count, err := semp.countSempV2Collection(ctx, bridgeURI+fmt.Sprintf("/remoteSubscriptions?count=%d&select=remoteSubscriptionTopic", sempPageSize), "BridgeRemoteSubscriptionsSemp2")
+				if err != nil {
+					return subCollectionUp(err), err
+				}
+				ch <- semp.NewMetric(BridgeV2["bridge_remote_subscriptions"], prometheus.GaugeValue, count, bridge.MsgVpnName, bridge.BridgeName)
+			}
+
+			if remoteMsgVpns {
+				uri := bridgeURI + fmt.Sprintf("/remoteMsgVpns?count=%d", sempPageSize)
+				if len(remoteMsgVpnFields) > 0 {
+					uri += "&select=" + strings.Join(append(remoteMsgVpnFields, "remoteMsgVpnName", "remoteMsgVpnLocation"), ",")
+				}
+				remotes, err := semp.getBridgeRemoteMsgVpnsSemp2(ctx, uri)
+				if err != nil {
+					return subCollectionUp(err), err
+				}
+				for _, remote := range remotes {
+					var values = []V2Result{
+						{v2Desc: BridgeV2["bridge_remote_vpn_enabled"], valueType: prometheus.GaugeValue, value: encodeMetricBool(remote.Enabled)},
+						{v2Desc: BridgeV2["bridge_remote_vpn_up"], valueType: prometheus.GaugeValue, value: encodeMetricBool(remote.Up)},
+						{v2Desc: BridgeV2["bridge_remote_vpn_queue_bound"], valueType: prometheus.GaugeValue, value: encodeMetricBool(remote.BoundToQueue)},
+					}
+					for _, v := range values {
+						if v.v2Desc.isSelected(fieldsToSelect) {
+							ch <- semp.NewMetric(v.v2Desc, v.valueType, v.value, bridge.MsgVpnName, bridge.BridgeName, remote.RemoteMsgVpnName, remote.RemoteMsgVpnLocation)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return 1, nil
+}
+
+// getBridgeRemoteMsgVpnsSemp2 returns the remote message VPNs of the remoteMsgVpns collection of uri, following its
+// pages. Errors are those of countSempV2Collection.
+func (semp *Semp) getBridgeRemoteMsgVpnsSemp2(ctx context.Context, uri string) ([]bridgeRemoteMsgVpnSemp2, error) {
+	type Response struct {
+		RemoteMsgVpn []bridgeRemoteMsgVpnSemp2 `json:"data"`
+		Meta         struct {
+			ResponseCode int `json:"responseCode"`
+			Paging       struct {
+				NextPageURI string `json:"nextPageUri"`
+			} `json:"paging"`
+			Error struct {
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"meta"`
+	}
+
+	var remotes []bridgeRemoteMsgVpnSemp2
+	var page = 1
+	for nextURL := uri; nextURL != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return nil, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json", "BridgeRemoteMsgVpnsSemp2", page)
+		page++
+		if err != nil {
+			semp.logger.Error("Can't scrape BridgeRemoteMsgVpnsSemp2", "command", nextURL, "err", err, "broker", semp.brokerURI)
+			return nil, err
+		}
+
+		var response Response
+		if err := json.Unmarshal(body, &response); err != nil {
+			semp.logger.Error("Can't decode BridgeRemoteMsgVpnsSemp2", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
+			return nil, errors.Join(errUnexpectedSubCollection, err)
+		}
+		if response.Meta.ResponseCode != 200 {
+			semp.logger.Error("unexpected result", "command", nextURL, "remoteError", response.Meta.Error.Description, "broker", semp.brokerURI)
+			return nil, errUnexpectedSubCollection
+		}
+
+		remotes = append(remotes, response.RemoteMsgVpn...)
+		nextURL = response.Meta.Paging.NextPageURI
+	}
+	return remotes, nil
+}
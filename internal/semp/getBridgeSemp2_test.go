@@ -0,0 +1,111 @@
+package semp
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// TestGetBridgeSemp2ReportsAllMetricsWithoutMetricFilter checks that without a metric filter every bridge metric is
+// reported, including the remote subscriptions and message VPNs, each of a bridge on both virtual routers only once.
+func TestGetBridgeSemp2ReportsAllMetricsWithoutMetricFilter(t *testing.T) {
+	t.Parallel()
+
+	s, requests := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/default/bridges": `{"data":[` +
+			`{"bridgeName":"b1","bridgeVirtualRouter":"primary","msgVpnName":"default","remoteRouterName":"r2","remoteMsgVpnName":"far",` +
+			`"enabled":true,"inboundState":"ready-in-sync","localQueueName":"q","boundToQueue":true,"rxMsgCount":5,"uptime":60},` +
+			`{"bridgeName":"b1","bridgeVirtualRouter":"backup","msgVpnName":"default"}` +
+			`],"meta":{"responseCode":200}}`,
+		"/SEMP/v2/monitor/msgVpns/default/bridges/b1,primary/remoteSubscriptions": `{"data":[{"remoteSubscriptionTopic":"a/>"}],"meta":{"responseCode":200}}`,
+		"/SEMP/v2/monitor/msgVpns/default/bridges/b1,primary/remoteMsgVpns":       `{"data":[{"remoteMsgVpnName":"far","remoteMsgVpnLocation":"v:r2","enabled":true,"up":true,"boundToQueue":true}],"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 100)
+	up, err := s.GetBridgeSemp2(t.Context(), ch, "default", "*", nil, 100)
+	close(ch)
+	if up != 1 || err != nil {
+		t.Fatalf("GetBridgeSemp2 = %v, %v (requests %q)", up, err, requests())
+	}
+	if got := requests(); len(got) != 3 {
+		t.Errorf("got requests %q, want the bridges, and the remote subscriptions and message VPNs of b1 once", got)
+	}
+
+	metrics := map[string]string{}
+	for metric := range ch {
+		metrics[metric.Name()] = strconv.FormatFloat(metric.value, 'g', -1, 64)
+	}
+	for name, want := range map[string]string{
+		`solace_bridge_admin_state{vpn_name="default",bridge_name="b1"}`:                                                                  "0",
+		`solace_bridge_inbound_operational_state{vpn_name="default",bridge_name="b1"}`:                                                    "15",
+		`solace_bridge_queue_operational_state{vpn_name="default",bridge_name="b1"}`:                                                      "1",
+		`solace_bridge_connection_uptime_in_seconds{vpn_name="default",bridge_name="b1"}`:                                                 "60",
+		`solace_bridge_connected_remote_info{vpn_name="default",bridge_name="b1",remote_vpn_name="far",remote_router="r2"}`:               "1",
+		`solace_bridge_total_client_messages_received{vpn_name="default",bridge_name="b1",remote_router_name="r2",remote_vpn_name="far"}`: "5",
+		`solace_bridge_remote_subscriptions{vpn_name="default",bridge_name="b1"}`:                                                         "1",
+		`solace_bridge_remote_vpn_up{vpn_name="default",bridge_name="b1",remote_vpn_name="far",remote_vpn_location="v:r2"}`:               "1",
+		`solace_bridge_remote_vpn_queue_bound{vpn_name="default",bridge_name="b1",remote_vpn_name="far",remote_vpn_location="v:r2"}`:      "1",
+	} {
+		if got, ok := metrics[name]; !ok || got != want {
+			t.Errorf("%s = %q (reported %v), want %s", name, got, ok, want)
+		}
+	}
+	if len(metrics) != len(BridgeV2) {
+		t.Errorf("got %d series, want one of each of the %d metrics", len(metrics), len(BridgeV2))
+	}
+}
+
+func TestGetBridgeSemp2SkipsRemoteSubscriptionsNotInMetricFilter(t *testing.T) {
+	t.Parallel()
+
+	s, requests := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/default/bridges":                       `{"data":[{"bridgeName":"b1","bridgeVirtualRouter":"auto","msgVpnName":"default"}],"meta":{"responseCode":200}}`,
+		"/SEMP/v2/monitor/msgVpns/default/bridges/b1,auto/remoteMsgVpns": `{"data":[{"remoteMsgVpnName":"far","remoteMsgVpnLocation":"v:r2","up":true,"boundToQueue":true}],"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 10)
+	up, err := s.GetBridgeSemp2(t.Context(), ch, "default", "b1", []string{"solace_bridge_remote_vpn_up", "solace_bridge_remote_vpn_queue_bound", "uptime"}, 100)
+	close(ch)
+	if up != 1 || err != nil {
+		t.Fatalf("GetBridgeSemp2 = %v, %v", up, err)
+	}
+
+	want := []string{
+		"/SEMP/v2/monitor/msgVpns/default/bridges?count=100&where=bridgeName%3D%3Db1&select=uptime,bridgeName,bridgeVirtualRouter,msgVpnName,remoteRouterName,remoteMsgVpnName",
+		"/SEMP/v2/monitor/msgVpns/default/bridges/b1,auto/remoteMsgVpns?count=100&select=up,boundToQueue,remoteMsgVpnName,remoteMsgVpnLocation",
+	}
+	if got := requests(); !slices.Equal(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+
+	var names []string
+	for metric := range ch {
+		names = append(names, metric.Name())
+	}
+	slices.Sort(names)
+	wantNames := []string{
+		`solace_bridge_connection_uptime_in_seconds{vpn_name="default",bridge_name="b1"}`,
+		`solace_bridge_remote_vpn_queue_bound{vpn_name="default",bridge_name="b1",remote_vpn_name="far",remote_vpn_location="v:r2"}`,
+		`solace_bridge_remote_vpn_up{vpn_name="default",bridge_name="b1",remote_vpn_name="far",remote_vpn_location="v:r2"}`,
+	}
+	if !slices.Equal(names, wantNames) {
+		t.Errorf("metrics = %q, want %q", names, wantNames)
+	}
+}
+
+func TestGetBridgeSemp2FailsOnUnavailableSubCollection(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/default/bridges": `{"data":[{"bridgeName":"b1","bridgeVirtualRouter":"auto","msgVpnName":"default"}],"meta":{"responseCode":200}}`,
+	})
+
+	for _, metric := range []string{"solace_bridge_remote_vpn_up", "solace_bridge_remote_subscriptions"} {
+		ch := make(chan PrometheusMetric, 10)
+		up, err := s.GetBridgeSemp2(t.Context(), ch, "default", "*", []string{metric}, 100)
+		close(ch)
+		if up != -1 || err == nil {
+			t.Errorf("GetBridgeSemp2 of %s = %v, %v, want -1 and the error of the failed request", metric, up, err)
+		}
+	}
+}
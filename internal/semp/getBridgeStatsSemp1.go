@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
     "fmt"
 	"solace_exporter/internal/semp/types"
@@ -8,8 +9,23 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "BridgeStats",
+		Aliases:     []string{"BridgeStatsV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "has a very small performance down site",
+		Metrics:     []Descriptions{MetricDesc["BridgeStats"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetBridgeStatsSemp1(ctx, ch, query.VpnFilter, query.ItemFilter, query.PageSize)
+		},
+	})
+}
+
 // GetBridgeStatsSemp1 statistics of bridges for all VPNs
-func (semp *Semp) GetBridgeStatsSemp1(ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetBridgeStatsSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -106,7 +122,10 @@ func (semp *Semp) GetBridgeStatsSemp1(ch chan<- PrometheusMetric, vpnFilter stri
     var page = 1
 	var lastBridgeName = ""
 	for command := fmt.Sprintf("<rpc><show><bridge><bridge-name-pattern>" + itemFilter + "</bridge-name-pattern><vpn-name-pattern>" + vpnFilter + "</vpn-name-pattern><stats/><count/><num-elements>%d</num-elements></bridge></show></rpc>", sempPageSize); command != ""; {
-        body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "BridgeStatsSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+        body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "BridgeStatsSemp1", page)
         page++
 
         if err != nil {
@@ -119,6 +138,7 @@ func (semp *Semp) GetBridgeStatsSemp1(ch chan<- PrometheusMetric, vpnFilter stri
         err = decoder.Decode(&target)
         if err != nil {
             semp.logger.Error("Can't decode Xml BridgeStatsSemp1", "err", err, "broker", semp.brokerURI)
+            semp.observeDecodeError()
             _ = body.Close()
             return 0, err
         }
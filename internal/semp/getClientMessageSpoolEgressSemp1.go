@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"strconv"
 	"strings"
@@ -10,6 +11,20 @@ import (
 	"solace_exporter/internal/semp/types"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ClientMessageSpoolEgress",
+		Aliases:     []string{"ClientMessageSpoolEgressV1"},
+		SempVersion: 1,
+		ItemFilter:  true,
+		Performance: "may harm broker if many clients",
+		Metrics:     []Descriptions{MetricDesc["ClientMessageSpoolEgress"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetClientMessageSpoolEgressSemp1(ctx, ch, query.ItemFilter)
+		},
+	})
+}
+
 // GetClientMessageSpoolEgressSemp1 emits one client_endpoint_egress_bind_time_seconds
 // gauge per (client, endpoint) binding. A single client can be bound to multiple
 // endpoints, so each <flow> under <flows-to-client> becomes one series.
@@ -17,7 +32,7 @@ import (
 // itemFilter is the broker-side client-name wildcard (passed verbatim into
 // <name>); the broker handles the wildcard. There is no VPN or endpoint-name
 // filter at the SEMP level for `show client ... message-spool egress`.
-func (semp *Semp) GetClientMessageSpoolEgressSemp1(ch chan<- PrometheusMetric, itemFilter string) (float64, error) {
+func (semp *Semp) GetClientMessageSpoolEgressSemp1(ctx context.Context, ch chan<- PrometheusMetric, itemFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -54,7 +69,7 @@ func (semp *Semp) GetClientMessageSpoolEgressSemp1(ch chan<- PrometheusMetric, i
 	// The broker does not support paging (<count/><num-elements>) for
 	// `show client ... message-spool egress connected`, so this is a single request.
 	command := "<rpc><show><client><name>" + itemFilter + "</name><message-spool/><egress/><connected/></client></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ClientMessageSpoolEgressSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ClientMessageSpoolEgressSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape ClientMessageSpoolEgressSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -64,6 +79,7 @@ func (semp *Semp) GetClientMessageSpoolEgressSemp1(ch chan<- PrometheusMetric, i
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode ClientMessageSpoolEgressSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		_ = body.Close()
 		return 0, err
 	}
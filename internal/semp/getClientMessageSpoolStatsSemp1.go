@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"solace_exporter/internal/semp/types"
@@ -9,9 +10,23 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ClientMessageSpoolStats",
+		Aliases:     []string{"ClientMessageSpoolStatsV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		Performance: "may harm broker if many clients",
+		Metrics:     []Descriptions{MetricDesc["ClientMessageSpoolStats"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetClientMessageSpoolStatsSemp1(ctx, ch, query.VpnFilter, query.PageSize)
+		},
+	})
+}
+
 // GetClientMessageSpoolStatsSemp1 Get some statistics for each individual client of all VPNs
 // This can result in heavy system load for lots of clients
-func (semp *Semp) GetClientMessageSpoolStatsSemp1(ch chan<- PrometheusMetric, itemFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetClientMessageSpoolStatsSemp1(ctx context.Context, ch chan<- PrometheusMetric, itemFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -67,7 +82,10 @@ func (semp *Semp) GetClientMessageSpoolStatsSemp1(ch chan<- PrometheusMetric, it
 	var page = 1
 	var lastClientName = ""
 	for command := fmt.Sprintf("<rpc><show><client><name>"+itemFilter+"</name><message-spool-stats/><count/><num-elements>%d</num-elements></client></show></rpc>", sempPageSize); command != ""; {
-		body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ClientMessageSpoolStatsSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ClientMessageSpoolStatsSemp1", page)
 		page++
 
 		if err != nil {
@@ -79,6 +97,7 @@ func (semp *Semp) GetClientMessageSpoolStatsSemp1(ch chan<- PrometheusMetric, it
 		err = decoder.Decode(&target)
 		if err != nil {
 			semp.logger.Error("Can't decode ClientMessageSpoolStatsSemp1", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
 			_ = body.Close()
 			return 0, err
 		}
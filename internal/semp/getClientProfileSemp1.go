@@ -1,14 +1,29 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ClientProfile",
+		Aliases:     []string{"ClientProfileV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["ClientProfile"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetClientProfileSemp1(ctx, ch, query.VpnFilter)
+		},
+	})
+}
+
 // GetDiskSemp1 Get system disk information (for Appliance)
-func (semp *Semp) GetClientProfileSemp1(ch chan<- PrometheusMetric, vpnFilter string) (float64, error) {
+func (semp *Semp) GetClientProfileSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -33,7 +48,7 @@ func (semp *Semp) GetClientProfileSemp1(ch chan<- PrometheusMetric, vpnFilter st
 	}
 
 	command := "<rpc><show><client-profile><name>*</name><vpn-name>" + vpnFilter + "</vpn-name><detail/></client-profile></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "DiskSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "DiskSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape ClientProfiles", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -44,6 +59,7 @@ func (semp *Semp) GetClientProfileSemp1(ch chan<- PrometheusMetric, vpnFilter st
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml ClientProfiles", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
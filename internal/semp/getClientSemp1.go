@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 	"strings"
@@ -8,9 +9,24 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Client",
+		Aliases:     []string{"ClientV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "may harm broker if many clients",
+		Metrics:     []Descriptions{MetricDesc["Client"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetClientSemp1(ctx, ch, query.VpnFilter, query.ItemFilter)
+		},
+	})
+}
+
 // GetClientSemp1 Get summary for each client of VPNs
 // This can result in heavy system load when lots of clients are connected
-func (semp *Semp) GetClientSemp1(ch chan<- PrometheusMetric, vpnFilter string, itemFilter string) (float64, error) {
+func (semp *Semp) GetClientSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -32,7 +48,10 @@ func (semp *Semp) GetClientSemp1(ch chan<- PrometheusMetric, vpnFilter string, i
 
 	var page = 1
 	for command := "<rpc><show><client><name>" + itemFilter + "</name><vpn-name>" + vpnFilter + "</vpn-name><connected/></client></show></rpc>"; command != ""; {
-		body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ClientSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ClientSemp1", page)
 		page++
 
 		if err != nil {
@@ -44,6 +63,7 @@ func (semp *Semp) GetClientSemp1(ch chan<- PrometheusMetric, vpnFilter string, i
 		err = decoder.Decode(&target)
 		if err != nil {
 			semp.logger.Error("Can't decode ClientSemp1", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
 			_ = body.Close()
 			return 0, err
 		}
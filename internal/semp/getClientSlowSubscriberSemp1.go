@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 	"strings"
@@ -8,9 +9,24 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ClientSlowSubscriber",
+		Aliases:     []string{"ClientSlowSubscriberV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "may harm broker if many clients but less expensive than `ClientStats`",
+		Metrics:     []Descriptions{MetricDesc["ClientSlowSubscriber"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetClientSlowSubscriberSemp1(ctx, ch, query.VpnFilter, query.ItemFilter)
+		},
+	})
+}
+
 // GetClientSlowSubscriberSemp1 Get slow subscriber client of VPNs
 // This can result in heavy system load when lots of clients are connected
-func (semp *Semp) GetClientSlowSubscriberSemp1(ch chan<- PrometheusMetric, vpnFilter string, itemFilter string) (float64, error) {
+func (semp *Semp) GetClientSlowSubscriberSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -32,7 +48,10 @@ func (semp *Semp) GetClientSlowSubscriberSemp1(ch chan<- PrometheusMetric, vpnFi
 
 	var page = 1
 	for command := "<rpc><show><client><name>" + itemFilter + "</name><vpn-name>" + vpnFilter + "</vpn-name><slow-subscriber/></client></show></rpc>"; command != ""; {
-		body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ClientSlowSubscriberSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ClientSlowSubscriberSemp1", page)
 		page++
 
 		if err != nil {
@@ -45,6 +64,7 @@ func (semp *Semp) GetClientSlowSubscriberSemp1(ch chan<- PrometheusMetric, vpnFi
 		err = decoder.Decode(&target)
 		if err != nil {
 			semp.logger.Error("Can't decode ClientSlowSubscriberSemp1", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
 			_ = body.Close()
 			return 0, err
 		}
@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
     "fmt"
 	"solace_exporter/internal/semp/types"
@@ -8,9 +9,34 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ClientConnections",
+		Aliases:     []string{"ClientConnectionsV1"},
+		SempVersion: 1,
+		ItemFilter:  true,
+		Performance: "may harm broker if many clients",
+		Metrics:     []Descriptions{MetricDesc["ClientConnections"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetClientConnectionStatsSemp1(ctx, ch, query.ItemFilter)
+		},
+	})
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ClientStats",
+		Aliases:     []string{"ClientStatsV1"},
+		SempVersion: 1,
+		ItemFilter:  true,
+		Performance: "may harm broker if many clients",
+		Metrics:     []Descriptions{MetricDesc["ClientStats"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetClientStatsSemp1(ctx, ch, query.ItemFilter, query.PageSize)
+		},
+	})
+}
+
 // Get some statistics for each individual client of all VPNs
 // This can result in heavy system load for lots of clients
-func (semp *Semp) GetClientStatsSemp1(ch chan<- PrometheusMetric, itemFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetClientStatsSemp1(ctx context.Context, ch chan<- PrometheusMetric, itemFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -53,7 +79,10 @@ func (semp *Semp) GetClientStatsSemp1(ch chan<- PrometheusMetric, itemFilter str
 	var page = 1
 	var lastClientName = ""
 	for command := fmt.Sprintf("<rpc><show><client><name>" + itemFilter + "</name><stats/><count/><num-elements>%d</num-elements></client></show></rpc>", sempPageSize); command != ""; {
-		body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ClientStatsSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ClientStatsSemp1", page)
 		page++
 
 		if err != nil {
@@ -65,6 +94,7 @@ func (semp *Semp) GetClientStatsSemp1(ch chan<- PrometheusMetric, itemFilter str
 		err = decoder.Decode(&target)
 		if err != nil {
 			semp.logger.Error("Can't decode ClientStatSemp1", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
 			_ = body.Close()
 			return 0, err
 		}
@@ -104,7 +134,7 @@ func (semp *Semp) GetClientStatsSemp1(ch chan<- PrometheusMetric, itemFilter str
 
 // Get some statistics for each individual client connections of all VPNs
 // This can result in heavy system load for lots of clients
-func (semp *Semp) GetClientConnectionStatsSemp1(ch chan<- PrometheusMetric, itemFilter string) (float64, error) {
+func (semp *Semp) GetClientConnectionStatsSemp1(ctx context.Context, ch chan<- PrometheusMetric, itemFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -154,7 +184,7 @@ func (semp *Semp) GetClientConnectionStatsSemp1(ch chan<- PrometheusMetric, item
 
 	command := "<rpc><show><client><name>" + itemFilter + "</name><connections/></client></show></rpc>"
 
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ClientConnectionStatsSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ClientConnectionStatsSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape GetClientConnectionStatsSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -164,6 +194,7 @@ func (semp *Semp) GetClientConnectionStatsSemp1(ch chan<- PrometheusMetric, item
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode GetClientConnectionStatsSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		_ = body.Close()
 		return 0, err
 	}
@@ -0,0 +1,146 @@
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:         "ClientStatsV2",
+		SempVersion:  2,
+		VpnFilter:    true,
+		ItemFilter:   true,
+		MetricFilter: true,
+		Performance:  "may harm broker if many clients, less than ClientStats",
+		Metrics:      []Descriptions{MetricDesc["ClientStatsV2"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetClientStatsSemp2(ctx, ch, query.VpnFilter, query.ItemFilter, query.MetricFilter, query.PageSize)
+		},
+	})
+}
+
+// GetClientStatsSemp2 Get statistics for each individual client of one VPN. itemFilter is a client name, or a SEMP v2
+// where condition on e.g. clientUsername or clientProfileName.
+// This can result in heavy system load for lots of clients
+func (semp *Semp) GetClientStatsSemp2(ctx context.Context, ch chan<- PrometheusMetric, vpnName string, itemFilter string, metricFilter []string, sempPageSize int64) (float64, error) {
+	type Response struct {
+		Client []struct {
+			ClientName          string  `json:"clientName"`
+			ClientUsername      string  `json:"clientUsername"`
+			MsgVpnName          string  `json:"msgVpnName"`
+			SlowSubscriber      bool    `json:"slowSubscriber"`
+			DataRxMsgCount      float64 `json:"dataRxMsgCount"`
+			DataTxMsgCount      float64 `json:"dataTxMsgCount"`
+			DataRxByteCount     float64 `json:"dataRxByteCount"`
+			DataTxByteCount     float64 `json:"dataTxByteCount"`
+			DiscardedRxMsgCount float64 `json:"discardedRxMsgCount"`
+			DiscardedTxMsgCount float64 `json:"discardedTxMsgCount"`
+		} `json:"data"`
+		Meta struct {
+			Count        int64 `json:"count"`
+			ResponseCode int   `json:"responseCode"`
+			Paging       struct {
+				CursorQuery string `json:"cursorQuery"`
+				NextPageURI string `json:"nextPageUri"`
+			} `json:"paging"`
+			Error struct {
+				Code        int    `json:"code"`
+				Description string `json:"description"`
+				Status      string `json:"status"`
+			} `json:"error"`
+		} `json:"meta"`
+	}
+
+	var getParameter = fmt.Sprintf("count=%d", sempPageSize)
+
+	if len(strings.TrimSpace(itemFilter)) > 0 && itemFilter != "*" {
+		if strings.Contains(itemFilter, "=") {
+			getParameter += "&where=" + queryEscape(itemFilter)
+		} else {
+			getParameter += "&where=" + queryEscape("clientName=="+itemFilter)
+		}
+	}
+
+	var fieldsToSelect []string
+	if len(metricFilter) > 0 {
+		var err error
+
+		fieldsToSelect, err = getSempV2FieldsToSelect(
+			metricFilter,
+			[]string{"clientName", "clientUsername", "msgVpnName"},
+			ClientStats,
+		)
+
+		if err != nil {
+			semp.logger.Error("Unable to map metric filter", "err", err, "broker", semp.brokerURI)
+			return 0, err
+		}
+		getParameter += "&select=" + strings.Join(fieldsToSelect, ",")
+	}
+
+	var page = 1
+	var lastClientName = ""
+	for nextURL := semp.brokerURI + "/SEMP/v2/monitor/msgVpns/" + url.PathEscape(vpnName) + "/clients?" + getParameter; nextURL != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json", "ClientStatsSemp2", page)
+		page++
+
+		if err != nil {
+			semp.logger.Error("Can't scrape ClientStatsSemp2", "command", nextURL, "err", err, "broker", semp.brokerURI)
+			return -1, err
+		}
+
+		var response Response
+		err = json.Unmarshal(body, &response)
+		if err != nil {
+			semp.logger.Error("Can't decode ClientStatsSemp2", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
+			return 0, err
+		}
+		if response.Meta.ResponseCode != 200 {
+			semp.logger.Error("unexpected result", "command", nextURL, "remoteError", response.Meta.Error.Description, "broker", semp.brokerURI)
+			return 0, errors.New("unexpected result: see log")
+		}
+
+		semp.logger.Debug("Result of ClientStatsSemp2", "results", len(response.Client), "page", page-1)
+
+		nextURL = response.Meta.Paging.NextPageURI
+		for _, client := range response.Client {
+			clientKey := client.MsgVpnName + "___" + client.ClientName
+			if clientKey == lastClientName {
+				continue
+			}
+			lastClientName = clientKey
+
+			var values = []V2Result{
+				{v2Desc: ClientStats["client_rx_msgs_total"], valueType: prometheus.CounterValue, value: client.DataRxMsgCount},
+				{v2Desc: ClientStats["client_tx_msgs_total"], valueType: prometheus.CounterValue, value: client.DataTxMsgCount},
+				{v2Desc: ClientStats["client_rx_bytes_total"], valueType: prometheus.CounterValue, value: client.DataRxByteCount},
+				{v2Desc: ClientStats["client_tx_bytes_total"], valueType: prometheus.CounterValue, value: client.DataTxByteCount},
+				{v2Desc: ClientStats["client_rx_discarded_msgs_total"], valueType: prometheus.CounterValue, value: client.DiscardedRxMsgCount},
+				{v2Desc: ClientStats["client_tx_discarded_msgs_total"], valueType: prometheus.CounterValue, value: client.DiscardedTxMsgCount},
+			}
+
+			for _, v := range values {
+				if v.v2Desc.isSelected(fieldsToSelect) {
+					ch <- semp.NewMetric(v.v2Desc, v.valueType, v.value, client.MsgVpnName, client.ClientName, client.ClientUsername)
+				}
+			}
+			// Like ClientStats, without the client address of ClientSlowSubscriber.
+			if ClientStats["client_slow_subscriber"].isSelected(fieldsToSelect) {
+				ch <- semp.NewMetric(ClientStats["client_slow_subscriber"], prometheus.GaugeValue, encodeMetricBool(client.SlowSubscriber), client.MsgVpnName, client.ClientName, "", client.ClientUsername)
+			}
+		}
+	}
+
+	return 1, nil
+}
@@ -0,0 +1,40 @@
+package semp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetClientStatsSemp2SelectsFilteredFieldsOfEscapedVpn(t *testing.T) {
+	t.Parallel()
+
+	s, requests := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/prod%20a/clients": `{"data":[{"clientName":"c1","clientUsername":"app","msgVpnName":"prod a","dataRxMsgCount":7,"slowSubscriber":true}],` +
+			`"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 10)
+	up, err := s.GetClientStatsSemp2(t.Context(), ch, "prod a", "clientProfileName==default", []string{"solace_client_rx_msgs_total", "slowSubscriber"}, 100)
+	close(ch)
+	if up != 1 || err != nil {
+		t.Fatalf("GetClientStatsSemp2 = %v, %v", up, err)
+	}
+
+	wantRequests := []string{"/SEMP/v2/monitor/msgVpns/prod%20a/clients?count=100&where=clientProfileName%3D%3Ddefault&select=dataRxMsgCount,slowSubscriber,clientName,clientUsername,msgVpnName"}
+	if got := requests(); !slices.Equal(got, wantRequests) {
+		t.Errorf("requests = %q, want %q", got, wantRequests)
+	}
+
+	var names []string
+	for metric := range ch {
+		names = append(names, metric.Name())
+	}
+	slices.Sort(names)
+	want := []string{
+		`solace_client_rx_msgs_total{vpn_name="prod a",client_name="c1",client_username="app"}`,
+		`solace_client_slow_subscriber{vpn_name="prod a",client_name="c1",client_address="",client_username="app"}`,
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("metrics = %q, want %q", names, want)
+	}
+}
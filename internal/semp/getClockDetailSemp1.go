@@ -1,14 +1,30 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ClockDetail",
+		Aliases:     []string{"ClockDetailV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Platform:    PlatformHardware,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["ClockDetail"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetClockDetailSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetClockDetailSemp1 Clock details for Broker and Vpn
-func (semp *Semp) GetClockDetailSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetClockDetailSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -26,7 +42,7 @@ func (semp *Semp) GetClockDetailSemp1(ch chan<- PrometheusMetric) (float64, erro
 	}
 
 	command := "<rpc><show><clock><detail/></clock></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ClockDetailSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ClockDetailSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape ClockDetailSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -37,6 +53,7 @@ func (semp *Semp) GetClockDetailSemp1(ch chan<- PrometheusMetric) (float64, erro
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml ClockDetailSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
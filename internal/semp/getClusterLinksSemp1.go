@@ -1,14 +1,30 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ClusterLinks",
+		Aliases:     []string{"ClusterLinksV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["ClusterLinks"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetClusterLinksSemp1(ctx, ch, query.VpnFilter, query.ItemFilter)
+		},
+	})
+}
+
 // Cluster link states of broker
-func (semp *Semp) GetClusterLinksSemp1(ch chan<- PrometheusMetric, clusterFilter string, linkFilter string) (float64, error) {
+func (semp *Semp) GetClusterLinksSemp1(ctx context.Context, ch chan<- PrometheusMetric, clusterFilter string, linkFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -35,7 +51,7 @@ func (semp *Semp) GetClusterLinksSemp1(ch chan<- PrometheusMetric, clusterFilter
 	}
 
 	command := "<rpc><show><cluster><cluster-name-pattern>" + clusterFilter + "</cluster-name-pattern><link-name-pattern>" + linkFilter + "</link-name-pattern></cluster></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ClusterLinksSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ClusterLinksSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape ClusterLinksSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -46,6 +62,7 @@ func (semp *Semp) GetClusterLinksSemp1(ch chan<- PrometheusMetric, clusterFilter
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml ClusterLinksSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
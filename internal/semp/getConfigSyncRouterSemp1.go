@@ -1,14 +1,29 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ConfigSyncRouter",
+		Aliases:     []string{"ConfigSyncRouterV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Performance: "dont harm broker (only for HA broker)",
+		Metrics:     []Descriptions{MetricDesc["ConfigSyncRouter"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetConfigSyncRouterSemp1(ctx, ch)
+		},
+	})
+}
+
 // Config Sync Status for Broker and Vpn
-func (semp *Semp) GetConfigSyncRouterSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetConfigSyncRouterSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -34,7 +49,7 @@ func (semp *Semp) GetConfigSyncRouterSemp1(ch chan<- PrometheusMetric) (float64,
 	}
 
 	command := "<rpc><show><config-sync><database/><router/></config-sync></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ConfigSyncRouterSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ConfigSyncRouterSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape VpnSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -45,6 +60,7 @@ func (semp *Semp) GetConfigSyncRouterSemp1(ch chan<- PrometheusMetric) (float64,
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml ConfigSyncRouterSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -1,14 +1,29 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ConfigSync",
+		Aliases:     []string{"ConfigSyncV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Performance: "dont harm broker (only for HA broker)",
+		Metrics:     []Descriptions{MetricDesc["ConfigSync"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetConfigSyncSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetConfigSyncSemp1 Sync Status for Broker and Vpn
-func (semp *Semp) GetConfigSyncSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetConfigSyncSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -24,7 +39,7 @@ func (semp *Semp) GetConfigSyncSemp1(ch chan<- PrometheusMetric) (float64, error
 	}
 
 	command := "<rpc><show><config-sync></config-sync></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ConfigSyncSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ConfigSyncSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape VpnSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -35,6 +50,7 @@ func (semp *Semp) GetConfigSyncSemp1(ch chan<- PrometheusMetric) (float64, error
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml ConfigSyncSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
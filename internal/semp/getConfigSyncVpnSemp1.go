@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
     "fmt"
 	"solace_exporter/internal/semp/types"
@@ -8,8 +9,22 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ConfigSyncVpn",
+		Aliases:     []string{"ConfigSyncVpnV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		Performance: "dont harm broker (only for HA broker)",
+		Metrics:     []Descriptions{MetricDesc["ConfigSyncVpn"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetConfigSyncVpnSemp1(ctx, ch, query.VpnFilter, query.PageSize)
+		},
+	})
+}
+
 // GetConfigSyncVpnSemp1 Sync Status for Broker and Vpn
-func (semp *Semp) GetConfigSyncVpnSemp1(ch chan<- PrometheusMetric, vpnFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetConfigSyncVpnSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -38,7 +53,10 @@ func (semp *Semp) GetConfigSyncVpnSemp1(ch chan<- PrometheusMetric, vpnFilter st
     var page = 1
 	var lastTableName = ""
 	for command := fmt.Sprintf("<rpc><show><config-sync><database/><message-vpn/><vpn-name>" + vpnFilter + "</vpn-name><count/><num-elements>%d</num-elements></config-sync></show></rpc>", sempPageSize); command != ""; {
-        body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ConfigSyncVpnSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+        body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ConfigSyncVpnSemp1", page)
         page++
 
         if err != nil {
@@ -51,6 +69,7 @@ func (semp *Semp) GetConfigSyncVpnSemp1(ch chan<- PrometheusMetric, vpnFilter st
         err = decoder.Decode(&target)
         if err != nil {
             semp.logger.Error("Can't decode Xml ConfigSyncSemp1", "err", err, "broker", semp.brokerURI)
+            semp.observeDecodeError()
             _ = body.Close()
             return 0, err
         }
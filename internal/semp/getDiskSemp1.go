@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 	"strconv"
@@ -9,8 +10,23 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Disk",
+		Aliases:     []string{"DiskV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Platform:    PlatformHardware,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Disk"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetDiskSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetDiskSemp1 Get system disk information (for Appliance)
-func (semp *Semp) GetDiskSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetDiskSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -32,7 +48,7 @@ func (semp *Semp) GetDiskSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	}
 
 	command := "<rpc><show><disk><detail/></disk></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "DiskSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "DiskSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape DiskSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -43,6 +59,7 @@ func (semp *Semp) GetDiskSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml DiskSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
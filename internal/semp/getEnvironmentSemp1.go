@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"math"
 	"solace_exporter/internal/semp/types"
@@ -10,8 +11,23 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Environment",
+		Aliases:     []string{"EnvironmentV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Platform:    PlatformHardware,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Environment"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetEnvironmentSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetEnvironmentSemp1 Get system Alarm information
-func (semp *Semp) GetEnvironmentSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetEnvironmentSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -49,7 +65,7 @@ func (semp *Semp) GetEnvironmentSemp1(ch chan<- PrometheusMetric) (float64, erro
 	}
 
 	command := "<rpc><show><environment/></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "EnvironmentSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "EnvironmentSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape EnvironmentSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -60,6 +76,7 @@ func (semp *Semp) GetEnvironmentSemp1(ch chan<- PrometheusMetric) (float64, erro
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml EnvironmentSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -1,14 +1,38 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "GlobalStats",
+		Aliases:     []string{"GlobalStatsV1"},
+		SempVersion: 1,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["GlobalStats"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetGlobalStatsSemp1(ctx, ch)
+		},
+	})
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "GlobalSystemInfo",
+		Aliases:     []string{"GlobalSystemInfoV1"},
+		SempVersion: 1,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["GlobalStats"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetGlobalSystemInfoSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetGlobalSystemInfoSemp1 Get global stats information
-func (semp *Semp) GetGlobalSystemInfoSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetGlobalSystemInfoSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -25,7 +49,7 @@ func (semp *Semp) GetGlobalSystemInfoSemp1(ch chan<- PrometheusMetric) (float64,
 	}
 
 	command := "<rpc><show><system/></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "GetGlobalSystemInfoSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "GetGlobalSystemInfoSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape GetGlobalSystemInfoSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -36,6 +60,7 @@ func (semp *Semp) GetGlobalSystemInfoSemp1(ch chan<- PrometheusMetric) (float64,
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml GetGlobalSystemInfoSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -58,7 +83,7 @@ func (semp *Semp) GetGlobalSystemInfoSemp1(ch chan<- PrometheusMetric) (float64,
 	return 1, nil
 }
 
-func (semp *Semp) GetGlobalStatsSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetGlobalStatsSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -91,7 +116,7 @@ func (semp *Semp) GetGlobalStatsSemp1(ch chan<- PrometheusMetric) (float64, erro
 	}
 
 	command := "<rpc><show><stats><client/></stats></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "GlobalStatsSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "GlobalStatsSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape GlobalStatsSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -102,6 +127,7 @@ func (semp *Semp) GetGlobalStatsSemp1(ch chan<- PrometheusMetric) (float64, erro
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml GlobalStatsSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 	"strings"
@@ -8,8 +9,23 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Hardware",
+		Aliases:     []string{"HardwareV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Platform:    PlatformHardware,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Hardware"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetHardwareSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetHardwareSemp1 Get system Alarm information
-func (semp *Semp) GetHardwareSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetHardwareSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -44,7 +60,7 @@ func (semp *Semp) GetHardwareSemp1(ch chan<- PrometheusMetric) (float64, error)
 	}
 
 	command := "<rpc><show><hardware><details/></hardware></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "HardwareSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "HardwareSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape HardwareSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -55,6 +71,7 @@ func (semp *Semp) GetHardwareSemp1(ch chan<- PrometheusMetric) (float64, error)
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml HardwareSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -1,14 +1,30 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Health",
+		Aliases:     []string{"HealthV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Platform:    PlatformSoftware,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Health"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetHealthSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetHealthSemp1 Get system health information
-func (semp *Semp) GetHealthSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetHealthSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -34,7 +50,7 @@ func (semp *Semp) GetHealthSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	}
 
 	command := "<rpc><show><system><health/></system></show ></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "HealthSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "HealthSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape HealthSemp1. Attention this is only supported by software broker not by appliances", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -45,6 +61,7 @@ func (semp *Semp) GetHealthSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml HealthSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -1,14 +1,31 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "InterfaceHW",
+		Aliases:     []string{"InterfaceHWV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Platform:    PlatformHardware,
+		ItemFilter:  true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["InterfaceHW"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetInterfaceHWSemp1(ctx, ch, query.ItemFilter)
+		},
+	})
+}
+
 // GetInterfaceHWSemp1 Get interface information
-func (semp *Semp) GetInterfaceHWSemp1(ch chan<- PrometheusMetric, interfaceFilter string) (float64, error) {
+func (semp *Semp) GetInterfaceHWSemp1(ctx context.Context, ch chan<- PrometheusMetric, interfaceFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -56,7 +73,7 @@ func (semp *Semp) GetInterfaceHWSemp1(ch chan<- PrometheusMetric, interfaceFilte
 	}
 	command += "</interface></show></rpc>"
 
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "InterfaceHWSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "InterfaceHWSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape InterfaceHWSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -67,6 +84,7 @@ func (semp *Semp) GetInterfaceHWSemp1(ch chan<- PrometheusMetric, interfaceFilte
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml InterfaceHWSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
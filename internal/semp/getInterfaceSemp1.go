@@ -1,14 +1,30 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Interface",
+		Aliases:     []string{"InterfaceV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		ItemFilter:  true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Interface"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetInterfaceSemp1(ctx, ch, query.ItemFilter)
+		},
+	})
+}
+
 // GetInterfaceSemp1 Get interface information
-func (semp *Semp) GetInterfaceSemp1(ch chan<- PrometheusMetric, interfaceFilter string) (float64, error) {
+func (semp *Semp) GetInterfaceSemp1(ctx context.Context, ch chan<- PrometheusMetric, interfaceFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -31,7 +47,7 @@ func (semp *Semp) GetInterfaceSemp1(ch chan<- PrometheusMetric, interfaceFilter
 	}
 
 	command := "<rpc><show><interface><phy-interface>" + interfaceFilter + "</phy-interface></interface></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "InterfaceSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "InterfaceSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape InterfaceSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -42,6 +58,7 @@ func (semp *Semp) GetInterfaceSemp1(ch chan<- PrometheusMetric, interfaceFilter
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml InterfaceSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
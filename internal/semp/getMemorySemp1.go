@@ -1,14 +1,29 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Memory",
+		Aliases:     []string{"MemoryV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Memory"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetMemorySemp1(ctx, ch)
+		},
+	})
+}
+
 // GetMemorySemp1 Get system memory information
-func (semp *Semp) GetMemorySemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetMemorySemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -38,7 +53,7 @@ func (semp *Semp) GetMemorySemp1(ch chan<- PrometheusMetric) (float64, error) {
 	}
 
 	command := "<rpc><show><memory/></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "MemorySemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "MemorySemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape MemorySemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -49,6 +64,7 @@ func (semp *Semp) GetMemorySemp1(ch chan<- PrometheusMetric) (float64, error) {
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml MemorySemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -28,7 +28,7 @@ func newMemoryTestSemp(t *testing.T, reply string) *Semp {
 	}))
 	t.Cleanup(server.Close)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	return NewSemp(logger, server.URL, http.Client{}, nil, false, false)
+	return NewSemp(logger, server.URL, http.Client{}, nil, false, false, nil, RetryPolicy{}, nil)
 }
 
 func drain(ch chan PrometheusMetric) []PrometheusMetric {
@@ -47,7 +47,7 @@ func TestGetMemorySemp1EmptySlotInfoNoPanic(t *testing.T) {
 	s := newMemoryTestSemp(t, memoryReply(`<slot-infos></slot-infos>`))
 
 	ch := make(chan PrometheusMetric, 100)
-	up, err := s.GetMemorySemp1(ch) // must not panic
+	up, err := s.GetMemorySemp1(t.Context(), ch) // must not panic
 	metrics := drain(ch)
 
 	if err != nil {
@@ -72,7 +72,7 @@ func TestGetMemorySemp1WithSlotInfo(t *testing.T) {
 	s := newMemoryTestSemp(t, memoryReply(`<slot-infos><slot-info><slot>1</slot><nab-buffer-load-factor>0.5</nab-buffer-load-factor></slot-info></slot-infos>`))
 
 	ch := make(chan PrometheusMetric, 100)
-	up, err := s.GetMemorySemp1(ch)
+	up, err := s.GetMemorySemp1(t.Context(), ch)
 	metrics := drain(ch)
 
 	if err != nil {
@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
     "fmt"
 	"solace_exporter/internal/semp/types"
@@ -8,7 +9,21 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-func (semp *Semp) GetMqttSessionSemp1(ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "MqttSession",
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "may harm broker if many mqtt sessions",
+		Metrics:     []Descriptions{MetricDesc["MqttSession"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetMqttSessionSemp1(ctx, ch, query.VpnFilter, query.ItemFilter, query.PageSize)
+		},
+	})
+}
+
+func (semp *Semp) GetMqttSessionSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -43,7 +58,10 @@ func (semp *Semp) GetMqttSessionSemp1(ch chan<- PrometheusMetric, vpnFilter stri
 	var page = 1
 
 	for command := fmt.Sprintf("<rpc><show><message-vpn><vpn-name>" + vpnFilter + "</vpn-name><mqtt/><mqtt-session/><client-id-pattern>" + itemFilter + "</client-id-pattern><count/><num-elements>%d</num-elements></message-vpn></show></rpc>", sempPageSize); command != ""; {
-		body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "MqttSessionSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "MqttSessionSemp1", page)
 		page++
 
 		if err != nil {
@@ -56,6 +74,7 @@ func (semp *Semp) GetMqttSessionSemp1(ch chan<- PrometheusMetric, vpnFilter stri
 		err = decoder.Decode(&target)
 		if err != nil {
 			semp.logger.Error("Can't decode MqttSessionSemp1", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
 			_ = body.Close()
 			return 0, err
 		}
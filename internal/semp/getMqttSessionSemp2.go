@@ -0,0 +1,219 @@
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"solace_exporter/internal/semp/types"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:         "MqttSessionV2",
+		SempVersion:  2,
+		VpnFilter:    true,
+		ItemFilter:   true,
+		MetricFilter: true,
+		Performance:  "may harm broker if many mqtt sessions, the subscription and queued message counts need one more request per session each",
+		Metrics:      []Descriptions{MetricDesc["MqttSessionV2"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetMqttSessionSemp2(ctx, ch, query.VpnFilter, query.ItemFilter, query.MetricFilter, query.PageSize)
+		},
+	})
+}
+
+// GetMqttSessionSemp2 Get the MQTT sessions of one VPN. itemFilter is a client id, or a SEMP v2 where condition. The
+// subscriptions of a session are counted from its subscriptions collection, and the messages queued for it are read
+// from SEMP v1, only if metricFilter names mqtt_session_subscriptions or mqtt_session_queued_msgs.
+// This can result in heavy system load for lots of mqtt sessions
+func (semp *Semp) GetMqttSessionSemp2(ctx context.Context, ch chan<- PrometheusMetric, vpnName string, itemFilter string, metricFilter []string, sempPageSize int64) (float64, error) {
+	type Response struct {
+		MqttSession []struct {
+			ClientID      string `json:"mqttSessionClientId"`
+			VirtualRouter string `json:"mqttSessionVirtualRouter"`
+			MsgVpnName    string `json:"msgVpnName"`
+			Owner         string `json:"owner"`
+			Enabled       bool   `json:"enabled"`
+			Clean         bool   `json:"clean"`
+			Durable       bool   `json:"durable"`
+			QueueName     string `json:"queueName"`
+		} `json:"data"`
+		Meta struct {
+			Count        int64 `json:"count"`
+			ResponseCode int   `json:"responseCode"`
+			Paging       struct {
+				CursorQuery string `json:"cursorQuery"`
+				NextPageURI string `json:"nextPageUri"`
+			} `json:"paging"`
+			Error struct {
+				Code        int    `json:"code"`
+				Description string `json:"description"`
+				Status      string `json:"status"`
+			} `json:"error"`
+		} `json:"meta"`
+	}
+
+	var getParameter = fmt.Sprintf("count=%d", sempPageSize)
+
+	if len(strings.TrimSpace(itemFilter)) > 0 && itemFilter != "*" {
+		if strings.Contains(itemFilter, "=") {
+			getParameter += "&where=" + queryEscape(itemFilter)
+		} else {
+			getParameter += "&where=" + queryEscape("mqttSessionClientId=="+itemFilter)
+		}
+	}
+
+	var fieldsToSelect []string
+	var counts map[string][]string
+	if len(metricFilter) > 0 {
+		var err error
+
+		fieldsToSelect, err = getSempV2FieldsToSelect(
+			metricFilter,
+			[]string{"mqttSessionClientId", "mqttSessionVirtualRouter", "msgVpnName", "owner"},
+			MqttSessionV2,
+		)
+
+		if err != nil {
+			semp.logger.Error("Unable to map metric filter", "err", err, "broker", semp.brokerURI)
+			return 0, err
+		}
+		var sessionFields []string
+		sessionFields, counts = selectSubCollections(fieldsToSelect, "subscriptions", "queueMsgs")
+		if MqttSessionV2["mqtt_session_info"].isSelected(fieldsToSelect) {
+			sessionFields = append(sessionFields, "clean", "durable")
+		}
+		if _, ok := counts["queueMsgs"]; ok {
+			sessionFields = append(sessionFields, "queueName")
+		}
+		getParameter += "&select=" + strings.Join(sessionFields, ",")
+	}
+	_, subscriptions := counts["subscriptions"]
+	_, queuedMsgs := counts["queueMsgs"]
+
+	var page = 1
+	var lastSessionKey = ""
+	var vpnURI = semp.brokerURI + "/SEMP/v2/monitor/msgVpns/" + url.PathEscape(vpnName)
+	for nextURL := vpnURI + "/mqttSessions?" + getParameter; nextURL != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json", "MqttSessionSemp2", page)
+		page++
+
+		if err != nil {
+			semp.logger.Error("Can't scrape MqttSessionSemp2", "command", nextURL, "err", err, "broker", semp.brokerURI)
+			return -1, err
+		}
+
+		var response Response
+		err = json.Unmarshal(body, &response)
+		if err != nil {
+			semp.logger.Error("Can't decode MqttSessionSemp2", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
+			return 0, err
+		}
+		if response.Meta.ResponseCode != 200 {
+			semp.logger.Error("unexpected result", "command", nextURL, "remoteError", response.Meta.Error.Description, "broker", semp.brokerURI)
+			return 0, errors.New("unexpected result: see log")
+		}
+
+		semp.logger.Debug("Result of MqttSessionSemp2", "results", len(response.MqttSession), "page", page-1)
+
+		nextURL = response.Meta.Paging.NextPageURI
+		for _, session := range response.MqttSession {
+			// Like MqttSession, a session of the primary and the backup virtual router is reported once.
+			sessionKey := session.MsgVpnName + "___" + session.ClientID
+			if sessionKey == lastSessionKey {
+				continue
+			}
+			lastSessionKey = sessionKey
+
+			if MqttSessionV2["mqtt_session_info"].isSelected(fieldsToSelect) {
+				ch <- semp.NewMetric(MqttSessionV2["mqtt_session_info"], prometheus.GaugeValue, 1.0, session.MsgVpnName, session.ClientID, session.Owner, strconv.FormatBool(session.Clean), strconv.FormatBool(session.Durable), strconv.FormatBool(session.Enabled))
+			}
+
+			if subscriptions {
+				sessionURI := vpnURI + "/mqttSessions/" + url.PathEscape(session.ClientID) + "," + url.PathEscape(session.VirtualRouter)
+				count, err := semp.countSempV2Collection(ctx, sessionURI+fmt.Sprintf("/subscriptions?count=%d&select=subscriptionTopic", sempPageSize), "MqttSessionSubscriptionsSemp2")
+				if err != nil {
+					return subCollectionUp(err), err
+				}
+				ch <- semp.NewMetric(MqttSessionV2["mqtt_session_subscriptions"], prometheus.GaugeValue, count, session.MsgVpnName, session.ClientID, session.Owner)
+			}
+
+			// A session without QoS 1 subscriptions has no queue, so nothing is queued for it.
+			if queuedMsgs {
+				var count float64
+				if session.QueueName != "" {
+					count, err = semp.getSpooledMsgsSemp1(ctx, session.MsgVpnName, session.QueueName)
+					if err != nil {
+						return subCollectionUp(err), err
+					}
+				}
+				ch <- semp.NewMetric(MqttSessionV2["mqtt_session_queued_msgs"], prometheus.GaugeValue, count, session.MsgVpnName, session.ClientID, session.Owner)
+			}
+		}
+	}
+
+	return 1, nil
+}
+
+// getSpooledMsgsSemp1 returns the number of messages spooled on a queue from SEMP v1. Unlike paging the msgs collection
+// of the queue in SEMP v2, this is one request however many messages are queued. Errors are those of
+// countSempV2Collection.
+func (semp *Semp) getSpooledMsgsSemp1(ctx context.Context, vpnName string, queueName string) (float64, error) {
+	type Data struct {
+		RPC struct {
+			Show struct {
+				Queue struct {
+					Queues struct {
+						Queue []struct {
+							Info struct {
+								SpooledMsgCount float64 `xml:"num-messages-spooled"`
+							} `xml:"info"`
+						} `xml:"queue"`
+					} `xml:"queues"`
+				} `xml:"queue"`
+			} `xml:"show"`
+		} `xml:"rpc"`
+		ExecuteResult types.ExecuteResult `xml:"execute-result"`
+	}
+
+	command := "<rpc><show><queue><name>" + xmlEscape(queueName) + "</name><vpn-name>" + xmlEscape(vpnName) + "</vpn-name><detail/></queue></show></rpc>"
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "MqttSessionQueuedMsgsSemp1", 1)
+	if err != nil {
+		semp.logger.Error("Can't scrape MqttSessionQueuedMsgsSemp1", "err", err, "broker", semp.brokerURI)
+		return 0, err
+	}
+	defer body.Close()
+
+	var target Data
+	if err := xml.NewDecoder(body).Decode(&target); err != nil {
+		semp.logger.Error("Can't decode MqttSessionQueuedMsgsSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
+		return 0, errors.Join(errUnexpectedSubCollection, err)
+	}
+	if err := target.ExecuteResult.OK(); err != nil {
+		semp.logger.Error("unexpected result",
+			"command", command,
+			"result", target.ExecuteResult.Result,
+			"reason", target.ExecuteResult.Reason,
+			"broker", semp.brokerURI,
+		)
+		return 0, errors.Join(errUnexpectedSubCollection, err)
+	}
+
+	var count float64
+	for _, queue := range target.RPC.Show.Queue.Queues.Queue {
+		count += queue.Info.SpooledMsgCount
+	}
+	return count, nil
+}
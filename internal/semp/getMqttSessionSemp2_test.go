@@ -0,0 +1,104 @@
+package semp
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestGetMqttSessionSemp2CountsSubscriptionsAndQueuedMsgs(t *testing.T) {
+	t.Parallel()
+
+	s, requestsFunc := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/default/mqttSessions": `{"data":[` +
+			`{"mqttSessionClientId":"c1","mqttSessionVirtualRouter":"primary","msgVpnName":"default","owner":"app","enabled":true,"durable":true,"queueName":"#mqtt/c1/1"},` +
+			`{"mqttSessionClientId":"c1","mqttSessionVirtualRouter":"backup","msgVpnName":"default","owner":"app"},` +
+			`{"mqttSessionClientId":"c2","mqttSessionVirtualRouter":"primary","msgVpnName":"default","owner":"app","clean":true}` +
+			`],"meta":{"responseCode":200}}`,
+		"/SEMP/v2/monitor/msgVpns/default/mqttSessions/c1,primary/subscriptions": `{"data":[{"subscriptionTopic":"a"},{"subscriptionTopic":"b"}],"meta":{"responseCode":200}}`,
+		"/SEMP/v2/monitor/msgVpns/default/mqttSessions/c2,primary/subscriptions": `{"data":[],"meta":{"responseCode":200}}`,
+		"/SEMP": `<rpc-reply><rpc><show><queue><queues><queue><name>#mqtt/c1/1</name><info>` +
+			`<num-messages-spooled>3</num-messages-spooled></info></queue></queues></queue></show></rpc>` +
+			`<execute-result code="ok"/></rpc-reply>`,
+	})
+
+	ch := make(chan PrometheusMetric, 10)
+	up, err := s.GetMqttSessionSemp2(t.Context(), ch, "default", "*", []string{"solace_mqtt_session_info", "solace_mqtt_session_subscriptions", "solace_mqtt_session_queued_msgs"}, 100)
+	close(ch)
+	requests := requestsFunc()
+	if up != 1 || err != nil {
+		t.Fatalf("GetMqttSessionSemp2 = %v, %v (requests %q)", up, err, requests)
+	}
+
+	if want := "/SEMP/v2/monitor/msgVpns/default/mqttSessions?count=100&select=enabled,mqttSessionClientId,mqttSessionVirtualRouter,msgVpnName,owner,clean,durable,queueName"; requests[0] != want {
+		t.Errorf("request = %s, want %s", requests[0], want)
+	}
+	if want := "/SEMP <rpc><show><queue><name>#mqtt/c1/1</name><vpn-name>default</vpn-name><detail/></queue></show></rpc>"; !slices.Contains(requests, want) {
+		t.Errorf("got requests %q, want %s", requests, want)
+	}
+	if len(requests) != 4 {
+		t.Errorf("got requests %q, want the sessions, two subscriptions and the queue of one", requests)
+	}
+
+	var names []string
+	for metric := range ch {
+		names = append(names, metric.Name()+" "+strconv.FormatFloat(metric.value, 'g', -1, 64))
+	}
+	slices.Sort(names)
+	want := []string{
+		`solace_mqtt_session_info{vpn_name="default",client_id="c1",owner="app",clean="false",durable="true",enabled="true"} 1`,
+		`solace_mqtt_session_info{vpn_name="default",client_id="c2",owner="app",clean="true",durable="false",enabled="false"} 1`,
+		`solace_mqtt_session_queued_msgs{vpn_name="default",client_id="c1",owner="app"} 3`,
+		`solace_mqtt_session_queued_msgs{vpn_name="default",client_id="c2",owner="app"} 0`,
+		`solace_mqtt_session_subscriptions{vpn_name="default",client_id="c1",owner="app"} 2`,
+		`solace_mqtt_session_subscriptions{vpn_name="default",client_id="c2",owner="app"} 0`,
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("metrics = %q, want %q", names, want)
+	}
+}
+
+func TestGetMqttSessionSemp2SkipsCountsNotInMetricFilter(t *testing.T) {
+	t.Parallel()
+
+	s, requests := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/default/mqttSessions": `{"data":[{"mqttSessionClientId":"c1","msgVpnName":"default","owner":"app"}],"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 10)
+	up, err := s.GetMqttSessionSemp2(t.Context(), ch, "default", "owner==app", []string{"solace_mqtt_session_info"}, 100)
+	close(ch)
+	if up != 1 || err != nil {
+		t.Fatalf("GetMqttSessionSemp2 = %v, %v", up, err)
+	}
+
+	want := []string{"/SEMP/v2/monitor/msgVpns/default/mqttSessions?count=100&where=owner%3D%3Dapp&select=enabled,mqttSessionClientId,mqttSessionVirtualRouter,msgVpnName,owner,clean,durable"}
+	if got := requests(); !slices.Equal(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+	if len(ch) != 1 {
+		t.Errorf("got %d metrics, want only the info", len(ch))
+	}
+}
+
+func TestGetMqttSessionSemp2RequestsOnlySessionsWithoutMetricFilter(t *testing.T) {
+	t.Parallel()
+
+	s, requests := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/default/mqttSessions": `{"data":[{"mqttSessionClientId":"c1","mqttSessionVirtualRouter":"primary","msgVpnName":"default","owner":"app"}],"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 10)
+	up, err := s.GetMqttSessionSemp2(t.Context(), ch, "default", "*", nil, 100)
+	close(ch)
+	if up != 1 || err != nil {
+		t.Fatalf("GetMqttSessionSemp2 = %v, %v", up, err)
+	}
+
+	if want, got := []string{"/SEMP/v2/monitor/msgVpns/default/mqttSessions?count=100"}, requests(); !slices.Equal(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+	if len(ch) != 1 {
+		t.Errorf("got %d metrics, want only the info", len(ch))
+	}
+}
@@ -0,0 +1,180 @@
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:         "QueueDetailsV2",
+		SempVersion:  2,
+		VpnFilter:    true,
+		ItemFilter:   true,
+		MetricFilter: true,
+		Performance:  "may harm broker if many queues, the flow counts need one more request per queue each",
+		Metrics:      []Descriptions{MetricDesc["QueueDetailsV2"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetQueueDetailsSemp2(ctx, ch, query.VpnFilter, query.ItemFilter, query.MetricFilter, query.PageSize)
+		},
+	})
+}
+
+// GetQueueDetailsSemp2 Get state and configuration of each individual queue of one VPN. The number of its flows is
+// counted from its txFlows and rxFlows collections only if metricFilter names queue_tx_flows or queue_rx_flows.
+// This can result in heavy system load for lots of queues
+func (semp *Semp) GetQueueDetailsSemp2(ctx context.Context, ch chan<- PrometheusMetric, vpnName string, itemFilter string, metricFilter []string, sempPageSize int64) (float64, error) {
+	type Response struct {
+		Queue []struct {
+			QueueName                     string  `json:"queueName"`
+			MsgVpnName                    string  `json:"msgVpnName"`
+			AccessType                    string  `json:"accessType"`
+			IngressEnabled                bool    `json:"ingressEnabled"`
+			EgressEnabled                 bool    `json:"egressEnabled"`
+			Owner                         string  `json:"owner"`
+			MaxMsgSpoolUsage              float64 `json:"maxMsgSpoolUsage"`
+			MsgSpoolUsage                 float64 `json:"msgSpoolUsage"`
+			BindCount                     float64 `json:"bindCount"`
+			MaxBindCount                  float64 `json:"maxBindCount"`
+			PartitionCount                float64 `json:"partitionCount"`
+			ConsumerAckPropagationEnabled bool    `json:"consumerAckPropagationEnabled"`
+			RedeliveryEnabled             bool    `json:"redeliveryEnabled"`
+		} `json:"data"`
+		Meta struct {
+			Count        int64 `json:"count"`
+			ResponseCode int   `json:"responseCode"`
+			Paging       struct {
+				CursorQuery string `json:"cursorQuery"`
+				NextPageURI string `json:"nextPageUri"`
+			} `json:"paging"`
+			Error struct {
+				Code        int    `json:"code"`
+				Description string `json:"description"`
+				Status      string `json:"status"`
+			} `json:"error"`
+		} `json:"meta"`
+	}
+
+	var getParameter = fmt.Sprintf("count=%d", sempPageSize)
+
+	if len(strings.TrimSpace(itemFilter)) > 0 && itemFilter != "*" {
+		if strings.Contains(itemFilter, "=") {
+			getParameter += "&where=" + queryEscape(itemFilter)
+		} else {
+			getParameter += "&where=" + queryEscape("queueName=="+itemFilter)
+		}
+	}
+
+	var fieldsToSelect []string
+	var flows map[string][]string
+	if len(metricFilter) > 0 {
+		var err error
+
+		fieldsToSelect, err = getSempV2FieldsToSelect(
+			metricFilter,
+			[]string{"queueName", "msgVpnName"},
+			QueueDetailsV2,
+		)
+
+		if err != nil {
+			semp.logger.Error("Unable to map metric filter", "err", err, "broker", semp.brokerURI)
+			return 0, err
+		}
+		var queueFields []string
+		queueFields, flows = selectSubCollections(fieldsToSelect, "txFlows", "rxFlows")
+		getParameter += "&select=" + strings.Join(queueFields, ",")
+	}
+	_, txFlows := flows["txFlows"]
+	_, rxFlows := flows["rxFlows"]
+
+	var page = 1
+	var lastQueueName = ""
+	var queuesURI = semp.brokerURI + "/SEMP/v2/monitor/msgVpns/" + url.PathEscape(vpnName) + "/queues"
+	for nextURL := queuesURI + "?" + getParameter; nextURL != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json", "QueueDetailsSemp2", page)
+		page++
+
+		if err != nil {
+			semp.logger.Error("Can't scrape QueueDetailsSemp2", "command", nextURL, "err", err, "broker", semp.brokerURI)
+			return -1, err
+		}
+
+		var response Response
+		err = json.Unmarshal(body, &response)
+		if err != nil {
+			semp.logger.Error("Can't decode QueueDetailsSemp2", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
+			return 0, err
+		}
+		if response.Meta.ResponseCode != 200 {
+			semp.logger.Error("unexpected result", "command", nextURL, "remoteError", response.Meta.Error.Description, "broker", semp.brokerURI)
+			return 0, errors.New("unexpected result: see log")
+		}
+
+		semp.logger.Debug("Result of QueueDetailsSemp2", "results", len(response.Queue), "page", page-1)
+
+		nextURL = response.Meta.Paging.NextPageURI
+		for _, queue := range response.Queue {
+			queueKey := queue.MsgVpnName + "___" + queue.QueueName
+			if queueKey == lastQueueName {
+				continue
+			}
+			lastQueueName = queueKey
+
+			var values = []V2Result{
+				// maxMsgSpoolUsage is in MB, like the quota of QueueDetails. The usage keeps the value type of QueueDetails.
+				{v2Desc: QueueDetailsV2["queue_spool_quota_bytes"], valueType: prometheus.GaugeValue, value: math.Round(queue.MaxMsgSpoolUsage * 1048576.0)},
+				{v2Desc: QueueDetailsV2["queue_spool_usage_bytes"], valueType: prometheus.CounterValue, value: queue.MsgSpoolUsage},
+				{v2Desc: QueueDetailsV2["queue_binds"], valueType: prometheus.GaugeValue, value: queue.BindCount},
+				{v2Desc: QueueDetailsV2["queue_binds_max"], valueType: prometheus.GaugeValue, value: queue.MaxBindCount},
+				{v2Desc: QueueDetailsV2["queue_access_type"], valueType: prometheus.GaugeValue, value: encodeMetricMulti(queue.AccessType, []string{"exclusive", "non-exclusive"})},
+				{v2Desc: QueueDetailsV2["queue_ingress_enabled"], valueType: prometheus.GaugeValue, value: encodeMetricBool(queue.IngressEnabled)},
+				{v2Desc: QueueDetailsV2["queue_egress_enabled"], valueType: prometheus.GaugeValue, value: encodeMetricBool(queue.EgressEnabled)},
+				{v2Desc: QueueDetailsV2["queue_partitions"], valueType: prometheus.GaugeValue, value: queue.PartitionCount},
+				{v2Desc: QueueDetailsV2["queue_consumer_ack_propagation_enabled"], valueType: prometheus.GaugeValue, value: encodeMetricBool(queue.ConsumerAckPropagationEnabled)},
+				{v2Desc: QueueDetailsV2["queue_redelivery_enabled"], valueType: prometheus.GaugeValue, value: encodeMetricBool(queue.RedeliveryEnabled)},
+			}
+
+			for _, v := range values {
+				if v.v2Desc.isSelected(fieldsToSelect) {
+					ch <- semp.NewMetric(v.v2Desc, v.valueType, v.value, queue.MsgVpnName, queue.QueueName)
+				}
+			}
+			if QueueDetailsV2["queue_owner_info"].isSelected(fieldsToSelect) {
+				ch <- semp.NewMetric(QueueDetailsV2["queue_owner_info"], prometheus.GaugeValue, 1, queue.MsgVpnName, queue.QueueName, queue.Owner)
+			}
+
+			queueURI := queuesURI + "/" + url.PathEscape(queue.QueueName)
+			for _, flows := range []struct {
+				selected bool
+				desc     *Desc
+				uri      string
+				logName  string
+			}{
+				{selected: txFlows, desc: QueueDetailsV2["queue_tx_flows"], uri: queueURI + "/txFlows", logName: "QueueTxFlowsSemp2"},
+				{selected: rxFlows, desc: QueueDetailsV2["queue_rx_flows"], uri: queueURI + "/rxFlows", logName: "QueueRxFlowsSemp2"},
+			} {
+				if !flows.selected {
+					continue
+				}
+				count, err := semp.countSempV2Collection(ctx, flows.uri+fmt.Sprintf("?count=%d&select=flowId", sempPageSize), flows.logName)
+				if err != nil {
+					return subCollectionUp(err), err
+				}
+				ch <- semp.NewMetric(flows.desc, prometheus.GaugeValue, count, queue.MsgVpnName, queue.QueueName)
+			}
+		}
+	}
+
+	return 1, nil
+}
@@ -0,0 +1,86 @@
+package semp
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGetQueueDetailsSemp2CountsPagedFlowsOfEscapedQueue(t *testing.T) {
+	t.Parallel()
+
+	s, requestsFunc := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/default/queues": `{"data":[{"queueName":"q/1","msgVpnName":"default","owner":"app","bindCount":2}],"meta":{"responseCode":200}}`,
+		"/SEMP/v2/monitor/msgVpns/default/queues/q%2F1/txFlows": `{"data":[{"flowId":1}],"meta":{"responseCode":200,"paging":{"nextPageUri":` +
+			`"{server}/SEMP/v2/monitor/msgVpns/default/queues/q%2F1/txFlows?cursor=2"}}}`,
+		"/SEMP/v2/monitor/msgVpns/default/queues/q%2F1/txFlows?cursor=2": `{"data":[{"flowId":2}],"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 10)
+	up, err := s.GetQueueDetailsSemp2(t.Context(), ch, "default", "*", []string{"bindCount", "solace_queue_owner_info", "solace_queue_tx_flows"}, 100)
+	close(ch)
+	requests := requestsFunc()
+	if up != 1 || err != nil {
+		t.Fatalf("GetQueueDetailsSemp2 = %v, %v (requests %q)", up, err, requests)
+	}
+
+	if want := "/SEMP/v2/monitor/msgVpns/default/queues?count=100&select=bindCount,owner,queueName,msgVpnName"; requests[0] != want {
+		t.Errorf("request = %s, want %s", requests[0], want)
+	}
+	if len(requests) != 3 || !strings.HasPrefix(requests[1], "/SEMP/v2/monitor/msgVpns/default/queues/q%2F1/txFlows?") {
+		t.Errorf("got requests %q, want the queues and two pages of the escaped txFlows", requests)
+	}
+
+	var names []string
+	for metric := range ch {
+		names = append(names, metric.Name()+" "+strconv.FormatFloat(metric.value, 'g', -1, 64))
+	}
+	slices.Sort(names)
+	want := []string{
+		`solace_queue_binds{vpn_name="default",queue_name="q/1"} 2`,
+		`solace_queue_owner_info{vpn_name="default",queue_name="q/1",owner="app"} 1`,
+		`solace_queue_tx_flows{vpn_name="default",queue_name="q/1"} 2`,
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("metrics = %q, want %q", names, want)
+	}
+}
+
+func TestGetQueueDetailsSemp2RequestsOnlyQueuesWithoutMetricFilter(t *testing.T) {
+	t.Parallel()
+
+	s, requests := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/default/queues": `{"data":[{"queueName":"q1","msgVpnName":"default"},{"queueName":"q2","msgVpnName":"default"}],"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 100)
+	up, err := s.GetQueueDetailsSemp2(t.Context(), ch, "default", "*", nil, 100)
+	close(ch)
+	if up != 1 || err != nil {
+		t.Fatalf("GetQueueDetailsSemp2 = %v, %v", up, err)
+	}
+	if want, got := []string{"/SEMP/v2/monitor/msgVpns/default/queues?count=100"}, requests(); !slices.Equal(got, want) {
+		t.Errorf("got requests %q, want %q", got, want)
+	}
+	for metric := range ch {
+		if strings.Contains(metric.Name(), "_flows{") {
+			t.Errorf("unexpected %s", metric.Name())
+		}
+	}
+}
+
+func TestGetQueueDetailsSemp2FailsOnUnavailableFlows(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/default/queues": `{"data":[{"queueName":"q1","msgVpnName":"default"}],"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 10)
+	up, err := s.GetQueueDetailsSemp2(t.Context(), ch, "default", "*", []string{"solace_queue_rx_flows"}, 100)
+	close(ch)
+	if up != -1 || err == nil {
+		t.Errorf("GetQueueDetailsSemp2 = %v, %v, want -1 and the error of the flows request", up, err)
+	}
+}
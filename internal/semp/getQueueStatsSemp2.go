@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"strings"
@@ -8,9 +9,24 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:         "QueueStatsV2",
+		SempVersion:  2,
+		VpnFilter:    true,
+		ItemFilter:   true,
+		MetricFilter: true,
+		Performance:  "may harm broker if many queues",
+		Metrics:      []Descriptions{MetricDesc["QueueStatsV2"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetQueueStatsSemp2(ctx, ch, query.VpnFilter, query.ItemFilter, query.MetricFilter)
+		},
+	})
+}
+
 // GetQueueStatsSemp2 Get rates for each individual queue of all VPNs
 // This can result in heavy system load for lots of queues
-func (semp *Semp) GetQueueStatsSemp2(ch chan<- PrometheusMetric, vpnName string, itemFilter string, metricFilter []string) (float64, error) {
+func (semp *Semp) GetQueueStatsSemp2(ctx context.Context, ch chan<- PrometheusMetric, vpnName string, itemFilter string, metricFilter []string) (float64, error) {
 	type Response struct {
 		Queue []struct {
 			QueueName                           string  `json:"queueName"`
@@ -79,7 +95,10 @@ func (semp *Semp) GetQueueStatsSemp2(ch chan<- PrometheusMetric, vpnName string,
 	var page = 1
 	var lastQueueName = ""
 	for nextURL := semp.brokerURI + "/SEMP/v2/monitor/msgVpns/" + vpnName + "/queues?" + getParameter; nextURL != ""; {
-		body, err := semp.getHTTPbytes(nextURL, "application/json ", "QueueStatsSemp2", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json ", "QueueStatsSemp2", page)
 		page++
 
 		if err != nil {
@@ -91,6 +110,7 @@ func (semp *Semp) GetQueueStatsSemp2(ch chan<- PrometheusMetric, vpnName string,
 		err = json.Unmarshal(body, &response)
 		if err != nil {
 			semp.logger.Error("Can't decode QueueStatsSemp2", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
 			return 0, err
 		}
 		if response.Meta.ResponseCode != 200 {
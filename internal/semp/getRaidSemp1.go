@@ -1,14 +1,30 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Raid",
+		Aliases:     []string{"RaidV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Platform:    PlatformHardware,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Raid"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetRaidSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetRaidSemp1 Get system disk information (for Appliance)
-func (semp *Semp) GetRaidSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetRaidSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -33,7 +49,7 @@ func (semp *Semp) GetRaidSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	}
 
 	command := "<rpc><show><disk></disk></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "RaidSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "RaidSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape GetRaidSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -44,6 +60,7 @@ func (semp *Semp) GetRaidSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml GetRaidSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
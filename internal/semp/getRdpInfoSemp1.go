@@ -1,15 +1,31 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "RdpInfo",
+		Aliases:     []string{"RdpInfoV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "may harm broker if many REST delivery points",
+		Metrics:     []Descriptions{MetricDesc["RdpInfo"], MetricDesc["RdpTotals"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetRdpInfoSemp1(ctx, ch, query.VpnFilter, query.ItemFilter)
+		},
+	})
+}
+
 // GetRdpInfoSemp1 Get rates for each individual queue of all VPNs
 // This can result in heavy system load for lots of queues
-func (semp *Semp) GetRdpInfoSemp1(ch chan<- PrometheusMetric, vpnFilter string, itemFilter string) (float64, error) {
+func (semp *Semp) GetRdpInfoSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -48,7 +64,10 @@ func (semp *Semp) GetRdpInfoSemp1(ch chan<- PrometheusMetric, vpnFilter string,
 	var page = 1
 	var lastRdpName = ""
 	for command := "<rpc><show><message-vpn><vpn-name>" + vpnFilter + "</vpn-name><rest></rest><rest-delivery-point></rest-delivery-point><rdp-name>" + itemFilter + "</rdp-name></message-vpn></show></rpc>"; command != ""; {
-		body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "RdpInfoSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "RdpInfoSemp1", page)
 		page++
 		if err != nil {
 			semp.logger.Error("Can't scrape RdpInfoSemp1", "err", err, "broker", semp.brokerURI)
@@ -59,6 +78,7 @@ func (semp *Semp) GetRdpInfoSemp1(ch chan<- PrometheusMetric, vpnFilter string,
 		err = decoder.Decode(&target)
 		if err != nil {
 			semp.logger.Error("Can't decode Xml RdpInfoSemp1", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
 			_ = body.Close()
 			return 0, err
 		}
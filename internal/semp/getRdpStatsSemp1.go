@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
     "fmt"
 	"solace_exporter/internal/semp/types"
@@ -8,9 +9,24 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "RdpStats",
+		Aliases:     []string{"RdpStatsV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		ItemFilter:  true,
+		Performance: "may harm broker if many REST delivery points",
+		Metrics:     []Descriptions{MetricDesc["RdpStats"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetRdpStatsSemp1(ctx, ch, query.VpnFilter, query.ItemFilter, query.PageSize)
+		},
+	})
+}
+
 // GetRdpStatsSemp1 Get rates for each individual queue of all VPNs
 // This can result in heavy system load for lots of queues
-func (semp *Semp) GetRdpStatsSemp1(ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetRdpStatsSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -43,8 +59,11 @@ func (semp *Semp) GetRdpStatsSemp1(ch chan<- PrometheusMetric, vpnFilter string,
 	var page = 1
 	var lastRdpName = ""
 	for command := fmt.Sprintf("<rpc><show><message-vpn><vpn-name>" + vpnFilter + "</vpn-name><rest></rest><rest-delivery-point></rest-delivery-point><rdp-name>" + itemFilter + "</rdp-name><stats/><count/><num-elements>%d</num-elements></message-vpn></show></rpc>", sempPageSize); command != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
 		semp.logger.Debug("RdpStatsSemp1", "vpnFilter", vpnFilter, "itemFilter", itemFilter)
-		body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "RdpStatsSemp1", page)
+		body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "RdpStatsSemp1", page)
 		page++
 		if err != nil {
 			semp.logger.Error("Can't scrape RdpStatsSemp1", "err", err, "broker", semp.brokerURI)
@@ -55,6 +74,7 @@ func (semp *Semp) GetRdpStatsSemp1(ch chan<- PrometheusMetric, vpnFilter string,
 		err = decoder.Decode(&target)
 		if err != nil {
 			semp.logger.Error("Can't decode Xml RdpStatsSemp1", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
 			_ = body.Close()
 			return 0, err
 		}
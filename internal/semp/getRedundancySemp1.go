@@ -1,70 +1,74 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Redundancy",
+		Aliases:     []string{"RedundancyV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Performance: "dont harm broker (only for HA broker)",
+		Metrics:     []Descriptions{MetricDesc["Redundancy"], MetricDesc["RedundancyHW"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetRedundancySemp1(ctx, ch)
+		},
+	})
+}
+
+// redundancySemp1Reply is the reply to `show redundancy`.
+type redundancySemp1Reply struct {
+	RPC struct {
+		Show struct {
+			Red struct {
+				ConfigStatus      string `xml:"config-status"`
+				RedundancyStatus  string `xml:"redundancy-status"`
+				OperatingMode     string `xml:"operating-mode"`
+				RedundancyMode    string `xml:"redundancy-mode"`
+				ActiveStandbyRole string `xml:"active-standby-role"`
+				MateRouterName    string `xml:"mate-router-name"`
+				OperationalStatus struct {
+					ADBLink  bool `xml:"adb-link-up"`
+					ADBHello bool `xml:"adb-hello-up"`
+				} `xml:"oper-status"`
+				VirtualRouters struct {
+					Primary struct {
+						Status struct {
+							Activity string `xml:"activity"`
+						} `xml:"status"`
+					} `xml:"primary"`
+					Backup struct {
+						Status struct {
+							Activity string `xml:"activity"`
+						} `xml:"status"`
+					} `xml:"backup"`
+				} `xml:"virtual-routers"`
+			} `xml:"redundancy"`
+		} `xml:"show"`
+	} `xml:"rpc"`
+	ExecuteResult types.ExecuteResult `xml:"execute-result"`
+}
+
+// localActive reports whether the broker is the active node of its HA pair: the virtual router of its role is active.
+func (target *redundancySemp1Reply) localActive() bool {
+	red := target.RPC.Show.Red
+	return red.ActiveStandbyRole == "Primary" && red.VirtualRouters.Primary.Status.Activity == "Local Active" ||
+		red.ActiveStandbyRole == "Backup" && red.VirtualRouters.Backup.Status.Activity == "Local Active"
+}
+
 // GetRedundancySemp1 Get system-wide basic redundancy information for HA triples
-func (semp *Semp) GetRedundancySemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetRedundancySemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	var redundancyState float64
 
-	type Data struct {
-		RPC struct {
-			Show struct {
-				Red struct {
-					ConfigStatus      string `xml:"config-status"`
-					RedundancyStatus  string `xml:"redundancy-status"`
-					OperatingMode     string `xml:"operating-mode"`
-					RedundancyMode    string `xml:"redundancy-mode"`
-					ActiveStandbyRole string `xml:"active-standby-role"`
-					MateRouterName    string `xml:"mate-router-name"`
-					OperationalStatus struct {
-						ADBLink  bool `xml:"adb-link-up"`
-						ADBHello bool `xml:"adb-hello-up"`
-					} `xml:"oper-status"`
-					VirtualRouters struct {
-						Primary struct {
-							Status struct {
-								Activity string `xml:"activity"`
-							} `xml:"status"`
-						} `xml:"primary"`
-						Backup struct {
-							Status struct {
-								Activity string `xml:"activity"`
-							} `xml:"status"`
-						} `xml:"backup"`
-					} `xml:"virtual-routers"`
-				} `xml:"redundancy"`
-			} `xml:"show"`
-		} `xml:"rpc"`
-		ExecuteResult types.ExecuteResult `xml:"execute-result"`
-	}
-
-	command := "<rpc><show><redundancy/></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "RedundancySemp1", 1)
-	if err != nil {
-		semp.logger.Error("Can't scrape RedundancySemp1", "err", err, "broker", semp.brokerURI)
-		return -1, err
-	}
-	defer func() { _ = body.Close() }()
-	decoder := xml.NewDecoder(body)
-	var target Data
-	err = decoder.Decode(&target)
+	target, up, err := semp.fetchRedundancySemp1(ctx)
 	if err != nil {
-		semp.logger.Error("Can't decode Xml RedundancySemp1", "err", err, "broker", semp.brokerURI)
-		return 0, err
-	}
-	if err := target.ExecuteResult.OK(); err != nil {
-		semp.logger.Error("unexpected result",
-			"command", command,
-			"result", target.ExecuteResult.Result,
-			"reason", target.ExecuteResult.Reason,
-			"broker", semp.brokerURI,
-		)
-		return 0, err
+		return up, err
 	}
 
 	mateRouterName := "" + target.RPC.Show.Red.MateRouterName
@@ -77,8 +81,7 @@ func (semp *Semp) GetRedundancySemp1(ch chan<- PrometheusMetric) (float64, error
 		ch <- semp.NewMetric(MetricDesc["RedundancyHW"]["system_redundancy_hw_adb_hello"], prometheus.GaugeValue, encodeMetricBool(target.RPC.Show.Red.OperationalStatus.ADBHello), mateRouterName)
 	}
 
-	if target.RPC.Show.Red.ActiveStandbyRole == "Primary" && target.RPC.Show.Red.VirtualRouters.Primary.Status.Activity == "Local Active" ||
-		target.RPC.Show.Red.ActiveStandbyRole == "Backup" && target.RPC.Show.Red.VirtualRouters.Backup.Status.Activity == "Local Active" {
+	if target.localActive() {
 		redundancyState = 1
 	} else {
 		redundancyState = 0
@@ -87,3 +90,43 @@ func (semp *Semp) GetRedundancySemp1(ch chan<- PrometheusMetric) (float64, error
 
 	return 1, nil
 }
+
+// IsLocalActive reports whether the broker is the active node of its HA pair, which GetRedundancySemp1 reports as
+// system_redundancy_local_active.
+func (semp *Semp) IsLocalActive(ctx context.Context) (bool, error) {
+	target, _, err := semp.fetchRedundancySemp1(ctx)
+	if err != nil {
+		return false, err
+	}
+	return target.localActive(), nil
+}
+
+// fetchRedundancySemp1 sends `show redundancy`. On error, up is the up value of the Redundancy data source.
+func (semp *Semp) fetchRedundancySemp1(ctx context.Context) (target *redundancySemp1Reply, up float64, err error) {
+	command := "<rpc><show><redundancy/></show></rpc>"
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "RedundancySemp1", 1)
+	if err != nil {
+		semp.logger.Error("Can't scrape RedundancySemp1", "err", err, "broker", semp.brokerURI)
+		return nil, -1, err
+	}
+	defer func() { _ = body.Close() }()
+	decoder := xml.NewDecoder(body)
+	target = new(redundancySemp1Reply)
+	err = decoder.Decode(target)
+	if err != nil {
+		semp.logger.Error("Can't decode Xml RedundancySemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
+		return nil, 0, err
+	}
+	if err := target.ExecuteResult.OK(); err != nil {
+		semp.logger.Error("unexpected result",
+			"command", command,
+			"result", target.ExecuteResult.Result,
+			"reason", target.ExecuteResult.Reason,
+			"broker", semp.brokerURI,
+		)
+		return nil, 0, err
+	}
+
+	return target, 1, nil
+}
@@ -1,14 +1,28 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "ReplicationStats",
+		Aliases:     []string{"ReplicationStatsV1"},
+		SempVersion: 1,
+		Performance: "dont harm broker (only for DR broker)",
+		Metrics:     []Descriptions{MetricDesc["ReplicationStats"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetReplicationStatsSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetReplicationStatsSemp1 Get DR replication statistics
-func (semp *Semp) GetReplicationStatsSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetReplicationStatsSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -68,7 +82,7 @@ func (semp *Semp) GetReplicationStatsSemp1(ch chan<- PrometheusMetric) (float64,
 	}
 
 	command := "<rpc><show><replication><stats/></replication></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "ReplicationStatsSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "ReplicationStatsSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape ReplicationStatsSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -79,6 +93,7 @@ func (semp *Semp) GetReplicationStatsSemp1(ch chan<- PrometheusMetric) (float64,
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml ReplicationStatsSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
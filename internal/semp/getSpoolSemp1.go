@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"math"
 	"solace_exporter/internal/semp/types"
@@ -9,8 +10,21 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Spool",
+		Aliases:     []string{"SpoolV1"},
+		SempVersion: 1,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Spool"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetSpoolSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetSpoolSemp1 Get system-wide spool information
-func (semp *Semp) GetSpoolSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetSpoolSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -79,7 +93,7 @@ func (semp *Semp) GetSpoolSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	}
 
 	command := "<rpc><show><message-spool><detail/></message-spool></show ></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "SpoolSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "SpoolSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape Solace", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -90,6 +104,7 @@ func (semp *Semp) GetSpoolSemp1(ch chan<- PrometheusMetric) (float64, error) {
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
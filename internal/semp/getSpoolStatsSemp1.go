@@ -1,14 +1,28 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "SpoolStats",
+		Aliases:     []string{"SpoolStatsV1"},
+		SempVersion: 1,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["SpoolStats"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetSpoolStatsSemp1(ctx, ch)
+		},
+	})
+}
+
 // GetSpoolStatsSemp1 Get system-wide spool statistics
-func (semp *Semp) GetSpoolStatsSemp1(ch chan<- PrometheusMetric) (float64, error) {
+func (semp *Semp) GetSpoolStatsSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -126,7 +140,7 @@ func (semp *Semp) GetSpoolStatsSemp1(ch chan<- PrometheusMetric) (float64, error
 	}
 
 	command := "<rpc><show><message-spool><stats/></message-spool></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "SpoolStatsSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "SpoolStatsSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape Solace", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -137,6 +151,7 @@ func (semp *Semp) GetSpoolStatsSemp1(ch chan<- PrometheusMetric) (float64, error
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -1,14 +1,31 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "StorageElement",
+		Aliases:     []string{"StorageElementV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Platform:    PlatformSoftware,
+		ItemFilter:  true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["StorageElement"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetStorageElementSemp1(ctx, ch, query.ItemFilter)
+		},
+	})
+}
+
 // GetStorageElementSemp1 Get system storage-element information (for Software Broker)
-func (semp *Semp) GetStorageElementSemp1(ch chan<- PrometheusMetric, storageElementFilter string) (float64, error) {
+func (semp *Semp) GetStorageElementSemp1(ctx context.Context, ch chan<- PrometheusMetric, storageElementFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -29,7 +46,7 @@ func (semp *Semp) GetStorageElementSemp1(ch chan<- PrometheusMetric, storageElem
 	}
 
 	command := "<rpc><show><storage-element><pattern>" + storageElementFilter + "</pattern></storage-element></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "StorageElementSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "StorageElementSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape StorageElementSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -40,6 +57,7 @@ func (semp *Semp) GetStorageElementSemp1(ch chan<- PrometheusMetric, storageElem
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml StorageElementSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -0,0 +1,156 @@
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:         "TopicEndpointStatsV2",
+		SempVersion:  2,
+		VpnFilter:    true,
+		ItemFilter:   true,
+		MetricFilter: true,
+		Performance:  "may harm broker if many topic-endpoints, less than TopicEndpointStats",
+		Metrics:      []Descriptions{MetricDesc["TopicEndpointStatsV2"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetTopicEndpointStatsSemp2(ctx, ch, query.VpnFilter, query.ItemFilter, query.MetricFilter, query.PageSize)
+		},
+	})
+}
+
+// GetTopicEndpointStatsSemp2 Get statistics for each individual topic-endpoint of one VPN. itemFilter is a
+// topic-endpoint name, or a SEMP v2 where condition.
+// This can result in heavy system load for lots of topic-endpoints
+func (semp *Semp) GetTopicEndpointStatsSemp2(ctx context.Context, ch chan<- PrometheusMetric, vpnName string, itemFilter string, metricFilter []string, sempPageSize int64) (float64, error) {
+	type Response struct {
+		TopicEndpoint []struct {
+			TopicEndpointName      string  `json:"topicEndpointName"`
+			MsgVpnName             string  `json:"msgVpnName"`
+			TotalByteSpooled       float64 `json:"spooledByteCount"`
+			TotalMsgSpooled        float64 `json:"spooledMsgCount"`
+			MsgRedelivered         float64 `json:"redeliveredMsgCount"`
+			MsgRetransmit          float64 `json:"transportRetransmitMsgCount"`
+			SpoolUsageExceeded     float64 `json:"maxMsgSpoolUsageExceededDiscardedMsgCount"`
+			MsgSizeExceeded        float64 `json:"maxMsgSizeExceededDiscardedMsgCount"`
+			SpoolShutdownDiscard   float64 `json:"disabledDiscardedMsgCount"`
+			Deleted                float64 `json:"deletedMsgCount"`
+			TTLDiscarded           float64 `json:"maxTtlExpiredDiscardedMsgCount"`
+			TTLDmq                 float64 `json:"maxTtlExpiredToDmqMsgCount"`
+			TTLDmqFailed           float64 `json:"maxTtlExpiredToDmqFailedMsgCount"`
+			MaxRedeliveryDiscarded float64 `json:"maxRedeliveryExceededDiscardedMsgCount"`
+			MaxRedeliveryDmq       float64 `json:"maxRedeliveryExceededToDmqMsgCount"`
+			MaxRedeliveryDmqFailed float64 `json:"maxRedeliveryExceededToDmqFailedMsgCount"`
+		} `json:"data"`
+		Meta struct {
+			Count        int64 `json:"count"`
+			ResponseCode int   `json:"responseCode"`
+			Paging       struct {
+				CursorQuery string `json:"cursorQuery"`
+				NextPageURI string `json:"nextPageUri"`
+			} `json:"paging"`
+			Error struct {
+				Code        int    `json:"code"`
+				Description string `json:"description"`
+				Status      string `json:"status"`
+			} `json:"error"`
+		} `json:"meta"`
+	}
+
+	var getParameter = fmt.Sprintf("count=%d", sempPageSize)
+
+	if len(strings.TrimSpace(itemFilter)) > 0 && itemFilter != "*" {
+		if strings.Contains(itemFilter, "=") {
+			getParameter += "&where=" + queryEscape(itemFilter)
+		} else {
+			getParameter += "&where=" + queryEscape("topicEndpointName=="+itemFilter)
+		}
+	}
+
+	var fieldsToSelect []string
+	if len(metricFilter) > 0 {
+		var err error
+
+		fieldsToSelect, err = getSempV2FieldsToSelect(
+			metricFilter,
+			[]string{"topicEndpointName", "msgVpnName"},
+			TopicEndpointStats,
+		)
+
+		if err != nil {
+			semp.logger.Error("Unable to map metric filter", "err", err, "broker", semp.brokerURI)
+			return 0, err
+		}
+		getParameter += "&select=" + strings.Join(fieldsToSelect, ",")
+	}
+
+	var page = 1
+	var lastTopicEndpointName = ""
+	for nextURL := semp.brokerURI + "/SEMP/v2/monitor/msgVpns/" + url.PathEscape(vpnName) + "/topicEndpoints?" + getParameter; nextURL != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json", "TopicEndpointStatsSemp2", page)
+		page++
+
+		if err != nil {
+			semp.logger.Error("Can't scrape TopicEndpointStatsSemp2", "command", nextURL, "err", err, "broker", semp.brokerURI)
+			return -1, err
+		}
+
+		var response Response
+		err = json.Unmarshal(body, &response)
+		if err != nil {
+			semp.logger.Error("Can't decode TopicEndpointStatsSemp2", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
+			return 0, err
+		}
+		if response.Meta.ResponseCode != 200 {
+			semp.logger.Error("unexpected result", "command", nextURL, "remoteError", response.Meta.Error.Description, "broker", semp.brokerURI)
+			return 0, errors.New("unexpected result: see log")
+		}
+
+		semp.logger.Debug("Result of TopicEndpointStatsSemp2", "results", len(response.TopicEndpoint), "page", page-1)
+
+		nextURL = response.Meta.Paging.NextPageURI
+		for _, topicEndpoint := range response.TopicEndpoint {
+			topicEndpointKey := topicEndpoint.MsgVpnName + "___" + topicEndpoint.TopicEndpointName
+			if topicEndpointKey == lastTopicEndpointName {
+				continue
+			}
+			lastTopicEndpointName = topicEndpointKey
+
+			var values = []V2Result{
+				{v2Desc: TopicEndpointStats["total_bytes_spooled"], valueType: prometheus.CounterValue, value: topicEndpoint.TotalByteSpooled},
+				{v2Desc: TopicEndpointStats["total_messages_spooled"], valueType: prometheus.CounterValue, value: topicEndpoint.TotalMsgSpooled},
+				{v2Desc: TopicEndpointStats["messages_redelivered"], valueType: prometheus.CounterValue, value: topicEndpoint.MsgRedelivered},
+				{v2Desc: TopicEndpointStats["messages_transport_retransmitted"], valueType: prometheus.CounterValue, value: topicEndpoint.MsgRetransmit},
+				{v2Desc: TopicEndpointStats["spool_usage_exceeded"], valueType: prometheus.CounterValue, value: topicEndpoint.SpoolUsageExceeded},
+				{v2Desc: TopicEndpointStats["max_message_size_exceeded"], valueType: prometheus.CounterValue, value: topicEndpoint.MsgSizeExceeded},
+				{v2Desc: TopicEndpointStats["total_deleted_messages"], valueType: prometheus.CounterValue, value: topicEndpoint.Deleted},
+				{v2Desc: TopicEndpointStats["messages_shutdown_discarded"], valueType: prometheus.CounterValue, value: topicEndpoint.SpoolShutdownDiscard},
+				{v2Desc: TopicEndpointStats["messages_ttl_discarded"], valueType: prometheus.CounterValue, value: topicEndpoint.TTLDiscarded},
+				{v2Desc: TopicEndpointStats["messages_ttl_dmq"], valueType: prometheus.CounterValue, value: topicEndpoint.TTLDmq},
+				{v2Desc: TopicEndpointStats["messages_ttl_dmq_failed"], valueType: prometheus.CounterValue, value: topicEndpoint.TTLDmqFailed},
+				{v2Desc: TopicEndpointStats["messages_max_redelivered_discarded"], valueType: prometheus.CounterValue, value: topicEndpoint.MaxRedeliveryDiscarded},
+				{v2Desc: TopicEndpointStats["messages_max_redelivered_dmq"], valueType: prometheus.CounterValue, value: topicEndpoint.MaxRedeliveryDmq},
+				{v2Desc: TopicEndpointStats["messages_max_redelivered_dmq_failed"], valueType: prometheus.CounterValue, value: topicEndpoint.MaxRedeliveryDmqFailed},
+			}
+
+			for _, v := range values {
+				if v.v2Desc.isSelected(fieldsToSelect) {
+					ch <- semp.NewMetric(v.v2Desc, v.valueType, v.value, topicEndpoint.MsgVpnName, topicEndpoint.TopicEndpointName)
+				}
+			}
+		}
+	}
+
+	return 1, nil
+}
@@ -0,0 +1,40 @@
+package semp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetTopicEndpointStatsSemp2FiltersByItemName(t *testing.T) {
+	t.Parallel()
+
+	s, requests := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns/prod%20a/topicEndpoints": `{"data":[{"topicEndpointName":"te1","msgVpnName":"prod a","spooledMsgCount":7,"deletedMsgCount":2}],` +
+			`"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 10)
+	up, err := s.GetTopicEndpointStatsSemp2(t.Context(), ch, "prod a", "te1", []string{"solace_topic_endpoint_msg_spooled", "deletedMsgCount"}, 100)
+	close(ch)
+	if up != 1 || err != nil {
+		t.Fatalf("GetTopicEndpointStatsSemp2 = %v, %v", up, err)
+	}
+
+	wantRequests := []string{"/SEMP/v2/monitor/msgVpns/prod%20a/topicEndpoints?count=100&where=topicEndpointName%3D%3Dte1&select=spooledMsgCount,deletedMsgCount,topicEndpointName,msgVpnName"}
+	if got := requests(); !slices.Equal(got, wantRequests) {
+		t.Errorf("requests = %q, want %q", got, wantRequests)
+	}
+
+	var names []string
+	for metric := range ch {
+		names = append(names, metric.Name())
+	}
+	slices.Sort(names)
+	want := []string{
+		`solace_topic_endpoint_msg_spooled{vpn_name="prod a",topic_endpoint_name="te1"}`,
+		`solace_topic_endpoint_msg_total_deleted{vpn_name="prod a",topic_endpoint_name="te1"}`,
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("metrics = %q, want %q", names, want)
+	}
+}
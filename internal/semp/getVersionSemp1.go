@@ -2,6 +2,7 @@ package semp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -12,45 +13,49 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// GetVersionSemp1 Get version of broker
-func (semp *Semp) GetVersionSemp1(ch chan<- PrometheusMetric) (float64, error) {
-	type Data struct {
-		RPC struct {
-			Show struct {
-				Version struct {
-					Description string `xml:"description"`
-					CurrentLoad string `xml:"current-load"`
-					Uptime      struct {
-						Days      float64 `xml:"days"`
-						Hours     float64 `xml:"hours"`
-						Mins      float64 `xml:"mins"`
-						Secs      float64 `xml:"secs"`
-						TotalSecs float64 `xml:"total-secs"`
-					} `xml:"uptime"`
-				} `xml:"version"`
-			} `xml:"show"`
-		} `xml:"rpc"`
-		ExecuteResult types.ExecuteResult `xml:"execute-result"`
-	}
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Version",
+		Aliases:     []string{"VersionV1"},
+		SempVersion: 1,
+		PerNode:     true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Version"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, _ DataSourceQuery) (float64, error) {
+			return semp.GetVersionSemp1(ctx, ch)
+		},
+	})
+}
 
-	command := "<rpc><show><version/></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "VersionSemp1", 1)
-	if err != nil {
-		semp.logger.Error("Can't scrape getVersionSemp1", "err", err, "broker", semp.brokerURI)
-		return -1, err
-	}
-	defer func() { _ = body.Close() }()
-	decoder := xml.NewDecoder(body)
-	var target Data
-	err = decoder.Decode(&target)
+// versionSemp1Reply is the reply to `show version`.
+type versionSemp1Reply struct {
+	// SempVersion is the SEMP version of the broker, like soltr/10_4VMR.
+	SempVersion string `xml:"semp-version,attr"`
+	RPC         struct {
+		Show struct {
+			Version struct {
+				Description string `xml:"description"`
+				CurrentLoad string `xml:"current-load"`
+				Uptime      struct {
+					Days      float64 `xml:"days"`
+					Hours     float64 `xml:"hours"`
+					Mins      float64 `xml:"mins"`
+					Secs      float64 `xml:"secs"`
+					TotalSecs float64 `xml:"total-secs"`
+				} `xml:"uptime"`
+			} `xml:"version"`
+		} `xml:"show"`
+	} `xml:"rpc"`
+	ExecuteResult types.ExecuteResult `xml:"execute-result"`
+}
+
+// GetVersionSemp1 Get version of broker
+func (semp *Semp) GetVersionSemp1(ctx context.Context, ch chan<- PrometheusMetric) (float64, error) {
+	target, up, err := semp.fetchVersionSemp1(ctx)
 	if err != nil {
-		semp.logger.Error("Can't decode Xml getVersionSemp1", "err", err, "broker", semp.brokerURI)
-		return 0, err
-	}
-	if target.ExecuteResult.Result != "ok" {
-		semp.logger.Error("Unexpected result for getVersionSemp1", "command", command, "result", target.ExecuteResult.Result, "reason", target.ExecuteResult.Reason, "broker", semp.brokerURI)
-		return 0, errors.New("unexpected result: " + target.ExecuteResult.Reason + ". see log for further details")
+		return up, err
 	}
+	semp.storeBrokerInfo(target)
 
 	// remember this for the label
 	vmrVersion := strings.TrimPrefix(target.RPC.Show.Version.CurrentLoad, "soltr_")
@@ -67,3 +72,28 @@ func (semp *Semp) GetVersionSemp1(ch chan<- PrometheusMetric) (float64, error) {
 
 	return 1, nil
 }
+
+// fetchVersionSemp1 sends `show version`. On error, up is the up value of the Version data source.
+func (semp *Semp) fetchVersionSemp1(ctx context.Context) (target *versionSemp1Reply, up float64, err error) {
+	command := "<rpc><show><version/></show></rpc>"
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "VersionSemp1", 1)
+	if err != nil {
+		semp.logger.Error("Can't scrape getVersionSemp1", "err", err, "broker", semp.brokerURI)
+		return nil, -1, err
+	}
+	defer func() { _ = body.Close() }()
+	decoder := xml.NewDecoder(body)
+	target = new(versionSemp1Reply)
+	err = decoder.Decode(target)
+	if err != nil {
+		semp.logger.Error("Can't decode Xml getVersionSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
+		return nil, 0, err
+	}
+	if target.ExecuteResult.Result != "ok" {
+		semp.logger.Error("Unexpected result for getVersionSemp1", "command", command, "result", target.ExecuteResult.Result, "reason", target.ExecuteResult.Reason, "broker", semp.brokerURI)
+		return nil, 0, errors.New("unexpected result: " + target.ExecuteResult.Reason + ". see log for further details")
+	}
+
+	return target, 1, nil
+}
@@ -1,14 +1,29 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"solace_exporter/internal/semp/types"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "VpnReplication",
+		Aliases:     []string{"VpnReplicationV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["VpnReplication"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetVpnReplicationSemp1(ctx, ch, query.VpnFilter)
+		},
+	})
+}
+
 // Replication Config and status
-func (semp *Semp) GetVpnReplicationSemp1(ch chan<- PrometheusMetric, vpnFilter string) (float64, error) {
+func (semp *Semp) GetVpnReplicationSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -30,7 +45,7 @@ func (semp *Semp) GetVpnReplicationSemp1(ch chan<- PrometheusMetric, vpnFilter s
 	}
 
 	command := "<rpc><show><message-vpn><vpn-name>" + vpnFilter + "</vpn-name><replication/></message-vpn></show></rpc>"
-	body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "VpnReplicationSemp1", 1)
+	body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "VpnReplicationSemp1", 1)
 	if err != nil {
 		semp.logger.Error("Can't scrape VpnReplicationSemp1", "err", err, "broker", semp.brokerURI)
 		return -1, err
@@ -41,6 +56,7 @@ func (semp *Semp) GetVpnReplicationSemp1(ch chan<- PrometheusMetric, vpnFilter s
 	err = decoder.Decode(&target)
 	if err != nil {
 		semp.logger.Error("Can't decode Xml VpnReplicationSemp1", "err", err, "broker", semp.brokerURI)
+		semp.observeDecodeError()
 		return 0, err
 	}
 	if err := target.ExecuteResult.OK(); err != nil {
@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
     "fmt"
@@ -9,8 +10,22 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "Vpn",
+		Aliases:     []string{"VpnV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["Vpn"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetVpnSemp1(ctx, ch, query.VpnFilter, query.PageSize)
+		},
+	})
+}
+
 // GetVpnSemp1 Get info of all VPNs
-func (semp *Semp) GetVpnSemp1(ch chan<- PrometheusMetric, vpnFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetVpnSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -39,7 +54,10 @@ func (semp *Semp) GetVpnSemp1(ch chan<- PrometheusMetric, vpnFilter string, semp
     var page = 1
     var lastVpnName = ""
 	for command := fmt.Sprintf("<rpc><show><message-vpn><vpn-name>" + vpnFilter + "</vpn-name><count/><num-elements>%d</num-elements></message-vpn></show></rpc>", sempPageSize); command != ""; {
-        body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "VpnSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+        body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "VpnSemp1", page)
         page++
 
         if err != nil {
@@ -52,6 +70,7 @@ func (semp *Semp) GetVpnSemp1(ch chan<- PrometheusMetric, vpnFilter string, semp
         err = decoder.Decode(&target)
         if err != nil {
             semp.logger.Error("Can't decode Xml VpnSemp1", "err", err, "broker", semp.brokerURI)
+            semp.observeDecodeError()
             _ = body.Close()
             return 0, err
         }
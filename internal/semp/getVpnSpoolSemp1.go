@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
 	"math"
     "fmt"
@@ -9,8 +10,22 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "VpnSpool",
+		Aliases:     []string{"VpnSpoolV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		Performance: "dont harm broker",
+		Metrics:     []Descriptions{MetricDesc["VpnSpool"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetVpnSpoolSemp1(ctx, ch, query.VpnFilter, query.PageSize)
+		},
+	})
+}
+
 // GetVpnSpoolSemp1 Replication Config and status
-func (semp *Semp) GetVpnSpoolSemp1(ch chan<- PrometheusMetric, vpnFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetVpnSpoolSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -42,7 +57,10 @@ func (semp *Semp) GetVpnSpoolSemp1(ch chan<- PrometheusMetric, vpnFilter string,
     var page = 1
     var lastVpnName = ""
 	for command := fmt.Sprintf("<rpc><show><message-spool><vpn-name>" + vpnFilter + "</vpn-name><detail/><count/><num-elements>%d</num-elements></message-spool></show></rpc>", sempPageSize); command != ""; {
-        body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "VpnSpoolSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+        body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "VpnSpoolSemp1", page)
         page++
 
         if err != nil {
@@ -55,6 +73,7 @@ func (semp *Semp) GetVpnSpoolSemp1(ch chan<- PrometheusMetric, vpnFilter string,
         err = decoder.Decode(&target)
         if err != nil {
             semp.logger.Error("Can't decode Xml VpnSpoolSemp1", "err", err, "broker", semp.brokerURI)
+            semp.observeDecodeError()
             _ = body.Close()
             return 0, err
         }
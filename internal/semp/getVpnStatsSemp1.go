@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"context"
 	"encoding/xml"
     "fmt"
 	"solace_exporter/internal/semp/types"
@@ -8,8 +9,22 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:        "VpnStats",
+		Aliases:     []string{"VpnStatsV1"},
+		SempVersion: 1,
+		VpnFilter:   true,
+		Performance: "has a very small performance down site",
+		Metrics:     []Descriptions{MetricDesc["VpnStats"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetVpnStatsSemp1(ctx, ch, query.VpnFilter, query.PageSize)
+		},
+	})
+}
+
 // GetVpnStatsSemp1 Get statistics of all VPNs
-func (semp *Semp) GetVpnStatsSemp1(ch chan<- PrometheusMetric, vpnFilter string, sempPageSize int64) (float64, error) {
+func (semp *Semp) GetVpnStatsSemp1(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, sempPageSize int64) (float64, error) {
 	type Data struct {
 		RPC struct {
 			Show struct {
@@ -62,7 +77,10 @@ func (semp *Semp) GetVpnStatsSemp1(ch chan<- PrometheusMetric, vpnFilter string,
     var page = 1
 	var lastVpnName = ""
 	for command := fmt.Sprintf("<rpc><show><message-vpn><vpn-name>" + vpnFilter + "</vpn-name><stats/><count/><num-elements>%d</num-elements></message-vpn></show></rpc>", sempPageSize); command != ""; {
-        body, err := semp.postHTTP(semp.brokerURI+"/SEMP", "application/xml", command, "VpnStatsSemp1", page)
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+        body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "VpnStatsSemp1", page)
         page++
 
         if err != nil {
@@ -75,6 +93,7 @@ func (semp *Semp) GetVpnStatsSemp1(ch chan<- PrometheusMetric, vpnFilter string,
         err = decoder.Decode(&target)
         if err != nil {
             semp.logger.Error("Can't decode Xml VpnStatsSemp1", "err", err, "broker", semp.brokerURI)
+            semp.observeDecodeError()
             _ = body.Close()
             return 0, err
         }
@@ -0,0 +1,168 @@
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	RegisterDataSource(&DataSourceDescriptor{
+		Name:         "VpnStatsV2",
+		SempVersion:  2,
+		VpnFilter:    true,
+		ItemFilter:   true,
+		MetricFilter: true,
+		CrossVpn:     true,
+		Performance:  "dont harm broker",
+		Metrics:      []Descriptions{MetricDesc["VpnStatsV2"]},
+		Collect: func(ctx context.Context, semp *Semp, ch chan<- PrometheusMetric, query DataSourceQuery) (float64, error) {
+			return semp.GetVpnStatsSemp2(ctx, ch, query.VpnFilter, query.ItemFilter, query.MetricFilter, query.PageSize)
+		},
+	})
+}
+
+// GetVpnStatsSemp2 Get statistics of all VPNs matching vpnFilter. itemFilter is an additional SEMP v2 where condition,
+// like msgVpnConnections>0. Only the fields of metricFilter are requested from the broker.
+func (semp *Semp) GetVpnStatsSemp2(ctx context.Context, ch chan<- PrometheusMetric, vpnFilter string, itemFilter string, metricFilter []string, sempPageSize int64) (float64, error) {
+	type Response struct {
+		MsgVpn []struct {
+			MsgVpnName                            string  `json:"msgVpnName"`
+			DataRxMsgCount                        float64 `json:"dataRxMsgCount"`
+			DataTxMsgCount                        float64 `json:"dataTxMsgCount"`
+			DataRxByteCount                       float64 `json:"dataRxByteCount"`
+			DataTxByteCount                       float64 `json:"dataTxByteCount"`
+			DiscardedRxMsgCount                   float64 `json:"discardedRxMsgCount"`
+			DiscardedTxMsgCount                   float64 `json:"discardedTxMsgCount"`
+			MsgVpnConnections                     float64 `json:"msgVpnConnections"`
+			MsgVpnConnectionsServiceAmqp          float64 `json:"msgVpnConnectionsServiceAmqp"`
+			MsgVpnConnectionsServiceMqtt          float64 `json:"msgVpnConnectionsServiceMqtt"`
+			MsgVpnConnectionsServiceSmf           float64 `json:"msgVpnConnectionsServiceSmf"`
+			MsgVpnConnectionsServiceWeb           float64 `json:"msgVpnConnectionsServiceWeb"`
+			MsgVpnConnectionsServiceRestIncoming  float64 `json:"msgVpnConnectionsServiceRestIncoming"`
+			MsgVpnConnectionsServiceRestOutgoing  float64 `json:"msgVpnConnectionsServiceRestOutgoing"`
+			MaxConnectionCount                    float64 `json:"maxConnectionCount"`
+			ServiceAmqpMaxConnectionCount         float64 `json:"serviceAmqpMaxConnectionCount"`
+			ServiceSmfMaxConnectionCount          float64 `json:"serviceSmfMaxConnectionCount"`
+			ServiceWebMaxConnectionCount          float64 `json:"serviceWebMaxConnectionCount"`
+			ServiceMqttMaxConnectionCount         float64 `json:"serviceMqttMaxConnectionCount"`
+			ServiceRestIncomingMaxConnectionCount float64 `json:"serviceRestIncomingMaxConnectionCount"`
+			ServiceRestOutgoingMaxConnectionCount float64 `json:"serviceRestOutgoingMaxConnectionCount"`
+		} `json:"data"`
+		Meta struct {
+			Count        int64 `json:"count"`
+			ResponseCode int   `json:"responseCode"`
+			Paging       struct {
+				CursorQuery string `json:"cursorQuery"`
+				NextPageURI string `json:"nextPageUri"`
+			} `json:"paging"`
+			Error struct {
+				Code        int    `json:"code"`
+				Description string `json:"description"`
+				Status      string `json:"status"`
+			} `json:"error"`
+		} `json:"meta"`
+	}
+
+	var getParameter = fmt.Sprintf("count=%d", sempPageSize)
+
+	var conditions []string
+	if len(strings.TrimSpace(vpnFilter)) > 0 && vpnFilter != "*" {
+		conditions = append(conditions, "msgVpnName=="+vpnFilter)
+	}
+	if len(strings.TrimSpace(itemFilter)) > 0 && itemFilter != "*" {
+		conditions = append(conditions, itemFilter)
+	}
+	if len(conditions) > 0 {
+		getParameter += "&where=" + queryEscape(strings.Join(conditions, ","))
+	}
+
+	var fieldsToSelect []string
+	if len(metricFilter) > 0 {
+		var err error
+
+		fieldsToSelect, err = getSempV2FieldsToSelect(
+			metricFilter,
+			[]string{"msgVpnName"},
+			VpnStats,
+		)
+
+		if err != nil {
+			semp.logger.Error("Unable to map metric filter", "err", err, "broker", semp.brokerURI)
+			return 0, err
+		}
+		getParameter += "&select=" + strings.Join(fieldsToSelect, ",")
+	}
+
+	var page = 1
+	var lastVpnName = ""
+	for nextURL := semp.brokerURI + "/SEMP/v2/monitor/msgVpns?" + getParameter; nextURL != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return -1, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json", "VpnStatsSemp2", page)
+		page++
+
+		if err != nil {
+			semp.logger.Error("Can't scrape VpnStatsSemp2", "command", nextURL, "err", err, "broker", semp.brokerURI)
+			return -1, err
+		}
+
+		var response Response
+		err = json.Unmarshal(body, &response)
+		if err != nil {
+			semp.logger.Error("Can't decode VpnStatsSemp2", "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
+			return 0, err
+		}
+		if response.Meta.ResponseCode != 200 {
+			semp.logger.Error("unexpected result", "command", nextURL, "remoteError", response.Meta.Error.Description, "broker", semp.brokerURI)
+			return 0, errors.New("unexpected result: see log")
+		}
+
+		semp.logger.Debug("Result of VpnStatsSemp2", "results", len(response.MsgVpn), "page", page-1)
+
+		nextURL = response.Meta.Paging.NextPageURI
+		for _, vpn := range response.MsgVpn {
+			if vpn.MsgVpnName == lastVpnName {
+				continue
+			}
+			lastVpnName = vpn.MsgVpnName
+
+			var values = []V2Result{
+				{v2Desc: VpnStats["vpn_rx_msgs_total"], valueType: prometheus.CounterValue, value: vpn.DataRxMsgCount},
+				{v2Desc: VpnStats["vpn_tx_msgs_total"], valueType: prometheus.CounterValue, value: vpn.DataTxMsgCount},
+				{v2Desc: VpnStats["vpn_rx_bytes_total"], valueType: prometheus.CounterValue, value: vpn.DataRxByteCount},
+				{v2Desc: VpnStats["vpn_tx_bytes_total"], valueType: prometheus.CounterValue, value: vpn.DataTxByteCount},
+				{v2Desc: VpnStats["vpn_rx_discarded_msgs_total"], valueType: prometheus.CounterValue, value: vpn.DiscardedRxMsgCount},
+				{v2Desc: VpnStats["vpn_tx_discarded_msgs_total"], valueType: prometheus.CounterValue, value: vpn.DiscardedTxMsgCount},
+				{v2Desc: VpnStats["vpn_connections"], valueType: prometheus.GaugeValue, value: vpn.MsgVpnConnections},
+				{v2Desc: VpnStats["vpn_connections_service_amqp"], valueType: prometheus.GaugeValue, value: vpn.MsgVpnConnectionsServiceAmqp},
+				{v2Desc: VpnStats["vpn_connections_service_mqtt"], valueType: prometheus.GaugeValue, value: vpn.MsgVpnConnectionsServiceMqtt},
+				{v2Desc: VpnStats["vpn_connections_service_smf"], valueType: prometheus.GaugeValue, value: vpn.MsgVpnConnectionsServiceSmf},
+				{v2Desc: VpnStats["vpn_connections_service_web"], valueType: prometheus.GaugeValue, value: vpn.MsgVpnConnectionsServiceWeb},
+				{v2Desc: VpnStats["vpn_connections_service_rest_in"], valueType: prometheus.GaugeValue, value: vpn.MsgVpnConnectionsServiceRestIncoming},
+				{v2Desc: VpnStats["vpn_connections_service_rest_out"], valueType: prometheus.GaugeValue, value: vpn.MsgVpnConnectionsServiceRestOutgoing},
+				{v2Desc: VpnStats["vpn_quota_connections"], valueType: prometheus.GaugeValue, value: vpn.MaxConnectionCount},
+				{v2Desc: VpnStats["vpn_quota_connections_smf"], valueType: prometheus.GaugeValue, value: vpn.ServiceSmfMaxConnectionCount},
+				{v2Desc: VpnStats["vpn_quota_connections_web"], valueType: prometheus.GaugeValue, value: vpn.ServiceWebMaxConnectionCount},
+				{v2Desc: VpnStats["vpn_quota_connections_amqp"], valueType: prometheus.GaugeValue, value: vpn.ServiceAmqpMaxConnectionCount},
+				{v2Desc: VpnStats["vpn_quota_connections_mqtt"], valueType: prometheus.GaugeValue, value: vpn.ServiceMqttMaxConnectionCount},
+				{v2Desc: VpnStats["vpn_quota_connections_rest_in"], valueType: prometheus.GaugeValue, value: vpn.ServiceRestIncomingMaxConnectionCount},
+				{v2Desc: VpnStats["vpn_quota_connections_rest_out"], valueType: prometheus.GaugeValue, value: vpn.ServiceRestOutgoingMaxConnectionCount},
+			}
+
+			for _, v := range values {
+				if v.v2Desc.isSelected(fieldsToSelect) {
+					ch <- semp.NewMetric(v.v2Desc, v.valueType, v.value, vpn.MsgVpnName)
+				}
+			}
+		}
+	}
+
+	return 1, nil
+}
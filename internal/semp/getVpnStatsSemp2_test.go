@@ -0,0 +1,36 @@
+package semp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetVpnStatsSemp2CombinesVpnAndItemFilter(t *testing.T) {
+	t.Parallel()
+
+	s, requests := newSemp2TestServer(t, map[string]string{
+		"/SEMP/v2/monitor/msgVpns": `{"data":[{"msgVpnName":"prod-a","dataRxMsgCount":7,"msgVpnConnections":3}],"meta":{"responseCode":200}}`,
+	})
+
+	ch := make(chan PrometheusMetric, 10)
+	up, err := s.GetVpnStatsSemp2(t.Context(), ch, "prod-*", "msgVpnConnections>0", []string{"solace_vpn_rx_msgs_total", "msgVpnConnections"}, 50)
+	close(ch)
+	if up != 1 || err != nil {
+		t.Fatalf("GetVpnStatsSemp2 = %v, %v", up, err)
+	}
+
+	wantRequests := []string{"/SEMP/v2/monitor/msgVpns?count=50&where=msgVpnName%3D%3Dprod-%2A%2CmsgVpnConnections%3E0&select=dataRxMsgCount,msgVpnConnections,msgVpnName"}
+	if got := requests(); !slices.Equal(got, wantRequests) {
+		t.Errorf("requests = %q, want %q", got, wantRequests)
+	}
+
+	var names []string
+	for metric := range ch {
+		names = append(names, metric.Name())
+	}
+	slices.Sort(names)
+	want := []string{`solace_vpn_connections{vpn_name="prod-a"}`, `solace_vpn_rx_msgs_total{vpn_name="prod-a"}`}
+	if !slices.Equal(names, want) {
+		t.Errorf("metrics = %q, want %q", names, want)
+	}
+}
@@ -1,6 +1,7 @@
 package semp
 
 import (
+	"encoding/xml"
 	"fmt"
 	"net/url"
 	"strings"
@@ -45,3 +46,10 @@ func queryEscape(raw string) string {
 
 	return url.QueryEscape(raw)
 }
+
+// xmlEscape escapes raw for the text of an element of a SEMP v1 command.
+func xmlEscape(raw string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(raw))
+	return escaped.String()
+}
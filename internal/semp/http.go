@@ -1,6 +1,9 @@
 package semp
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,11 +14,69 @@ import (
 const longQuery time.Duration = 2 * 1000 * 1000 * 1000             // 2 seconds
 const longQueryFirstSempV2 time.Duration = 15 * 1000 * 1000 * 1000 // 15 seconds
 
-// Call http post for the supplied uri and body
-func (semp *Semp) postHTTP(uri string, _ string, body string, logName string, page int) (io.ReadCloser, error) {
+// acquireConnection waits for the broker's limiter, at most as long as the HTTP timeout and until ctx is done. The
+// returned release must be called once the response is read.
+func (semp *Semp) acquireConnection(ctx context.Context) (release func(), err error) {
+	if semp.httpClient.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, semp.httpClient.Timeout)
+		defer cancel()
+	}
+
+	return semp.limiter.acquire(ctx)
+}
+
+// scrapeCancelled returns an error once ctx is done, so paging loops stop at the next page boundary when the scrape
+// was abandoned or its timeout ran out, instead of fetching the remaining pages for nobody.
+func scrapeCancelled(ctx context.Context, page int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("scrape cancelled before page %d: %w", page, context.Cause(ctx))
+	}
+	return nil
+}
+
+// Call http post for the supplied uri and body. The request is aborted when ctx is done, and not sent at all while the
+// circuit breaker of the broker is open. Monitor reads (show commands) are retried after transient failures, see
+// RetryPolicy. The response is read completely before returning, so the broker connection is given back to the
+// limiter right away, no matter how the caller handles the body.
+func (semp *Semp) postHTTP(ctx context.Context, uri string, _ string, body string, logName string, page int) (io.ReadCloser, error) {
+	var content []byte
+	attempt := func() (err error) {
+		content, err = semp.postHTTPOnce(ctx, uri, body, logName, page)
+		return err
+	}
+
+	if err := semp.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if isMonitorRead(body) {
+		err = semp.withRetries(ctx, logName, page, attempt)
+	} else {
+		err = attempt()
+	}
+	semp.breaker.done(ctx, err)
+	if err != nil {
+		content, err = replyOfTransientError(err)
+		if err != nil {
+			return nil, err
+		}
+	}
+	semp.observePage(len(content))
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (semp *Semp) postHTTPOnce(ctx context.Context, uri string, body string, logName string, page int) ([]byte, error) {
+	release, err := semp.acquireConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	start := time.Now()
 
-	req, err := http.NewRequest("POST", uri, strings.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", uri, strings.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -26,8 +87,11 @@ func (semp *Semp) postHTTP(uri string, _ string, body string, logName string, pa
 
 	resp, err := semp.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		semp.observeRequest(start, 0)
+		return nil, classifyTransportError(ctx, err)
 	}
+	semp.observeRequest(start, resp.StatusCode)
+	defer func() { _ = resp.Body.Close() }()
 
 	var queryDuration = time.Since(start)
 	if queryDuration > longQuery {
@@ -36,16 +100,56 @@ func (semp *Semp) postHTTP(uri string, _ string, body string, logName string, pa
 	semp.logger.Debug("Scraped "+logName, "page", page, "duration", queryDuration)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		_ = resp.Body.Close()
-		return nil, fmt.Errorf("HTTP status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+		err := &httpStatusError{status: resp.StatusCode}
+		if isTransientStatus(resp.StatusCode) {
+			return nil, &transientError{reason: statusReason(resp.StatusCode), err: err}
+		}
+		return nil, err
 	}
-	return resp.Body, nil
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, classifyTransportError(ctx, err)
+	}
+	if reason, busy := brokerBusy(content); busy {
+		return nil, &transientError{reason: "broker_busy", err: fmt.Errorf("broker busy: %s", reason), reply: content}
+	}
+	return content, nil
 }
 
-func (semp *Semp) getHTTPbytes(uri string, _ string, logName string, page int) ([]byte, error) {
+// getHTTPbytes calls http get for the supplied uri. The request is aborted when ctx is done, not sent at all while the
+// circuit breaker of the broker is open, and retried after transient failures, see RetryPolicy.
+func (semp *Semp) getHTTPbytes(ctx context.Context, uri string, _ string, logName string, page int) ([]byte, error) {
+	if err := semp.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	err := semp.withRetries(ctx, logName, page, func() (err error) {
+		body, err = semp.getHTTPbytesOnce(ctx, uri, logName, page)
+		return err
+	})
+	semp.breaker.done(ctx, err)
+	if err != nil {
+		body, err = replyOfTransientError(err)
+		if err != nil {
+			return nil, err
+		}
+	}
+	semp.observePage(len(body))
+	return body, nil
+}
+
+func (semp *Semp) getHTTPbytesOnce(ctx context.Context, uri string, logName string, page int) ([]byte, error) {
+	release, err := semp.acquireConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	start := time.Now()
 
-	req, err := http.NewRequest("GET", uri, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -56,8 +160,10 @@ func (semp *Semp) getHTTPbytes(uri string, _ string, logName string, page int) (
 
 	resp, err := semp.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		semp.observeRequest(start, 0)
+		return nil, classifyTransportError(ctx, err)
 	}
+	semp.observeRequest(start, resp.StatusCode)
 	// Always close the body: previously it was only closed on the >=500 path, leaking a connection on every
 	// successful SEMP v2 page.
 	defer func() { _ = resp.Body.Close() }()
@@ -70,13 +176,40 @@ func (semp *Semp) getHTTPbytes(uri string, _ string, logName string, page int) (
 	semp.logger.Debug("Scraped "+logName, "page", page, "duration", queryDuration)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 500 {
-		return nil, fmt.Errorf("HTTP status %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+		err := &httpStatusError{status: resp.StatusCode}
+		if isTransientStatus(resp.StatusCode) {
+			return nil, &transientError{reason: statusReason(resp.StatusCode), err: err}
+		}
+		return nil, err
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, classifyTransportError(ctx, err)
+	}
+	if isTransientStatus(resp.StatusCode) {
+		// The error detail of a 4xx reply is parsed by the caller once no retry is left.
+		return nil, &transientError{reason: statusReason(resp.StatusCode), err: &httpStatusError{status: resp.StatusCode}, reply: body}
 	}
 
 	return body, nil
 }
+
+// httpStatusError is a SEMP reply with an HTTP status the caller does not handle.
+type httpStatusError struct {
+	status int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("HTTP status %d (%s)", e.status, http.StatusText(e.status))
+}
+
+// replyOfTransientError returns the broker reply of a transient error that ran out of retries, so that the caller
+// handles it as if there were no retries. Other errors are returned as they are.
+func replyOfTransientError(err error) ([]byte, error) {
+	var transient *transientError
+	if errors.As(err, &transient) && transient.reply != nil {
+		return transient.reply, nil
+	}
+	return nil, err
+}
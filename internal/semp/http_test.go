@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -17,13 +20,48 @@ func newHTTPTestSemp(t *testing.T, status int, body string) *Semp {
 	}))
 	t.Cleanup(server.Close)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	return NewSemp(logger, server.URL, http.Client{}, nil, false, false)
+	return NewSemp(logger, server.URL, http.Client{}, nil, false, false, nil, RetryPolicy{}, nil)
+}
+
+// newSemp2TestServer returns a Semp of a broker that replies to a request with the body routes has for its request URI,
+// or else for its escaped path, with {server} replaced by the broker URI for paging links. Any other request fails with
+// 503. The returned func lists the request URIs in order, each followed by its body if it has one.
+func newSemp2TestServer(t *testing.T, routes map[string]string) (*Semp, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := r.URL.RequestURI()
+		if body, _ := io.ReadAll(r.Body); len(body) > 0 {
+			request += " " + string(body)
+		}
+		mu.Lock()
+		requests = append(requests, request)
+		mu.Unlock()
+
+		reply, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			reply, ok = routes[r.URL.EscapedPath()]
+		}
+		if !ok {
+			http.Error(w, "unexpected request", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(strings.ReplaceAll(reply, "{server}", "http://"+r.Host)))
+	}))
+	t.Cleanup(server.Close)
+	s := NewSemp(slog.New(slog.NewTextHandler(io.Discard, nil)), server.URL, http.Client{}, nil, false, false, nil, RetryPolicy{}, nil)
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(requests)
+	}
 }
 
 func TestPostHTTPSuccess(t *testing.T) {
 	t.Parallel()
 	s := newHTTPTestSemp(t, http.StatusOK, "<ok/>")
-	rc, err := s.postHTTP(s.brokerURI+"/SEMP", "application/xml", "<rpc/>", "Test", 1)
+	rc, err := s.postHTTP(t.Context(), s.brokerURI+"/SEMP", "application/xml", "<rpc/>", "Test", 1)
 	if err != nil {
 		t.Fatalf("postHTTP error: %v", err)
 	}
@@ -38,7 +76,7 @@ func TestPostHTTPStatusErrors(t *testing.T) {
 	t.Parallel()
 	for _, status := range []int{http.StatusUnauthorized, http.StatusInternalServerError} {
 		s := newHTTPTestSemp(t, status, "boom")
-		rc, err := s.postHTTP(s.brokerURI+"/SEMP", "application/xml", "<rpc/>", "Test", 1)
+		rc, err := s.postHTTP(t.Context(), s.brokerURI+"/SEMP", "application/xml", "<rpc/>", "Test", 1)
 		if err == nil {
 			_ = rc.Close()
 			t.Errorf("postHTTP status %d: expected error, got nil", status)
@@ -53,7 +91,7 @@ func TestGetHTTPbytesSuccessAndClientError(t *testing.T) {
 	t.Parallel()
 	// 200 -> body returned
 	s := newHTTPTestSemp(t, http.StatusOK, `{"ok":true}`)
-	b, err := s.getHTTPbytes(s.brokerURI, "application/json", "Test", 1)
+	b, err := s.getHTTPbytes(t.Context(), s.brokerURI, "application/json", "Test", 1)
 	if err != nil {
 		t.Fatalf("getHTTPbytes 200 error: %v", err)
 	}
@@ -63,7 +101,7 @@ func TestGetHTTPbytesSuccessAndClientError(t *testing.T) {
 
 	// 4xx -> body still returned (SEMP v2 returns error detail in a 400 body, which the caller parses)
 	s = newHTTPTestSemp(t, http.StatusBadRequest, `{"error":"bad"}`)
-	b, err = s.getHTTPbytes(s.brokerURI, "application/json", "Test", 1)
+	b, err = s.getHTTPbytes(t.Context(), s.brokerURI, "application/json", "Test", 1)
 	if err != nil {
 		t.Fatalf("getHTTPbytes 400 error: %v", err)
 	}
@@ -75,7 +113,7 @@ func TestGetHTTPbytesSuccessAndClientError(t *testing.T) {
 func TestGetHTTPbytesServerErrorReturnsError(t *testing.T) {
 	t.Parallel()
 	s := newHTTPTestSemp(t, http.StatusInternalServerError, "boom")
-	if _, err := s.getHTTPbytes(s.brokerURI, "application/json", "Test", 1); err == nil {
+	if _, err := s.getHTTPbytes(t.Context(), s.brokerURI, "application/json", "Test", 1); err == nil {
 		t.Error("getHTTPbytes 500: expected error, got nil")
 	}
 }
@@ -84,7 +122,7 @@ func TestGetHTTPbytesServerErrorReturnsError(t *testing.T) {
 func TestVisitorNilDoesNotPanic(t *testing.T) {
 	t.Parallel()
 	s := newHTTPTestSemp(t, http.StatusOK, "<ok/>") // NewSemp called with nil visitor
-	rc, err := s.postHTTP(s.brokerURI+"/SEMP", "application/xml", "<rpc/>", "Test", 1)
+	rc, err := s.postHTTP(t.Context(), s.brokerURI+"/SEMP", "application/xml", "<rpc/>", "Test", 1)
 	if err != nil {
 		t.Fatalf("postHTTP with nil visitor error: %v", err)
 	}
@@ -0,0 +1,90 @@
+package semp
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var instrumentationLabels = []string{"endpoint", "datasource", "broker"}
+
+var (
+	requestDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "solace_exporter_semp_request_duration_seconds",
+		Help:    "Duration of SEMP requests to the broker, including failed attempts and retries.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+	}, instrumentationLabels)
+	responsesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "solace_exporter_semp_responses_total",
+		Help: "SEMP requests to the broker by HTTP status class (2xx, 3xx, 4xx, 5xx), or error if no response was received.",
+	}, append(instrumentationLabels, "status_class"))
+	pagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "solace_exporter_semp_pages_total",
+		Help: "SEMP reply pages fetched from the broker.",
+	}, instrumentationLabels)
+	responseSizeBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "solace_exporter_semp_response_size_bytes",
+		Help:    "Size of the SEMP reply pages fetched from the broker.",
+		Buckets: prometheus.ExponentialBuckets(1024, 4, 8),
+	}, instrumentationLabels)
+	decodeErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "solace_exporter_semp_decode_errors_total",
+		Help: "SEMP replies that could not be decoded.",
+	}, instrumentationLabels)
+	dataSourceSeries = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "solace_exporter_datasource_series",
+		Help: "Series emitted by the last scrape of the data source.",
+	}, instrumentationLabels)
+)
+
+func init() {
+	prometheus.MustRegister(requestDurationSeconds, responsesTotal, pagesTotal, responseSizeBytes, decodeErrorsTotal, dataSourceSeries)
+}
+
+// ForDataSource returns a copy of semp that labels its self-instrumentation with endpoint and dataSource, and counts
+// the series it emits for ObserveSeries. Use one copy per scrape of a data source.
+func (semp *Semp) ForDataSource(endpoint string, dataSource string) *Semp {
+	scoped := *semp
+	scoped.endpoint = endpoint
+	scoped.dataSource = dataSource
+	scoped.series = new(atomic.Int64)
+	return &scoped
+}
+
+// ObserveSeries exports the number of series emitted through NewMetric since ForDataSource.
+func (semp *Semp) ObserveSeries() {
+	if semp.series == nil {
+		return
+	}
+	dataSourceSeries.WithLabelValues(semp.instrumentationLabels()...).Set(float64(semp.series.Load()))
+}
+
+func (semp *Semp) instrumentationLabels() []string {
+	return []string{semp.endpoint, semp.dataSource, semp.brokerURI}
+}
+
+// observeRequest records one SEMP request that was sent at start. status is 0 if no response was received.
+func (semp *Semp) observeRequest(start time.Time, status int) {
+	labels := semp.instrumentationLabels()
+	requestDurationSeconds.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+	statusClass := "error"
+	if status > 0 {
+		statusClass = strconv.Itoa(status/100) + "xx"
+	}
+	responsesTotal.WithLabelValues(append(labels, statusClass)...).Inc()
+}
+
+// observePage records a reply page handed to the caller.
+func (semp *Semp) observePage(size int) {
+	labels := semp.instrumentationLabels()
+	pagesTotal.WithLabelValues(labels...).Inc()
+	responseSizeBytes.WithLabelValues(labels...).Observe(float64(size))
+}
+
+// observeDecodeError records a reply that could not be decoded.
+func (semp *Semp) observeDecodeError() {
+	decodeErrorsTotal.WithLabelValues(semp.instrumentationLabels()...).Inc()
+}
@@ -0,0 +1,51 @@
+package semp
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestForDataSourceInstrumentsScrape(t *testing.T) {
+	t.Parallel()
+
+	reply := memoryReply(`<slot-infos></slot-infos>`)
+	s := newMemoryTestSemp(t, reply).ForDataSource("test-endpoint", "Memory")
+	labels := []string{"test-endpoint", "Memory", s.brokerURI}
+
+	ch := make(chan PrometheusMetric, 100)
+	if _, err := s.GetMemorySemp1(t.Context(), ch); err != nil {
+		t.Fatalf("GetMemorySemp1: %v", err)
+	}
+	metrics := drain(ch)
+	s.ObserveSeries()
+
+	if got := testutil.ToFloat64(pagesTotal.WithLabelValues(labels...)); got != 1 {
+		t.Errorf("pages = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(responsesTotal.WithLabelValues(append(labels, "2xx")...)); got != 1 {
+		t.Errorf("2xx responses = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(dataSourceSeries.WithLabelValues(labels...)); got != float64(len(metrics)) {
+		t.Errorf("series = %v, want %d", got, len(metrics))
+	}
+	if got := testutil.CollectAndCount(requestDurationSeconds); got == 0 {
+		t.Error("no request duration observed")
+	}
+}
+
+func TestForDataSourceCountsDecodeErrors(t *testing.T) {
+	t.Parallel()
+
+	s := newMemoryTestSemp(t, "not xml").ForDataSource("test-endpoint", "Memory")
+
+	ch := make(chan PrometheusMetric, 100)
+	if _, err := s.GetMemorySemp1(t.Context(), ch); err == nil {
+		t.Fatal("GetMemorySemp1: expected decode error, got nil")
+	}
+	drain(ch)
+
+	if got := testutil.ToFloat64(decodeErrorsTotal.WithLabelValues("test-endpoint", "Memory", s.brokerURI)); got != 1 {
+		t.Errorf("decode errors = %v, want 1", got)
+	}
+}
@@ -0,0 +1,154 @@
+package semp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+var (
+	limiterWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "solace_exporter_semp_limiter_wait_seconds",
+		Help:    "Time SEMP requests waited for a free connection and a rate limit token of the broker.",
+		Buckets: []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"broker"})
+	limiterRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "solace_exporter_semp_limiter_rejected_total",
+		Help: "SEMP requests that were not sent, because no connection or rate limit token of the broker became free in time.",
+	}, []string{"broker"})
+)
+
+func init() {
+	prometheus.MustRegister(limiterWaitSeconds, limiterRejectedTotal)
+}
+
+// Limiter bounds the SEMP requests to one broker: at most connections requests in flight, and at most
+// requestsPerSecond requests started per second. It also bounds the data sources running at once, and the message VPNs
+// that data sources fanned out across VPNs scrape at once, to connections each, see Semp.AcquireDataSource and
+// Semp.AcquireVpnScrape. All Semp instances of a broker share one Limiter, see BrokerLimiter.
+type Limiter struct {
+	brokerUse
+	brokerURI string
+	rate      *rate.Limiter
+
+	// connections, dataSources and vpnScrapes are replaced when the limits change. A request releases the semaphore it
+	// acquired.
+	connections atomic.Pointer[semaphore.Weighted]
+	dataSources atomic.Pointer[semaphore.Weighted]
+	vpnScrapes  atomic.Pointer[semaphore.Weighted]
+
+	mu                sync.Mutex
+	maxConnections    int64
+	requestsPerSecond int64
+}
+
+var limiters = brokerRegistry[*Limiter]{
+	maxIdle: brokerIdleTimeout,
+	evict: func(brokerURI string, _ *Limiter) {
+		limiterWaitSeconds.DeleteLabelValues(brokerURI)
+		limiterRejectedTotal.DeleteLabelValues(brokerURI)
+	},
+}
+
+// BrokerLimiter returns the Limiter of the broker at brokerURI, so that sync scrapes, prefetch loops and named targets
+// share one budget. connections below 1 are raised to 1; requestsPerSecond below 1 disables the rate limit. Changed
+// limits (after a config reload) apply to the Limiter in place: requests already in flight finish under the old
+// connection limit.
+func BrokerLimiter(brokerURI string, connections int64, requestsPerSecond int64) *Limiter {
+	if connections < 1 {
+		connections = 1
+	}
+	if requestsPerSecond < 0 {
+		requestsPerSecond = 0
+	}
+
+	limiter := limiters.get(brokerURI, func() *Limiter {
+		return &Limiter{brokerURI: brokerURI, rate: rate.NewLimiter(rate.Inf, 0)}
+	})
+	limiter.setLimits(connections, requestsPerSecond)
+	return limiter
+}
+
+// setLimits applies the limits, if they changed.
+func (limiter *Limiter) setLimits(connections int64, requestsPerSecond int64) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if connections != limiter.maxConnections {
+		limiter.connections.Store(semaphore.NewWeighted(connections))
+		limiter.dataSources.Store(semaphore.NewWeighted(connections))
+		limiter.vpnScrapes.Store(semaphore.NewWeighted(connections))
+		limiter.rate.SetBurst(int(connections))
+		limiter.maxConnections = connections
+	}
+	if requestsPerSecond != limiter.requestsPerSecond {
+		limit := rate.Inf
+		if requestsPerSecond > 0 {
+			limit = rate.Limit(requestsPerSecond)
+		}
+		limiter.rate.SetLimit(limit)
+		limiter.requestsPerSecond = requestsPerSecond
+	}
+}
+
+// acquire waits for a free connection and a rate limit token. On success the caller must call release once the
+// request is done. It gives up when ctx is done, or right away if the token would not be available before the
+// deadline of ctx.
+func (limiter *Limiter) acquire(ctx context.Context) (release func(), err error) {
+	if limiter == nil {
+		return func() {}, nil
+	}
+
+	limiter.touch()
+	start := time.Now()
+	connections := limiter.connections.Load()
+	if err := connections.Acquire(ctx, 1); err != nil {
+		limiterRejectedTotal.WithLabelValues(limiter.brokerURI).Inc()
+		return nil, fmt.Errorf("no free SEMP connection to the broker: %w", err)
+	}
+	if err := limiter.rate.Wait(ctx); err != nil {
+		connections.Release(1)
+		limiterRejectedTotal.WithLabelValues(limiter.brokerURI).Inc()
+		return nil, fmt.Errorf("SEMP request rate limit of the broker exceeded: %w", err)
+	}
+	limiterWaitSeconds.WithLabelValues(limiter.brokerURI).Observe(time.Since(start).Seconds())
+
+	return func() { connections.Release(1) }, nil
+}
+
+// AcquireDataSource waits until fewer data sources of the broker run at once than it has connections, of all scrapes
+// and prefetch loops. A scrape starts the goroutine of a data source only then, so a scrape of many data sources does
+// not park one goroutine per data source on the connections. On success the caller must call release once the data
+// source is collected. It gives up when ctx is done.
+func (semp *Semp) AcquireDataSource(ctx context.Context) (release func(), err error) {
+	if semp.limiter == nil {
+		return func() {}, nil
+	}
+	semp.limiter.touch()
+	dataSources := semp.limiter.dataSources.Load()
+	if err := dataSources.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	return func() { dataSources.Release(1) }, nil
+}
+
+// AcquireVpnScrape waits until the broker is scraped for fewer message VPNs at once than it has connections, by all
+// data sources fanned out across VPNs of all exporters. On success the caller must call release once the VPN is
+// scraped. It gives up when ctx is done.
+func (semp *Semp) AcquireVpnScrape(ctx context.Context) (release func(), err error) {
+	if semp.limiter == nil {
+		return func() {}, nil
+	}
+	semp.limiter.touch()
+	vpnScrapes := semp.limiter.vpnScrapes.Load()
+	if err := vpnScrapes.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	return func() { vpnScrapes.Release(1) }, nil
+}
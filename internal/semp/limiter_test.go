@@ -0,0 +1,165 @@
+package semp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestBrokerLimiterIsSharedPerBrokerAndLimits(t *testing.T) {
+	t.Parallel()
+
+	a := BrokerLimiter("http://limiter-shared:8080", 2, 10)
+	if b := BrokerLimiter("http://limiter-shared:8080", 2, 10); a != b {
+		t.Error("the same broker and limits must share one limiter")
+	}
+	if b := BrokerLimiter("http://limiter-shared:8080", 3, 20); a != b {
+		t.Error("changed limits must apply to the limiter of the broker")
+	}
+	if a.rate.Limit() != 20 || a.rate.Burst() != 3 || !a.connections.Load().TryAcquire(3) {
+		t.Errorf("limits = %v per second, burst %d, want the changed ones", a.rate.Limit(), a.rate.Burst())
+	}
+	if b := BrokerLimiter("http://limiter-other:8080", 2, 10); a == b {
+		t.Error("another broker must get its own limiter")
+	}
+}
+
+func TestLimiterCapsConcurrentRequests(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	limiter := BrokerLimiter(server.URL, 2, 0)
+
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Go(func() {
+			// Every Semp of the broker shares the limiter, like the exporters of parallel scrapes do.
+			s := NewSemp(logger, server.URL, http.Client{Timeout: 5 * time.Second}, nil, false, false, limiter, RetryPolicy{}, nil)
+			if body, err := s.postHTTP(t.Context(), server.URL+"/SEMP", "application/xml", "<rpc/>", "test", 1); err == nil {
+				_ = body.Close()
+			} else {
+				t.Errorf("postHTTP: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if got := maxInFlight.Load(); got != 2 {
+		t.Errorf("max concurrent SEMP requests = %d, want 2", got)
+	}
+}
+
+func TestLimiterRateLimitsAndRejects(t *testing.T) {
+	t.Parallel()
+
+	brokerURI := "http://limiter-rate:8080"
+	// One connection and one request per second: the bucket holds a single token.
+	limiter := BrokerLimiter(brokerURI, 1, 1)
+
+	release, err := limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	release()
+
+	// The next token is a second away, which is past the deadline: rejected right away instead of waiting.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := limiter.acquire(ctx); err == nil {
+		t.Fatal("second request within the same second must be rejected")
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("rejection took %s, want it right away", elapsed)
+	}
+	if got := testutil.ToFloat64(limiterRejectedTotal.WithLabelValues(brokerURI)); got != 1 {
+		t.Errorf("rejected requests = %v, want 1", got)
+	}
+
+	// With enough time the request gets through.
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	release, err = limiter.acquire(ctx)
+	if err != nil {
+		t.Fatalf("third request: %v", err)
+	}
+	release()
+}
+
+func TestNilLimiterDoesNotLimit(t *testing.T) {
+	t.Parallel()
+
+	var limiter *Limiter
+	release, err := limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
+
+// TestScrapeSlotsAreLimitedPerBroker checks that the Semp instances of a broker, like those of two exporters, share
+// the bounds of the data sources running at once and of the VPNs scraped at once.
+func TestScrapeSlotsAreLimitedPerBroker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		brokerURI string
+		acquire   func(semp *Semp, ctx context.Context) (func(), error)
+	}{
+		{name: "data sources", brokerURI: "http://limiter-data-sources:8080", acquire: (*Semp).AcquireDataSource},
+		{name: "vpn scrapes", brokerURI: "http://limiter-vpn-scrapes:8080", acquire: (*Semp).AcquireVpnScrape},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			newSemp := func() *Semp {
+				limiter := BrokerLimiter(tt.brokerURI, 1, 0)
+				return NewSemp(logger, tt.brokerURI, http.Client{}, nil, false, false, limiter, RetryPolicy{}, nil)
+			}
+			first, second := newSemp(), newSemp()
+
+			release, err := tt.acquire(first, t.Context())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+			defer cancel()
+			if _, err := tt.acquire(second, ctx); err == nil {
+				t.Error("a second scrape slot of the broker was acquired while its only one was taken")
+			}
+
+			release()
+			releaseSecond, err := tt.acquire(second, t.Context())
+			if err != nil {
+				t.Fatalf("acquire after release: %v", err)
+			}
+			releaseSecond()
+		})
+	}
+}
@@ -6,6 +6,8 @@ const (
 
 var (
 	variableLabelsUp                 = []string{"error", "endpoint"}
+	variableLabelsStale              = []string{"endpoint", "data_source"}
+	variableLabelsUnsupported        = []string{"endpoint", "min_version", "broker_version"}
 	variableLabelsEnvironment        = []string{"sensor_name"}
 	variableLabelsHardwareFC         = []string{"channel_number"}
 	variableLabelsHardwareLUN        = []string{"lun_number"}
@@ -27,10 +29,12 @@ var (
 		"bind_type", "bind_name", "bind_target",
 	}
 	variableLabelsVpnQueue           = []string{"vpn_name", "queue_name"}
+	variableLabelsVpnQueueOwner      = []string{"vpn_name", "queue_name", "owner"}
 	variableLabelsVpnTopicEndpoint   = []string{"vpn_name", "topic_endpoint_name"}
 	variableLabelsClusterLink        = []string{"cluster", "node_name", "remote_cluster", "remote_node_name"}
 	variableLabelsBridge             = []string{"vpn_name", "bridge_name"}
 	variableLabelsBridgeRemote       = []string{"vpn_name", "bridge_name", "remote_vpn_name", "remote_router"}
+	variableLabelsBridgeRemoteVpn    = []string{"vpn_name", "bridge_name", "remote_vpn_name", "remote_vpn_location"}
 	variableLabelsBridgeDetail       = []string{"vpn_name", "bridge_name", "connected_remote_vpn_name", "connected_remote_router", "local_queue_name"}
 	variableLabelsBridgeDetailAuth   = []string{"vpn_name", "bridge_name", "connected_remote_vpn_name", "connected_remote_router", "local_queue_name", "client_username", "certificate_file"}
 	variableLabelsBridgeDetailRemote = []string{"vpn_name", "bridge_name", "connected_remote_vpn_name", "connected_remote_router", "local_queue_name", "remote_vpn_name", "remote_router", "compressed", "ssl", "remote_queue_name"}
@@ -70,9 +74,181 @@ var QueueStats = Descriptions{
 	"messages_max_redelivered_dmq_failed": NewSemDesc("queue_msg_max_redelivered_dmq_failed", "maxRedeliveryExceededToDmqFailedMsgCount", "Queue total number of messages failed delivery to dmq due to exceeded max redelivery.", variableLabelsVpnQueue),
 }
 
+var VpnStats = Descriptions{
+	"vpn_rx_msgs_total":                NewSemDesc("vpn_rx_msgs_total", "dataRxMsgCount", "Number of received messages.", variableLabelsVpn),
+	"vpn_tx_msgs_total":                NewSemDesc("vpn_tx_msgs_total", "dataTxMsgCount", "Number of transmitted messages.", variableLabelsVpn),
+	"vpn_rx_bytes_total":               NewSemDesc("vpn_rx_bytes_total", "dataRxByteCount", "Number of received bytes.", variableLabelsVpn),
+	"vpn_tx_bytes_total":               NewSemDesc("vpn_tx_bytes_total", "dataTxByteCount", "Number of transmitted bytes.", variableLabelsVpn),
+	"vpn_rx_discarded_msgs_total":      NewSemDesc("vpn_rx_discarded_msgs_total", "discardedRxMsgCount", "Number of discarded received messages.", variableLabelsVpn),
+	"vpn_tx_discarded_msgs_total":      NewSemDesc("vpn_tx_discarded_msgs_total", "discardedTxMsgCount", "Number of discarded transmitted messages.", variableLabelsVpn),
+	"vpn_connections_service_amqp":     NewSemDesc("vpn_connections_service_amqp", "msgVpnConnectionsServiceAmqp", "Total number of amq connections", variableLabelsVpn),
+	"vpn_connections_service_mqtt":     NewSemDesc("vpn_connections_service_mqtt", "msgVpnConnectionsServiceMqtt", "Total number of mqtt connections", variableLabelsVpn),
+	"vpn_connections_service_smf":      NewSemDesc("vpn_connections_service_smf", "msgVpnConnectionsServiceSmf", "Total number of smf connections", variableLabelsVpn),
+	"vpn_connections_service_web":      NewSemDesc("vpn_connections_service_web", "msgVpnConnectionsServiceWeb", "Total number of smf-web connections", variableLabelsVpn),
+	"vpn_connections_service_rest_in":  NewSemDesc("vpn_connections_service_rest_in", "msgVpnConnectionsServiceRestIncoming", "Total number of inbound rest connections", variableLabelsVpn),
+	"vpn_connections_service_rest_out": NewSemDesc("vpn_connections_service_rest_out", "msgVpnConnectionsServiceRestOutgoing", "Total number of outbound rest connections", variableLabelsVpn),
+	"vpn_connections":                  NewSemDesc("vpn_connections", "msgVpnConnections", "Number of connections.", variableLabelsVpn),
+	"vpn_quota_connections":            NewSemDesc("vpn_quota_connections", "maxConnectionCount", "Maximum number of connections.", variableLabelsVpn),
+	"vpn_quota_connections_amqp":       NewSemDesc("vpn_quota_connections_amqp", "serviceAmqpMaxConnectionCount", "Maximum number of amqp connections.", variableLabelsVpn),
+	"vpn_quota_connections_smf":        NewSemDesc("vpn_quota_connections_smf", "serviceSmfMaxConnectionCount", "Maximum number of smf connections.", variableLabelsVpn),
+	"vpn_quota_connections_web":        NewSemDesc("vpn_quota_connections_web", "serviceWebMaxConnectionCount", "Maximum number of smf-web connections.", variableLabelsVpn),
+	"vpn_quota_connections_mqtt":       NewSemDesc("vpn_quota_connections_mqtt", "serviceMqttMaxConnectionCount", "Maximum number of mqtt connections.", variableLabelsVpn),
+	"vpn_quota_connections_rest_in":    NewSemDesc("vpn_quota_connections_rest_in", "serviceRestIncomingMaxConnectionCount", "Maximum number of inbound rest connections.", variableLabelsVpn),
+	"vpn_quota_connections_rest_out":   NewSemDesc("vpn_quota_connections_rest_out", "serviceRestOutgoingMaxConnectionCount", "Maximum number of outbound rest connections.", variableLabelsVpn),
+}
+
+var ClientStats = Descriptions{
+	"client_rx_msgs_total":           NewSemDesc("client_rx_msgs_total", "dataRxMsgCount", "Number of received messages.", variableLabelsVpnClientUser),
+	"client_tx_msgs_total":           NewSemDesc("client_tx_msgs_total", "dataTxMsgCount", "Number of transmitted messages.", variableLabelsVpnClientUser),
+	"client_rx_bytes_total":          NewSemDesc("client_rx_bytes_total", "dataRxByteCount", "Number of received bytes.", variableLabelsVpnClientUser),
+	"client_tx_bytes_total":          NewSemDesc("client_tx_bytes_total", "dataTxByteCount", "Number of transmitted bytes.", variableLabelsVpnClientUser),
+	"client_rx_discarded_msgs_total": NewSemDesc("client_rx_discarded_msgs_total", "discardedRxMsgCount", "Number of discarded received messages.", variableLabelsVpnClientUser),
+	"client_tx_discarded_msgs_total": NewSemDesc("client_tx_discarded_msgs_total", "discardedTxMsgCount", "Number of discarded transmitted messages.", variableLabelsVpnClientUser),
+	"client_slow_subscriber":         NewSemDesc("client_slow_subscriber", "slowSubscriber", "Is client a slow subscriber? (0=not slow, 1=slow).", variableLabelsClientSlowSub),
+}
+
+var QueueDetails = Descriptions{
+	"queue_spool_quota_bytes": NewSemDesc("queue_spool_quota_bytes", "maxMsgSpoolUsage", "Queue spool configured max disk usage in bytes.", variableLabelsVpnQueue),
+	"queue_spool_usage_bytes": NewSemDesc("queue_spool_usage_bytes", "msgSpoolUsage", "The size in bytes of all messages currently in the Queue.", variableLabelsVpnQueue),
+	"queue_spool_usage_msgs":  NewSemDesc("queue_spool_usage_msgs", NoSempV2Ready, "The count of all messages currently in the Queue.", variableLabelsVpnQueue),
+	"queue_binds":             NewSemDesc("queue_binds", "bindCount", "Number of clients bound to queue.", variableLabelsVpnQueue),
+	"queue_subscriptions":     NewSemDesc("queue_subscriptions", NoSempV2Ready, "Number of subscriptions of the queue.", variableLabelsVpnQueue),
+}
+
+// QueueDetailsV2 are the QueueDetails SEMP v2 has, and its attributes of the queue state and configuration.
+var QueueDetailsV2 = Descriptions{
+	"queue_spool_quota_bytes":                QueueDetails["queue_spool_quota_bytes"],
+	"queue_spool_usage_bytes":                QueueDetails["queue_spool_usage_bytes"],
+	"queue_binds":                            QueueDetails["queue_binds"],
+	"queue_binds_max":                        NewSemDesc("queue_binds_max", "maxBindCount", "Maximum number of clients that can bind to the queue.", variableLabelsVpnQueue),
+	"queue_access_type":                      NewSemDesc("queue_access_type", "accessType", "Queue access type (0-exclusive, 1-non-exclusive).", variableLabelsVpnQueue),
+	"queue_ingress_enabled":                  NewSemDesc("queue_ingress_enabled", "ingressEnabled", "Is the queue enabled for receiving messages? (0=no, 1=yes).", variableLabelsVpnQueue),
+	"queue_egress_enabled":                   NewSemDesc("queue_egress_enabled", "egressEnabled", "Is the queue enabled for delivering messages? (0=no, 1=yes).", variableLabelsVpnQueue),
+	"queue_owner_info":                       NewSemDesc("queue_owner_info", "owner", "Client username that owns the queue. Value is always 1.", variableLabelsVpnQueueOwner),
+	"queue_partitions":                       NewSemDesc("queue_partitions", "partitionCount", "Number of partitions of a partitioned queue, 0 if it is not partitioned.", variableLabelsVpnQueue),
+	"queue_consumer_ack_propagation_enabled": NewSemDesc("queue_consumer_ack_propagation_enabled", "consumerAckPropagationEnabled", "Are consumer acknowledgements propagated to the replication mate? (0=no, 1=yes).", variableLabelsVpnQueue),
+	"queue_redelivery_enabled":               NewSemDesc("queue_redelivery_enabled", "redeliveryEnabled", "Are messages redelivered after a consumer failed to acknowledge them? (0=no, 1=yes).", variableLabelsVpnQueue),
+	"queue_tx_flows":                         NewSemDesc("queue_tx_flows", "txFlows", "Number of egress flows (consumers) of the queue.", variableLabelsVpnQueue),
+	"queue_rx_flows":                         NewSemDesc("queue_rx_flows", "rxFlows", "Number of ingress flows (publishers) of the queue.", variableLabelsVpnQueue),
+}
+
+var TopicEndpointStats = Descriptions{
+	"total_bytes_spooled":                 NewSemDesc("topic_endpoint_byte_spooled", "spooledByteCount", "Topic Endpoint spool total of all spooled messages in bytes.", variableLabelsVpnTopicEndpoint),
+	"total_messages_spooled":              NewSemDesc("topic_endpoint_msg_spooled", "spooledMsgCount", "Topic Endpoint spool total of all spooled messages.", variableLabelsVpnTopicEndpoint),
+	"messages_redelivered":                NewSemDesc("topic_endpoint_msg_redelivered", "redeliveredMsgCount", "Topic Endpoint total msg redeliveries.", variableLabelsVpnTopicEndpoint),
+	"messages_transport_retransmitted":    NewSemDesc("topic_endpoint_msg_retransmitted", "transportRetransmitMsgCount", "Topic Endpoint total msg retransmitted on transport.", variableLabelsVpnTopicEndpoint),
+	"spool_usage_exceeded":                NewSemDesc("topic_endpoint_msg_spool_usage_exceeded", "maxMsgSpoolUsageExceededDiscardedMsgCount", "Topic Endpoint total number of messages exceeded the spool usage.", variableLabelsVpnTopicEndpoint),
+	"max_message_size_exceeded":           NewSemDesc("topic_endpoint_msg_max_msg_size_exceeded", "maxMsgSizeExceededDiscardedMsgCount", "Topic Endpoint total number of messages exceeded the max message size.", variableLabelsVpnTopicEndpoint),
+	"total_deleted_messages":              NewSemDesc("topic_endpoint_msg_total_deleted", "deletedMsgCount", "Topic Endpoint total number that was deleted.", variableLabelsVpnTopicEndpoint),
+	"messages_shutdown_discarded":         NewSemDesc("topic_endpoint_msg_shutdown_discarded", "disabledDiscardedMsgCount", "Topic Endpoint total number of messages discarded due to spool shutdown.", variableLabelsVpnTopicEndpoint),
+	"messages_ttl_discarded":              NewSemDesc("topic_endpoint_msg_ttl_discarded", "maxTtlExpiredDiscardedMsgCount", "Topic Endpoint total number of messages discarded due to ttl expiry.", variableLabelsVpnTopicEndpoint),
+	"messages_ttl_dmq":                    NewSemDesc("topic_endpoint_msg_ttl_dmq", "maxTtlExpiredToDmqMsgCount", "Topic Endpoint total number of messages delivered to dmq due to ttl expiry.", variableLabelsVpnTopicEndpoint),
+	"messages_ttl_dmq_failed":             NewSemDesc("topic_endpoint_msg_ttl_dmq_failed", "maxTtlExpiredToDmqFailedMsgCount", "Topic Endpoint total number of messages that failed delivery to dmq due to ttl expiry.", variableLabelsVpnTopicEndpoint),
+	"messages_max_redelivered_discarded":  NewSemDesc("topic_endpoint_msg_max_redelivered_discarded", "maxRedeliveryExceededDiscardedMsgCount", "Topic Endpoint total number of messages discarded due to exceeded max redelivery.", variableLabelsVpnTopicEndpoint),
+	"messages_max_redelivered_dmq":        NewSemDesc("topic_endpoint_msg_max_redelivered_dmq", "maxRedeliveryExceededToDmqMsgCount", "Topic Endpoint total number of messages delivered to dmq due to exceeded max redelivery.", variableLabelsVpnTopicEndpoint),
+	"messages_max_redelivered_dmq_failed": NewSemDesc("topic_endpoint_msg_max_redelivered_dmq_failed", "maxRedeliveryExceededToDmqFailedMsgCount", "Topic Endpoint total number of messages failed delivery to dmq due to exceeded max redelivery.", variableLabelsVpnTopicEndpoint),
+}
+
+var MqttSession = Descriptions{
+	"mqtt_session_info":           NewSemDesc("mqtt_session_info", "enabled", "Static information and flags regarding the MQTT session. Value is always 1.", variableLabelsMqttSessionInfo),
+	"mqtt_session_subscriptions":  NewSemDesc("mqtt_session_subscriptions", "subscriptions", "Number of subscriptions for the MQTT session.", variableLabelsMqttSession),
+	"mqtt_session_uptime_seconds": NewSemDesc("mqtt_session_uptime_seconds", NoSempV2Ready, "Uptime of the MQTT session in seconds.", variableLabelsMqttSession),
+}
+
+// MqttSessionV2 are the MqttSession SEMP v2 has, and the number of messages queued for the session.
+var MqttSessionV2 = Descriptions{
+	"mqtt_session_info":          MqttSession["mqtt_session_info"],
+	"mqtt_session_subscriptions": MqttSession["mqtt_session_subscriptions"],
+	"mqtt_session_queued_msgs":   NewSemDesc("mqtt_session_queued_msgs", "queueMsgs", "Number of messages in the queue of the MQTT session.", variableLabelsMqttSession),
+}
+
+var Bridge = Descriptions{
+	"bridges_num_total_bridges":                         NewSemDesc("bridges_num_total_bridges", NoSempV2Ready, "Number of Bridges", nil),
+	"bridges_max_num_total_bridges":                     NewSemDesc("bridges_max_num_total_bridges", NoSempV2Ready, "Max number of Bridges", nil),
+	"bridges_num_local_bridges":                         NewSemDesc("bridges_num_local_bridges", NoSempV2Ready, "Number of Local Bridges", nil),
+	"bridges_max_num_local_bridges":                     NewSemDesc("bridges_max_num_local_bridges", NoSempV2Ready, "Max number of Local Bridges", nil),
+	"bridges_num_remote_bridges":                        NewSemDesc("bridges_num_remote_bridges", NoSempV2Ready, "Number of Remote Bridges", nil),
+	"bridges_max_num_remote_bridges":                    NewSemDesc("bridges_max_num_remote_bridges", NoSempV2Ready, "Max number of Remote Bridges", nil),
+	"bridges_num_total_remote_bridge_subscriptions":     NewSemDesc("bridges_num_total_remote_bridge_subscriptions", NoSempV2Ready, "Total number of Remote Bridge Subscription", nil),
+	"bridges_max_num_total_remote_bridge_subscriptions": NewSemDesc("bridges_max_num_total_remote_bridge_subscriptions", NoSempV2Ready, "Max total number of Remote Bridge Subscription", nil),
+	"bridge_admin_state":                                NewSemDesc("bridge_admin_state", "enabled", "Bridge Administrative State (0-Enabled 1-Disabled, 2--)", variableLabelsBridge),
+	"bridge_connection_establisher":                     NewSemDesc("bridge_connection_establisher", "establisher", "Connection Establisher (0-NotApplicable, 1-Local, 2-Remote, 3-Invalid)", variableLabelsBridge),
+	"bridge_inbound_operational_state":                  NewSemDesc("bridge_inbound_operational_state", "inboundState", "Inbound Ops State (0-Init, 1-Shutdown, 2-NoShutdown, 3-Prepare, 4-Prepare-WaitToConnect, 5-Prepare-FetchingDNS, 6-NotReady, 7-NotReady-Connecting, 8-NotReady-Handshaking, 9-NotReady-WaitNext, 10-NotReady-WaitReuse, 11-NotRead-WaitBridgeVersionMismatch, 12-NotReady-WaitCleanup, 13-Ready, 14-Ready-Subscribing, 15-Ready-InSync, 16-NotApplicable, 17-Invalid)", variableLabelsBridge),
+	"bridge_inbound_operational_failure_reason":         NewSemDesc("bridge_inbound_operational_failure_reason", NoSempV2Ready, "Inbound Ops Failure Reason (various very long codes)", variableLabelsBridge),
+	"bridge_outbound_operational_state":                 NewSemDesc("bridge_outbound_operational_state", "outboundState", "Outbound Ops State (0-Init, 1-Shutdown, 2-NoShutdown, 3-Prepare, 4-Prepare-WaitToConnect, 5-Prepare-FetchingDNS, 6-NotReady, 7-NotReady-Connecting, 8-NotReady-Handshaking, 9-NotReady-WaitNext, 10-NotReady-WaitReuse, 11-NotRead-WaitBridgeVersionMismatch, 12-NotReady-WaitCleanup, 13-Ready, 14-Ready-Subscribing, 15-Ready-InSync, 16-NotApplicable, 17-Invalid)", variableLabelsBridge),
+	"bridge_queue_operational_state":                    NewSemDesc("bridge_queue_operational_state", "boundToQueue", "Queue Ops State (0-NotApplicable, 1-Bound, 2-Unbound)", variableLabelsBridge),
+	"bridge_redundancy":                                 NewSemDesc("bridge_redundancy", NoSempV2Ready, "Bridge Redundancy (0-NotApplicable, 1-auto, 2-primary, 3-backup, 4-static, 5-none)", variableLabelsBridge),
+	"bridge_connection_uptime_in_seconds":               NewSemDesc("bridge_connection_uptime_in_seconds", "uptime", "Connection Uptime (s)", variableLabelsBridge),
+}
+
+var BridgeStats = Descriptions{
+	"bridge_client_num_subscriptions":               NewSemDesc("bridge_client_num_subscriptions", NoSempV2Ready, "Bridge Client Subscription", variableLabelsBridgeStats),
+	"bridge_client_slow_subscriber":                 NewSemDesc("bridge_client_slow_subscriber", NoSempV2Ready, "Bridge Slow Subscriber", variableLabelsBridgeStats),
+	"bridge_total_client_messages_received":         NewSemDesc("bridge_total_client_messages_received", "rxMsgCount", "Bridge Total Client Messages Received", variableLabelsBridgeStats),
+	"bridge_total_client_messages_sent":             NewSemDesc("bridge_total_client_messages_sent", "txMsgCount", "Bridge Total Client Messages sent", variableLabelsBridgeStats),
+	"bridge_client_data_messages_received":          NewSemDesc("bridge_client_data_messages_received", "dataRxMsgCount", "Bridge Client Data Msgs Received", variableLabelsBridgeStats),
+	"bridge_client_data_messages_sent":              NewSemDesc("bridge_client_data_messages_sent", "dataTxMsgCount", "Bridge Client Data Msgs Sent", variableLabelsBridgeStats),
+	"bridge_client_persistent_messages_received":    NewSemDesc("bridge_client_persistent_messages_received", NoSempV2Ready, "Bridge Client Persistent Msgs Received", variableLabelsBridgeStats),
+	"bridge_client_persistent_messages_sent":        NewSemDesc("bridge_client_persistent_messages_sent", NoSempV2Ready, "Bridge Client Persistent Msgs Sent", variableLabelsBridgeStats),
+	"bridge_client_nonpersistent_messages_received": NewSemDesc("bridge_client_nonpersistent_messages_received", NoSempV2Ready, "Bridge Client Non-Persistent Msgs Received", variableLabelsBridgeStats),
+	"bridge_client_nonpersistent_messages_sent":     NewSemDesc("bridge_client_nonpersistent_messages_sent", NoSempV2Ready, "Bridge Client Non-Persistent Msgs Sent", variableLabelsBridgeStats),
+	"bridge_client_direct_messages_received":        NewSemDesc("bridge_client_direct_messages_received", NoSempV2Ready, "Bridge Client Direct Msgs Received", variableLabelsBridgeStats),
+	"bridge_client_direct_messages_sent":            NewSemDesc("bridge_client_direct_messages_sent", NoSempV2Ready, "Bridge Client Direct Msgs Sent", variableLabelsBridgeStats),
+	"bridge_total_client_bytes_received":            NewSemDesc("bridge_total_client_bytes_received", "rxByteCount", "Bridge Total Client Bytes Received", variableLabelsBridgeStats),
+	"bridge_total_client_bytes_sent":                NewSemDesc("bridge_total_client_bytes_sent", "txByteCount", "Bridge Total Client Bytes sent", variableLabelsBridgeStats),
+	"bridge_client_data_bytes_received":             NewSemDesc("bridge_client_data_bytes_received", "dataRxByteCount", "Bridge Client Data Bytes Received", variableLabelsBridgeStats),
+	"bridge_client_data_bytes_sent":                 NewSemDesc("bridge_client_data_bytes_sent", "dataTxByteCount", "Bridge Client Data Bytes Sent", variableLabelsBridgeStats),
+	"bridge_client_persistent_bytes_received":       NewSemDesc("bridge_client_persistent_bytes_received", NoSempV2Ready, "Bridge Client Persistent Bytes Received", variableLabelsBridgeStats),
+	"bridge_client_persistent_bytes_sent":           NewSemDesc("bridge_client_persistent_bytes_sent", NoSempV2Ready, "Bridge Client Persistent Bytes Sent", variableLabelsBridgeStats),
+	"bridge_client_nonpersistent_bytes_received":    NewSemDesc("bridge_client_nonpersistent_bytes_received", NoSempV2Ready, "Bridge Client Non-Persistent Bytes Received", variableLabelsBridgeStats),
+	"bridge_client_nonpersistent_bytes_sent":        NewSemDesc("bridge_client_nonpersistent_bytes_sent", NoSempV2Ready, "Bridge Client Non-Persistent Bytes Sent", variableLabelsBridgeStats),
+	"bridge_client_direct_bytes_received":           NewSemDesc("bridge_client_direct_bytes_received", NoSempV2Ready, "Bridge Client Direct Bytes Received", variableLabelsBridgeStats),
+	"bridge_client_direct_bytes_sent":               NewSemDesc("bridge_client_direct_bytes_sent", NoSempV2Ready, "Bridge Client Direct Bytes Sent", variableLabelsBridgeStats),
+	"bridge_client_large_messages_received":         NewSemDesc("bridge_client_large_messages_received", NoSempV2Ready, "Bridge Client Large Messages received", variableLabelsBridgeStats),
+	"bridge_denied_duplicate_clients":               NewSemDesc("bridge_denied_duplicate_clients", NoSempV2Ready, "Bridge Denied Duplicate Clients", variableLabelsBridgeStats),
+	"bridge_not_enough_space_msgs_sent":             NewSemDesc("bridge_not_enough_space_msgs_sent", NoSempV2Ready, "Bridge Not Enough Space Messages Sent", variableLabelsBridgeStats),
+	"bridge_max_exceeded_msgs_sent":                 NewSemDesc("bridge_max_exceeded_msgs_sent", NoSempV2Ready, "Bridge Max Exceeded Messages Sent", variableLabelsBridgeStats),
+	"bridge_subscribe_client_not_found":             NewSemDesc("bridge_subscribe_client_not_found", NoSempV2Ready, "Bridge Subscriber Client Not Found", variableLabelsBridgeStats),
+	"bridge_not_found_msgs_sent":                    NewSemDesc("bridge_not_found_msgs_sent", NoSempV2Ready, "Bridge Not Found Messages Sent", variableLabelsBridgeStats),
+	"bridge_current_ingress_rate_per_second":        NewSemDesc("bridge_current_ingress_rate_per_second", "rxMsgRate", "Current Ingress Rate / s", variableLabelsBridgeStats),
+	"bridge_current_egress_rate_per_second":         NewSemDesc("bridge_current_egress_rate_per_second", "txMsgRate", "Current Egress Rate / s", variableLabelsBridgeStats),
+	"bridge_total_ingress_discards":                 NewSemDesc("bridge_total_ingress_discards", "discardedRxMsgCount", "Total Ingress Discards", variableLabelsBridgeStats),
+	"bridge_total_egress_discards":                  NewSemDesc("bridge_total_egress_discards", "discardedTxMsgCount", "Total Egress Discards", variableLabelsBridgeStats),
+}
+
+// BridgeV2 are the Bridge and BridgeStats SEMP v2 has, the connected remote router, the remote subscription count and
+// the state of the remote message VPNs of a bridge.
+var BridgeV2 = Descriptions{
+	"bridge_admin_state":                     Bridge["bridge_admin_state"],
+	"bridge_connection_establisher":          Bridge["bridge_connection_establisher"],
+	"bridge_inbound_operational_state":       Bridge["bridge_inbound_operational_state"],
+	"bridge_outbound_operational_state":      Bridge["bridge_outbound_operational_state"],
+	"bridge_queue_operational_state":         Bridge["bridge_queue_operational_state"],
+	"bridge_connection_uptime_in_seconds":    Bridge["bridge_connection_uptime_in_seconds"],
+	"bridge_connected_remote_info":           NewSemDesc("bridge_connected_remote_info", "remoteRouterName", "Remote router and message VPN the bridge is connected to. Value is always 1.", variableLabelsBridgeRemote),
+	"bridge_remote_subscriptions":            NewSemDesc("bridge_remote_subscriptions", "remoteSubscriptions", "Number of remote subscriptions of the bridge.", variableLabelsBridge),
+	"bridge_remote_vpn_enabled":              NewSemDesc("bridge_remote_vpn_enabled", subCollectionField("remoteMsgVpns", "enabled"), "Is the remote message VPN of the bridge enabled? (0=no, 1=yes).", variableLabelsBridgeRemoteVpn),
+	"bridge_remote_vpn_up":                   NewSemDesc("bridge_remote_vpn_up", subCollectionField("remoteMsgVpns", "up"), "Is the connection to the remote message VPN up? (0=down, 1=up).", variableLabelsBridgeRemoteVpn),
+	"bridge_remote_vpn_queue_bound":          NewSemDesc("bridge_remote_vpn_queue_bound", subCollectionField("remoteMsgVpns", "boundToQueue"), "Is the bridge bound to the queue of the remote message VPN? (0=unbound, 1=bound).", variableLabelsBridgeRemoteVpn),
+	"bridge_total_client_messages_received":  BridgeStats["bridge_total_client_messages_received"],
+	"bridge_total_client_messages_sent":      BridgeStats["bridge_total_client_messages_sent"],
+	"bridge_client_data_messages_received":   BridgeStats["bridge_client_data_messages_received"],
+	"bridge_client_data_messages_sent":       BridgeStats["bridge_client_data_messages_sent"],
+	"bridge_total_client_bytes_received":     BridgeStats["bridge_total_client_bytes_received"],
+	"bridge_total_client_bytes_sent":         BridgeStats["bridge_total_client_bytes_sent"],
+	"bridge_client_data_bytes_received":      BridgeStats["bridge_client_data_bytes_received"],
+	"bridge_client_data_bytes_sent":          BridgeStats["bridge_client_data_bytes_sent"],
+	"bridge_current_ingress_rate_per_second": BridgeStats["bridge_current_ingress_rate_per_second"],
+	"bridge_current_egress_rate_per_second":  BridgeStats["bridge_current_egress_rate_per_second"],
+	"bridge_total_ingress_discards":          BridgeStats["bridge_total_ingress_discards"],
+	"bridge_total_egress_discards":           BridgeStats["bridge_total_egress_discards"],
+}
+
 var MetricDesc = map[string]Descriptions{
 	"Global": {
-		"up": NewSemDesc("up", NoSempV2Ready, "Was the last scrape of Solace broker successful.", variableLabelsUp),
+		"up":           NewSemDesc("up", NoSempV2Ready, "Was the last scrape of Solace broker successful.", variableLabelsUp),
+		"stale_series": NewSemDesc("stale_series", NoSempV2Ready, "Series of a prefetched data source retained from an earlier fetch, because its last fetches failed.", variableLabelsStale),
+		"unsupported":  NewSemDesc("datasource_unsupported", NoSempV2Ready, "The data source was skipped, because the broker is older than the SolOS version it needs.", variableLabelsUnsupported),
 	},
 	"Alarm": {
 		"system_alarm": NewSemDesc("system_alarm", NoSempV2Ready, "A system alarm has been triggered 0 = false, 1 = true", nil),
@@ -424,24 +600,8 @@ var MetricDesc = map[string]Descriptions{
 		"configsync_table_ownership":          NewSemDesc("configsync_table_ownership", NoSempV2Ready, "Config Sync Ownership (0-Master, 1-Slave, 2-Unknown)", variableLabelsConfigSyncTable),
 		"configsync_table_syncstate":          NewSemDesc("configsync_table_syncstate", NoSempV2Ready, "Config Sync State (0-Down, 1-Up, 2-Unknown, 3-In-Sync, 4-Reconciling, 5-Blocked, 6-Out-Of-Sync)", variableLabelsConfigSyncTable),
 	},
-	"Bridge": {
-		"bridges_num_total_bridges":                         NewSemDesc("bridges_num_total_bridges", NoSempV2Ready, "Number of Bridges", nil),
-		"bridges_max_num_total_bridges":                     NewSemDesc("bridges_max_num_total_bridges", NoSempV2Ready, "Max number of Bridges", nil),
-		"bridges_num_local_bridges":                         NewSemDesc("bridges_num_local_bridges", NoSempV2Ready, "Number of Local Bridges", nil),
-		"bridges_max_num_local_bridges":                     NewSemDesc("bridges_max_num_local_bridges", NoSempV2Ready, "Max number of Local Bridges", nil),
-		"bridges_num_remote_bridges":                        NewSemDesc("bridges_num_remote_bridges", NoSempV2Ready, "Number of Remote Bridges", nil),
-		"bridges_max_num_remote_bridges":                    NewSemDesc("bridges_max_num_remote_bridges", NoSempV2Ready, "Max number of Remote Bridges", nil),
-		"bridges_num_total_remote_bridge_subscriptions":     NewSemDesc("bridges_num_total_remote_bridge_subscriptions", NoSempV2Ready, "Total number of Remote Bridge Subscription", nil),
-		"bridges_max_num_total_remote_bridge_subscriptions": NewSemDesc("bridges_max_num_total_remote_bridge_subscriptions", NoSempV2Ready, "Max total number of Remote Bridge Subscription", nil),
-		"bridge_admin_state":                                NewSemDesc("bridge_admin_state", NoSempV2Ready, "Bridge Administrative State (0-Enabled 1-Disabled, 2--)", variableLabelsBridge),
-		"bridge_connection_establisher":                     NewSemDesc("bridge_connection_establisher", NoSempV2Ready, "Connection Establisher (0-NotApplicable, 1-Local, 2-Remote, 3-Invalid)", variableLabelsBridge),
-		"bridge_inbound_operational_state":                  NewSemDesc("bridge_inbound_operational_state", NoSempV2Ready, "Inbound Ops State (0-Init, 1-Shutdown, 2-NoShutdown, 3-Prepare, 4-Prepare-WaitToConnect, 5-Prepare-FetchingDNS, 6-NotReady, 7-NotReady-Connecting, 8-NotReady-Handshaking, 9-NotReady-WaitNext, 10-NotReady-WaitReuse, 11-NotRead-WaitBridgeVersionMismatch, 12-NotReady-WaitCleanup, 13-Ready, 14-Ready-Subscribing, 15-Ready-InSync, 16-NotApplicable, 17-Invalid)", variableLabelsBridge),
-		"bridge_inbound_operational_failure_reason":         NewSemDesc("bridge_inbound_operational_failure_reason", NoSempV2Ready, "Inbound Ops Failure Reason (various very long codes)", variableLabelsBridge),
-		"bridge_outbound_operational_state":                 NewSemDesc("bridge_outbound_operational_state", NoSempV2Ready, "Outbound Ops State (0-Init, 1-Shutdown, 2-NoShutdown, 3-Prepare, 4-Prepare-WaitToConnect, 5-Prepare-FetchingDNS, 6-NotReady, 7-NotReady-Connecting, 8-NotReady-Handshaking, 9-NotReady-WaitNext, 10-NotReady-WaitReuse, 11-NotRead-WaitBridgeVersionMismatch, 12-NotReady-WaitCleanup, 13-Ready, 14-Ready-Subscribing, 15-Ready-InSync, 16-NotApplicable, 17-Invalid)", variableLabelsBridge),
-		"bridge_queue_operational_state":                    NewSemDesc("bridge_queue_operational_state", NoSempV2Ready, "Queue Ops State (0-NotApplicable, 1-Bound, 2-Unbound)", variableLabelsBridge),
-		"bridge_redundancy":                                 NewSemDesc("bridge_redundancy", NoSempV2Ready, "Bridge Redundancy (0-NotApplicable, 1-auto, 2-primary, 3-backup, 4-static, 5-none)", variableLabelsBridge),
-		"bridge_connection_uptime_in_seconds":               NewSemDesc("bridge_connection_uptime_in_seconds", NoSempV2Ready, "Connection Uptime (s)", variableLabelsBridge),
-	},
+	"Bridge":   Bridge,
+	"BridgeV2": BridgeV2,
 	"BridgeRemote": {
 		"bridge_remote_admin_state":                        NewSemDesc("bridge_remote_admin_state", NoSempV2Ready, "Bridge Administrative State (0-Enabled 1-Disabled, 2--, 3-N/A)", variableLabelsBridgeRemote),
 		"bridge_remote_connection_establisher":             NewSemDesc("bridge_remote_connection_establisher", NoSempV2Ready, "Connection Establisher (0-NotApplicable, 1-Local, 2-Remote, 3-Invalid)", variableLabelsBridgeRemote),
@@ -505,15 +665,8 @@ var MetricDesc = map[string]Descriptions{
 	"ClientSlowSubscriber": {
 		"client_slow_subscriber": NewSemDesc("client_slow_subscriber", NoSempV2Ready, "Is client a slow subscriber? (0=not slow, 1=slow).", variableLabelsClientSlowSub),
 	},
-	"ClientStats": {
-		"client_rx_msgs_total":           NewSemDesc("client_rx_msgs_total", NoSempV2Ready, "Number of received messages.", variableLabelsVpnClientUser),
-		"client_tx_msgs_total":           NewSemDesc("client_tx_msgs_total", NoSempV2Ready, "Number of transmitted messages.", variableLabelsVpnClientUser),
-		"client_rx_bytes_total":          NewSemDesc("client_rx_bytes_total", NoSempV2Ready, "Number of received bytes.", variableLabelsVpnClientUser),
-		"client_tx_bytes_total":          NewSemDesc("client_tx_bytes_total", NoSempV2Ready, "Number of transmitted bytes.", variableLabelsVpnClientUser),
-		"client_rx_discarded_msgs_total": NewSemDesc("client_rx_discarded_msgs_total", NoSempV2Ready, "Number of discarded received messages.", variableLabelsVpnClientUser),
-		"client_tx_discarded_msgs_total": NewSemDesc("client_tx_discarded_msgs_total", NoSempV2Ready, "Number of discarded transmitted messages.", variableLabelsVpnClientUser),
-		"client_slow_subscriber":         NewSemDesc("client_slow_subscriber", NoSempV2Ready, "Is client a slow subscriber? (0=not slow, 1=slow).", variableLabelsClientSlowSub),
-	},
+	"ClientStats":   ClientStats,
+	"ClientStatsV2": ClientStats,
 	"ClientMessageSpoolStats": {
 		"client_flows_ingress": NewSemDesc("client_flows_ingress", NoSempV2Ready, "Number of ingress flows, created/openend by this client.", variableLabelsVpnClientDetail),
 		"client_flows_egress":  NewSemDesc("client_flows_egress", NoSempV2Ready, "Number of egress flows, created/openend by this client.", variableLabelsVpnClientDetail),
@@ -549,62 +702,9 @@ var MetricDesc = map[string]Descriptions{
 			variableLabelsVpnClientEndpointBind,
 		),
 	},
-	"VpnStats": {
-		"vpn_rx_msgs_total":                NewSemDesc("vpn_rx_msgs_total", NoSempV2Ready, "Number of received messages.", variableLabelsVpn),
-		"vpn_tx_msgs_total":                NewSemDesc("vpn_tx_msgs_total", NoSempV2Ready, "Number of transmitted messages.", variableLabelsVpn),
-		"vpn_rx_bytes_total":               NewSemDesc("vpn_rx_bytes_total", NoSempV2Ready, "Number of received bytes.", variableLabelsVpn),
-		"vpn_tx_bytes_total":               NewSemDesc("vpn_tx_bytes_total", NoSempV2Ready, "Number of transmitted bytes.", variableLabelsVpn),
-		"vpn_rx_discarded_msgs_total":      NewSemDesc("vpn_rx_discarded_msgs_total", NoSempV2Ready, "Number of discarded received messages.", variableLabelsVpn),
-		"vpn_tx_discarded_msgs_total":      NewSemDesc("vpn_tx_discarded_msgs_total", NoSempV2Ready, "Number of discarded transmitted messages.", variableLabelsVpn),
-		"vpn_connections_service_amqp":     NewSemDesc("vpn_connections_service_amqp", NoSempV2Ready, "Total number of amq connections", variableLabelsVpn),
-		"vpn_connections_service_mqtt":     NewSemDesc("vpn_connections_service_mqtt", NoSempV2Ready, "Total number of mqtt connections", variableLabelsVpn),
-		"vpn_connections_service_smf":      NewSemDesc("vpn_connections_service_smf", NoSempV2Ready, "Total number of smf connections", variableLabelsVpn),
-		"vpn_connections_service_web":      NewSemDesc("vpn_connections_service_web", NoSempV2Ready, "Total number of smf-web connections", variableLabelsVpn),
-		"vpn_connections_service_rest_in":  NewSemDesc("vpn_connections_service_rest_in", NoSempV2Ready, "Total number of inbound rest connections", variableLabelsVpn),
-		"vpn_connections_service_rest_out": NewSemDesc("vpn_connections_service_rest_out", NoSempV2Ready, "Total number of outbound rest connections", variableLabelsVpn),
-		"vpn_connections":                  NewSemDesc("vpn_connections", NoSempV2Ready, "Number of connections.", variableLabelsVpn),
-		"vpn_quota_connections":            NewSemDesc("vpn_quota_connections", NoSempV2Ready, "Maximum number of connections.", variableLabelsVpn),
-		"vpn_quota_connections_amqp":       NewSemDesc("vpn_quota_connections_amqp", NoSempV2Ready, "Maximum number of amqp connections.", variableLabelsVpn),
-		"vpn_quota_connections_smf":        NewSemDesc("vpn_quota_connections_smf", NoSempV2Ready, "Maximum number of smf connections.", variableLabelsVpn),
-		"vpn_quota_connections_web":        NewSemDesc("vpn_quota_connections_web", NoSempV2Ready, "Maximum number of smf-web connections.", variableLabelsVpn),
-		"vpn_quota_connections_mqtt":       NewSemDesc("vpn_quota_connections_mqtt", NoSempV2Ready, "Maximum number of mqtt connections.", variableLabelsVpn),
-		"vpn_quota_connections_rest_in":    NewSemDesc("vpn_quota_connections_rest_in", NoSempV2Ready, "Maximum number of inbound rest connections.", variableLabelsVpn),
-		"vpn_quota_connections_rest_out":   NewSemDesc("vpn_quota_connections_rest_out", NoSempV2Ready, "Maximum number of outbound rest connections.", variableLabelsVpn),
-	},
-	"BridgeStats": {
-		"bridge_client_num_subscriptions":               NewSemDesc("bridge_client_num_subscriptions", NoSempV2Ready, "Bridge Client Subscription", variableLabelsBridgeStats),
-		"bridge_client_slow_subscriber":                 NewSemDesc("bridge_client_slow_subscriber", NoSempV2Ready, "Bridge Slow Subscriber", variableLabelsBridgeStats),
-		"bridge_total_client_messages_received":         NewSemDesc("bridge_total_client_messages_received", NoSempV2Ready, "Bridge Total Client Messages Received", variableLabelsBridgeStats),
-		"bridge_total_client_messages_sent":             NewSemDesc("bridge_total_client_messages_sent", NoSempV2Ready, "Bridge Total Client Messages sent", variableLabelsBridgeStats),
-		"bridge_client_data_messages_received":          NewSemDesc("bridge_client_data_messages_received", NoSempV2Ready, "Bridge Client Data Msgs Received", variableLabelsBridgeStats),
-		"bridge_client_data_messages_sent":              NewSemDesc("bridge_client_data_messages_sent", NoSempV2Ready, "Bridge Client Data Msgs Sent", variableLabelsBridgeStats),
-		"bridge_client_persistent_messages_received":    NewSemDesc("bridge_client_persistent_messages_received", NoSempV2Ready, "Bridge Client Persistent Msgs Received", variableLabelsBridgeStats),
-		"bridge_client_persistent_messages_sent":        NewSemDesc("bridge_client_persistent_messages_sent", NoSempV2Ready, "Bridge Client Persistent Msgs Sent", variableLabelsBridgeStats),
-		"bridge_client_nonpersistent_messages_received": NewSemDesc("bridge_client_nonpersistent_messages_received", NoSempV2Ready, "Bridge Client Non-Persistent Msgs Received", variableLabelsBridgeStats),
-		"bridge_client_nonpersistent_messages_sent":     NewSemDesc("bridge_client_nonpersistent_messages_sent", NoSempV2Ready, "Bridge Client Non-Persistent Msgs Sent", variableLabelsBridgeStats),
-		"bridge_client_direct_messages_received":        NewSemDesc("bridge_client_direct_messages_received", NoSempV2Ready, "Bridge Client Direct Msgs Received", variableLabelsBridgeStats),
-		"bridge_client_direct_messages_sent":            NewSemDesc("bridge_client_direct_messages_sent", NoSempV2Ready, "Bridge Client Direct Msgs Sent", variableLabelsBridgeStats),
-		"bridge_total_client_bytes_received":            NewSemDesc("bridge_total_client_bytes_received", NoSempV2Ready, "Bridge Total Client Bytes Received", variableLabelsBridgeStats),
-		"bridge_total_client_bytes_sent":                NewSemDesc("bridge_total_client_bytes_sent", NoSempV2Ready, "Bridge Total Client Bytes sent", variableLabelsBridgeStats),
-		"bridge_client_data_bytes_received":             NewSemDesc("bridge_client_data_bytes_received", NoSempV2Ready, "Bridge Client Data Bytes Received", variableLabelsBridgeStats),
-		"bridge_client_data_bytes_sent":                 NewSemDesc("bridge_client_data_bytes_sent", NoSempV2Ready, "Bridge Client Data Bytes Sent", variableLabelsBridgeStats),
-		"bridge_client_persistent_bytes_received":       NewSemDesc("bridge_client_persistent_bytes_received", NoSempV2Ready, "Bridge Client Persistent Bytes Received", variableLabelsBridgeStats),
-		"bridge_client_persistent_bytes_sent":           NewSemDesc("bridge_client_persistent_bytes_sent", NoSempV2Ready, "Bridge Client Persistent Bytes Sent", variableLabelsBridgeStats),
-		"bridge_client_nonpersistent_bytes_received":    NewSemDesc("bridge_client_nonpersistent_bytes_received", NoSempV2Ready, "Bridge Client Non-Persistent Bytes Received", variableLabelsBridgeStats),
-		"bridge_client_nonpersistent_bytes_sent":        NewSemDesc("bridge_client_nonpersistent_bytes_sent", NoSempV2Ready, "Bridge Client Non-Persistent Bytes Sent", variableLabelsBridgeStats),
-		"bridge_client_direct_bytes_received":           NewSemDesc("bridge_client_direct_bytes_received", NoSempV2Ready, "Bridge Client Direct Bytes Received", variableLabelsBridgeStats),
-		"bridge_client_direct_bytes_sent":               NewSemDesc("bridge_client_direct_bytes_sent", NoSempV2Ready, "Bridge Client Direct Bytes Sent", variableLabelsBridgeStats),
-		"bridge_client_large_messages_received":         NewSemDesc("bridge_client_large_messages_received", NoSempV2Ready, "Bridge Client Large Messages received", variableLabelsBridgeStats),
-		"bridge_denied_duplicate_clients":               NewSemDesc("bridge_denied_duplicate_clients", NoSempV2Ready, "Bridge Denied Duplicate Clients", variableLabelsBridgeStats),
-		"bridge_not_enough_space_msgs_sent":             NewSemDesc("bridge_not_enough_space_msgs_sent", NoSempV2Ready, "Bridge Not Enough Space Messages Sent", variableLabelsBridgeStats),
-		"bridge_max_exceeded_msgs_sent":                 NewSemDesc("bridge_max_exceeded_msgs_sent", NoSempV2Ready, "Bridge Max Exceeded Messages Sent", variableLabelsBridgeStats),
-		"bridge_subscribe_client_not_found":             NewSemDesc("bridge_subscribe_client_not_found", NoSempV2Ready, "Bridge Subscriber Client Not Found", variableLabelsBridgeStats),
-		"bridge_not_found_msgs_sent":                    NewSemDesc("bridge_not_found_msgs_sent", NoSempV2Ready, "Bridge Not Found Messages Sent", variableLabelsBridgeStats),
-		"bridge_current_ingress_rate_per_second":        NewSemDesc("bridge_current_ingress_rate_per_second", NoSempV2Ready, "Current Ingress Rate / s", variableLabelsBridgeStats),
-		"bridge_current_egress_rate_per_second":         NewSemDesc("bridge_current_egress_rate_per_second", NoSempV2Ready, "Current Egress Rate / s", variableLabelsBridgeStats),
-		"bridge_total_ingress_discards":                 NewSemDesc("bridge_total_ingress_discards", NoSempV2Ready, "Total Ingress Discards", variableLabelsBridgeStats),
-		"bridge_total_egress_discards":                  NewSemDesc("bridge_total_egress_discards", NoSempV2Ready, "Total Egress Discards", variableLabelsBridgeStats),
-	},
+	"VpnStats":    VpnStats,
+	"VpnStatsV2":  VpnStats,
+	"BridgeStats": BridgeStats,
 	"QueueRates": {
 		"queue_rx_msg_rate":      NewSemDesc("queue_rx_msg_rate", NoSempV2Ready, "Rate of received messages.", variableLabelsVpnQueue),
 		"queue_tx_msg_rate":      NewSemDesc("queue_tx_msg_rate", NoSempV2Ready, "Rate of transmitted messages.", variableLabelsVpnQueue),
@@ -615,15 +715,10 @@ var MetricDesc = map[string]Descriptions{
 		"queue_rx_byte_rate_avg": NewSemDesc("queue_rx_byte_rate_avg", NoSempV2Ready, "Average rate of received bytes.", variableLabelsVpnQueue),
 		"queue_tx_byte_rate_avg": NewSemDesc("queue_tx_byte_rate_avg", NoSempV2Ready, "Average rate of transmitted bytes.", variableLabelsVpnQueue),
 	},
-	"QueueDetails": {
-		"queue_spool_quota_bytes": NewSemDesc("queue_spool_quota_bytes", NoSempV2Ready, "Queue spool configured max disk usage in bytes.", variableLabelsVpnQueue),
-		"queue_spool_usage_bytes": NewSemDesc("queue_spool_usage_bytes", NoSempV2Ready, "The size in bytes of all messages currently in the Queue.", variableLabelsVpnQueue),
-		"queue_spool_usage_msgs":  NewSemDesc("queue_spool_usage_msgs", NoSempV2Ready, "The count of all messages currently in the Queue.", variableLabelsVpnQueue),
-		"queue_binds":             NewSemDesc("queue_binds", NoSempV2Ready, "Number of clients bound to queue.", variableLabelsVpnQueue),
-		"queue_subscriptions":     NewSemDesc("queue_subscriptions", NoSempV2Ready, "Number of subscriptions of the queue.", variableLabelsVpnQueue),
-	},
-	"QueueStats":   QueueStats,
-	"QueueStatsV2": QueueStats,
+	"QueueDetails":   QueueDetails,
+	"QueueDetailsV2": QueueDetailsV2,
+	"QueueStats":     QueueStats,
+	"QueueStatsV2":   QueueStats,
 	"TopicEndpointRates": {
 		"rx_msg_rate":      NewSemDesc("topic_endpoint_rx_msg_rate", NoSempV2Ready, "Rate of received messages.", variableLabelsVpnTopicEndpoint),
 		"tx_msg_rate":      NewSemDesc("topic_endpoint_tx_msg_rate", NoSempV2Ready, "Rate of transmitted messages.", variableLabelsVpnTopicEndpoint),
@@ -640,22 +735,8 @@ var MetricDesc = map[string]Descriptions{
 		"spool_usage_msgs":  NewSemDesc("topic_endpoint_spool_usage_msgs", NoSempV2Ready, "Topic Endpoint spooled number of messages.", variableLabelsVpnTopicEndpoint),
 		"binds":             NewSemDesc("topic_endpoint_binds", NoSempV2Ready, "Number of clients bound to topic-endpoint.", variableLabelsVpnTopicEndpoint),
 	},
-	"TopicEndpointStats": {
-		"total_bytes_spooled":                  NewSemDesc("topic_endpoint_byte_spooled", NoSempV2Ready, "Topic Endpoint spool total of all spooled messages in bytes.", variableLabelsVpnTopicEndpoint),
-		"total_messages_spooled":               NewSemDesc("topic_endpoint_msg_spooled", NoSempV2Ready, "Topic Endpoint spool total of all spooled messages.", variableLabelsVpnTopicEndpoint),
-		"messages_redelivered":                 NewSemDesc("topic_endpoint_msg_redelivered", NoSempV2Ready, "Topic Endpoint total msg redeliveries.", variableLabelsVpnTopicEndpoint),
-		"messages_transport_retransmitted":     NewSemDesc("topic_endpoint_msg_retransmitted", NoSempV2Ready, "Topic Endpoint total msg retransmitted on transport.", variableLabelsVpnTopicEndpoint),
-		"spool_usage_exceeded":                 NewSemDesc("topic_endpoint_msg_spool_usage_exceeded", NoSempV2Ready, "Topic Endpoint total number of messages exceeded the spool usage.", variableLabelsVpnTopicEndpoint),
-		"max_message_size_exceeded":            NewSemDesc("topic_endpoint_msg_max_msg_size_exceeded", NoSempV2Ready, "Topic Endpoint total number of messages exceeded the max message size.", variableLabelsVpnTopicEndpoint),
-		"total_deleted_messages":               NewSemDesc("topic_endpoint_msg_total_deleted", NoSempV2Ready, "Topic Endpoint total number that was deleted.", variableLabelsVpnTopicEndpoint),
-        "messages_shutdown_discarded":          NewSemDesc("topic_endpoint_msg_shutdown_discarded", NoSempV2Ready, "Topic Endpoint total number of messages discarded due to spool shutdown.", variableLabelsVpnTopicEndpoint),
-        "messages_ttl_discarded":               NewSemDesc("topic_endpoint_msg_ttl_discarded", NoSempV2Ready, "Topic Endpoint total number of messages discarded due to ttl expiry.", variableLabelsVpnTopicEndpoint),
-        "messages_ttl_dmq":                     NewSemDesc("topic_endpoint_msg_ttl_dmq", NoSempV2Ready, "Topic Endpoint total number of messages delivered to dmq due to ttl expiry.", variableLabelsVpnTopicEndpoint),
-        "messages_ttl_dmq_failed":              NewSemDesc("topic_endpoint_msg_ttl_dmq_failed", NoSempV2Ready, "Topic Endpoint total number of messages that failed delivery to dmq due to ttl expiry.", variableLabelsVpnTopicEndpoint),
-        "messages_max_redelivered_discarded":   NewSemDesc("topic_endpoint_msg_max_redelivered_discarded", NoSempV2Ready, "Topic Endpoint total number of messages discarded due to exceeded max redelivery.", variableLabelsVpnTopicEndpoint),
-        "messages_max_redelivered_dmq":         NewSemDesc("topic_endpoint_msg_max_redelivered_dmq", NoSempV2Ready, "Topic Endpoint total number of messages delivered to dmq due to exceeded max redelivery.", variableLabelsVpnTopicEndpoint),
-        "messages_max_redelivered_dmq_failed":  NewSemDesc("topic_endpoint_msg_max_redelivered_dmq_failed", NoSempV2Ready, "Topic Endpoint total number of messages failed delivery to dmq due to exceeded max redelivery.", variableLabelsVpnTopicEndpoint),
-	},
+	"TopicEndpointStats":   TopicEndpointStats,
+	"TopicEndpointStatsV2": TopicEndpointStats,
 	"ClusterLinks": {
 		"enabled":     NewSemDesc("cluster_link_enabled", NoSempV2Ready, "Cluster link is enabled.", variableLabelsClusterLink),
 		"oper_up":     NewSemDesc("cluster_link_operational", NoSempV2Ready, "Cluster link is operational.", variableLabelsClusterLink),
@@ -727,9 +808,6 @@ var MetricDesc = map[string]Descriptions{
 		"total_queue_bindings_up":                             NewSemDesc("rdp_total_queue_bindings_up", NoSempV2Ready, "The total number of queue bindings that are up.", nil),
 		"total_queue_bindings_configured":                     NewSemDesc("rdp_total_queue_bindings_configured", NoSempV2Ready, "The total number of configured queue bindings.", nil),
 	},
-	"MqttSession": {
-		"mqtt_session_info":           NewSemDesc("mqtt_session_info", NoSempV2Ready, "Static information and flags regarding the MQTT session. Value is always 1.", variableLabelsMqttSessionInfo),
-		"mqtt_session_subscriptions":  NewSemDesc("mqtt_session_subscriptions", NoSempV2Ready, "Number of subscriptions for the MQTT session.", variableLabelsMqttSession),
-		"mqtt_session_uptime_seconds": NewSemDesc("mqtt_session_uptime_seconds", NoSempV2Ready, "Uptime of the MQTT session in seconds.", variableLabelsMqttSession),
-	},
+	"MqttSession":   MqttSession,
+	"MqttSessionV2": MqttSessionV2,
 }
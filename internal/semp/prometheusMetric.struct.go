@@ -3,6 +3,8 @@ package semp
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"unicode/utf8"
 
@@ -22,6 +24,12 @@ func (semp *Semp) NewMetric(desc *Desc, valueType prometheus.ValueType, value fl
 	if err != nil {
 		panic(err)
 	}
+	if semp.series != nil {
+		semp.series.Add(1)
+	}
+	if semp.node != "" {
+		desc = desc.ForNode(semp.node)
+	}
 
 	return PrometheusMetric{
 		desc:        desc,
@@ -53,18 +61,25 @@ func validateLabelValues(vals []string, expectedNumberOfValues int) error {
 }
 
 func (metric *PrometheusMetric) Name() string {
-	if len(metric.desc.variableLabels) < 1 {
+	if len(metric.desc.variableLabels) < 1 && len(metric.desc.constLabels) < 1 {
 		return metric.desc.fqName
 	}
 
-	labelStrings := make([]string, len(metric.desc.variableLabels))
+	labelStrings := make([]string, len(metric.desc.variableLabels), len(metric.desc.variableLabels)+len(metric.desc.constLabels))
 	for index, variableLabel := range metric.desc.variableLabels {
 		variableLabelValue := metric.labelValues[index]
 		labelStrings[index] = variableLabel + "=\"" + variableLabelValue + "\""
 	}
+	for _, constLabel := range slices.Sorted(maps.Keys(metric.desc.constLabels)) {
+		labelStrings = append(labelStrings, constLabel+"=\""+metric.desc.constLabels[constLabel]+"\"")
+	}
 	return metric.desc.fqName + "{" + strings.Join(labelStrings, ",") + "}"
 }
 
+func (metric *PrometheusMetric) Desc() *Desc {
+	return metric.desc
+}
+
 func (metric *PrometheusMetric) AsPrometheusMetric() prometheus.Metric {
 	return prometheus.MustNewConstMetric(metric.desc.AsPrometheusDesc(), metric.valueType, metric.value, metric.labelValues...)
 }
@@ -76,3 +91,8 @@ func (metric *PrometheusMetric) Deprecate() {
 func (metric *PrometheusMetric) IsDeprecated() bool {
 	return metric.deprecated
 }
+
+// IsOf reports whether the metric is a series of desc, of any node.
+func (metric *PrometheusMetric) IsOf(desc *Desc) bool {
+	return metric.desc.fqName == desc.fqName
+}
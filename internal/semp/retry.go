@@ -0,0 +1,145 @@
+package semp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	retriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "solace_exporter_semp_retries_total",
+		Help: "SEMP requests that were retried after a transient failure, by reason of the failure.",
+	}, []string{"broker", "reason"})
+	retriesExhaustedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "solace_exporter_semp_retries_exhausted_total",
+		Help: "SEMP requests that still failed transiently after all retries.",
+	}, []string{"broker"})
+)
+
+func init() {
+	prometheus.MustRegister(retriesTotal, retriesExhaustedTotal)
+}
+
+// maxRetryBackoff caps the exponential backoff between two retries.
+const maxRetryBackoff = 5 * time.Second
+
+// RetryPolicy configures how SEMP monitor reads are retried after transient failures. The zero value disables retries.
+type RetryPolicy struct {
+	// Retries is the number of retries after the first attempt.
+	Retries int64
+	// Backoff is the wait before the first retry. It doubles with every further retry up to maxRetryBackoff, and is
+	// jittered between half and the full value, so the scrapes of several exporters do not retry in lockstep.
+	Backoff time.Duration
+}
+
+func (policy RetryPolicy) backoff(retry int64) time.Duration {
+	wait := policy.Backoff
+	for i := int64(0); i < retry && wait < maxRetryBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, maxRetryBackoff)
+	if wait <= 0 {
+		return 0
+	}
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// transientError is a failed SEMP request that may succeed when sent again.
+type transientError struct {
+	reason string
+	err    error
+	// reply is set if the broker answered in a way the caller can handle itself, like an execute-result it reports
+	// as error of the data source. It is returned instead of the error once the retries are used up.
+	reply []byte
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+
+func (e *transientError) Unwrap() error { return e.err }
+
+// classifyTransportError marks errors of the connection to the broker as transient. Cancellation and timeouts are
+// not: the scrape is over, or the broker is too slow already and must not get more load.
+func classifyTransportError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &transientError{reason: "connection", err: err}
+	}
+	return err
+}
+
+// isTransientStatus reports whether an HTTP status means the broker or a proxy in front of it is temporarily
+// unavailable.
+func isTransientStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func statusReason(status int) string {
+	return "http_" + strconv.Itoa(status)
+}
+
+var (
+	showCommandRe   = regexp.MustCompile(`^\s*<rpc(\s[^>]*)?>\s*<show>`)
+	executeResultRe = regexp.MustCompile(`<execute-result\s+code="([^"]*)"(?:\s+reason="([^"]*)")?`)
+)
+
+// isMonitorRead reports whether a SEMP v1 command only reads state, so that sending it twice does no harm.
+func isMonitorRead(command string) bool {
+	return showCommandRe.MatchString(command)
+}
+
+// brokerBusy reports whether a SEMP v1 reply is a failed execute-result because the broker is busy.
+func brokerBusy(reply []byte) (reason string, busy bool) {
+	match := executeResultRe.FindSubmatch(reply)
+	if match == nil || string(match[1]) == "ok" {
+		return "", false
+	}
+	reason = string(match[2])
+	return reason, strings.Contains(strings.ToLower(reason), "busy")
+}
+
+// withRetries calls attempt until it succeeds, fails with an error that is not transient, or the retries of the
+// policy are used up. The wait between two attempts ends early when ctx is done. attempt must send the same request
+// every time: a paging loop calls this per page, so a retry resumes at the page that failed.
+func (semp *Semp) withRetries(ctx context.Context, logName string, page int, attempt func() error) error {
+	for retry := int64(0); ; retry++ {
+		err := attempt()
+		var transient *transientError
+		if err == nil || !errors.As(err, &transient) {
+			return err
+		}
+		if retry >= semp.retry.Retries {
+			if semp.retry.Retries > 0 {
+				retriesExhaustedTotal.WithLabelValues(semp.brokerURI).Inc()
+			}
+			return err
+		}
+
+		retriesTotal.WithLabelValues(semp.brokerURI, transient.reason).Inc()
+		wait := semp.retry.backoff(retry)
+		semp.logger.Warn("Retrying "+logName+" after transient failure", "page", page, "retry", retry+1, "wait", wait, "err", err, "broker", semp.brokerURI)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package semp
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newRetryTestSemp starts a broker that answers the n-th request (starting at 0) with reply(n) and returns a Semp
+// with a fast retry policy, and the request bodies the broker received.
+func newRetryTestSemp(t *testing.T, retries int64, reply func(n int, w http.ResponseWriter)) (*Semp, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		n := len(bodies)
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		reply(n, w)
+	}))
+	t.Cleanup(server.Close)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewSemp(logger, server.URL, http.Client{Timeout: 5 * time.Second}, nil, false, false, nil, RetryPolicy{Retries: retries, Backoff: time.Millisecond}, nil)
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), bodies...)
+	}
+}
+
+func TestPostHTTPRetriesTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	s, bodies := newRetryTestSemp(t, 2, func(n int, w http.ResponseWriter) {
+		if n < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("<ok/>"))
+	})
+
+	body, err := s.postHTTP(t.Context(), s.brokerURI+"/SEMP", "application/xml", "<rpc><show><version/></show></rpc>", "Test", 1)
+	if err != nil {
+		t.Fatalf("postHTTP: %v", err)
+	}
+	_ = body.Close()
+
+	if got := len(bodies()); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+	if got := testutil.ToFloat64(retriesTotal.WithLabelValues(s.brokerURI, "http_503")); got != 2 {
+		t.Errorf("retries metric = %v, want 2", got)
+	}
+}
+
+func TestPostHTTPDoesNotRetry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		status  int
+		command string
+	}{
+		{name: "permanent error", status: http.StatusUnauthorized, command: "<rpc><show><version/></show></rpc>"},
+		{name: "not a monitor read", status: http.StatusServiceUnavailable, command: "<rpc><clear><stats/></clear></rpc>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, bodies := newRetryTestSemp(t, 3, func(n int, w http.ResponseWriter) {
+				w.WriteHeader(tt.status)
+			})
+			if _, err := s.postHTTP(t.Context(), s.brokerURI+"/SEMP", "application/xml", tt.command, "Test", 1); err == nil {
+				t.Error("postHTTP: expected error, got nil")
+			}
+			if got := len(bodies()); got != 1 {
+				t.Errorf("got %d requests, want 1", got)
+			}
+		})
+	}
+}
+
+// TestPostHTTPBrokerBusyReturnsReply checks that a busy broker is retried, and that its reply is handed to the caller
+// once the retries are used up, so the data source reports the execute-result as before.
+func TestPostHTTPBrokerBusyReturnsReply(t *testing.T) {
+	t.Parallel()
+
+	const busy = `<rpc-reply><execute-result code="fail" reason="Broker busy, try again later"/></rpc-reply>`
+	s, bodies := newRetryTestSemp(t, 1, func(n int, w http.ResponseWriter) {
+		_, _ = w.Write([]byte(busy))
+	})
+
+	body, err := s.postHTTP(t.Context(), s.brokerURI+"/SEMP", "application/xml", "<rpc><show><version/></show></rpc>", "Test", 1)
+	if err != nil {
+		t.Fatalf("postHTTP: %v", err)
+	}
+	content, _ := io.ReadAll(body)
+	if string(content) != busy {
+		t.Errorf("body = %q, want the busy reply", content)
+	}
+	if got := len(bodies()); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+	if got := testutil.ToFloat64(retriesExhaustedTotal.WithLabelValues(s.brokerURI)); got != 1 {
+		t.Errorf("exhausted metric = %v, want 1", got)
+	}
+}
+
+// TestPagingResumesAtFailedPage checks that a retry sends the more-cookie of the failed page again, instead of
+// restarting the walk at the first page.
+func TestPagingResumesAtFailedPage(t *testing.T) {
+	t.Parallel()
+
+	const cookie = "<rpc><show><message-vpn><vpn-name>*</vpn-name><page>2</page></message-vpn></show></rpc>"
+	s, bodies := newRetryTestSemp(t, 2, func(n int, w http.ResponseWriter) {
+		switch n {
+		case 0:
+			_, _ = w.Write([]byte(`<rpc-reply><rpc><show><message-vpn><vpn><name>a</name></vpn></message-vpn></show></rpc>` +
+				`<more-cookie>` + cookie + `</more-cookie><execute-result code="ok"/></rpc-reply>`))
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte(`<rpc-reply><rpc><show><message-vpn><vpn><name>b</name></vpn></message-vpn></show></rpc><execute-result code="ok"/></rpc-reply>`))
+		}
+	})
+
+	ch := make(chan PrometheusMetric, 100)
+	up, err := s.GetVpnSemp1(t.Context(), ch, "*", 1)
+	close(ch)
+	if err != nil || up != 1 {
+		t.Fatalf("GetVpnSemp1 = %v, %v; want 1, nil", up, err)
+	}
+
+	got := bodies()
+	if len(got) != 3 || got[1] != cookie || got[2] != cookie {
+		t.Errorf("got requests %q, want the first page and twice the more-cookie", got)
+	}
+	vpns := map[string]bool{}
+	for metric := range ch {
+		vpns[metric.labelValues[0]] = true
+	}
+	if !vpns["a"] || !vpns["b"] {
+		t.Errorf("got metrics of VPNs %v, want a and b", vpns)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{Retries: 10, Backoff: 100 * time.Millisecond}
+	tests := []struct {
+		retry    int64
+		min, max time.Duration
+	}{
+		{retry: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{retry: 2, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{retry: 9, min: maxRetryBackoff / 2, max: maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if got := policy.backoff(tt.retry); got < tt.min || got > tt.max {
+				t.Errorf("backoff(%d) = %s, want between %s and %s", tt.retry, got, tt.min, tt.max)
+			}
+		}
+	}
+}
@@ -2,12 +2,16 @@ package semp
 
 import (
 	"slices"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 const NoSempV2Ready = "NOT_SEMP_V2_READY"
 
+// NodeLabel is the label that tells apart the series of both nodes of an HA pair, see Semp.ForNode.
+const NodeLabel = "node"
+
 type Desc struct {
 	fqName         string
 	sempV2field    string
@@ -26,6 +30,27 @@ func NewSemDesc(fqName string, sempV2field string, help string, variableLabels [
 	}
 }
 
+type nodeDescKey struct {
+	desc *Desc
+	node string
+}
+
+// nodeDescs caches the descriptions returned by ForNode, so every series of a node shares one.
+var nodeDescs sync.Map
+
+// ForNode returns the description whose series carry the const label node="<node>".
+func (v2Desc *Desc) ForNode(node string) *Desc {
+	key := nodeDescKey{desc: v2Desc, node: node}
+	if desc, ok := nodeDescs.Load(key); ok {
+		return desc.(*Desc)
+	}
+
+	desc := *v2Desc
+	desc.constLabels = prometheus.Labels{NodeLabel: node}
+	actual, _ := nodeDescs.LoadOrStore(key, &desc)
+	return actual.(*Desc)
+}
+
 func (v2Desc *Desc) AsPrometheusDesc() *prometheus.Desc {
 	return prometheus.NewDesc(v2Desc.fqName, v2Desc.help, v2Desc.variableLabels, v2Desc.constLabels)
 }
@@ -63,6 +88,13 @@ func getSempV2FieldsToSelect(metricFilter []string, mandatoryFields []string, de
 
 type Descriptions map[string]*Desc
 
+// CheckMetricFilter returns an error for the first entry of metricFilter that is neither a metric name nor a SEMP v2
+// field of descriptions, listing the valid choices.
+func (descriptions Descriptions) CheckMetricFilter(metricFilter []string) error {
+	_, err := mapItems(metricFilter, getSempV2FieldMapList(descriptions))
+	return err
+}
+
 type V2Result struct {
 	v2Desc    *Desc
 	valueType prometheus.ValueType
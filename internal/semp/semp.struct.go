@@ -3,6 +3,7 @@ package semp
 import (
 	"log/slog"
 	"net/http"
+	"sync/atomic"
 )
 
 // Semp API to the solace broker, to collect data
@@ -13,10 +14,24 @@ type Semp struct {
 	brokerURI               string
 	logBrokerToSlowWarnings bool
 	isHWBroker              bool
+	// limiter is shared by all Semp instances of the broker. Nil means unlimited.
+	limiter *Limiter
+	retry   RetryPolicy
+	// breaker is shared by all Semp instances of the broker. Nil means no circuit breaker.
+	breaker *CircuitBreaker
+
+	// Labels of the self-instrumentation and the series counter of a data source, see ForDataSource.
+	endpoint   string
+	dataSource string
+	series     *atomic.Int64
+
+	// node labels the series of one node of an HA pair, see ForNode.
+	node string
 }
 
-// NewSemp returns an initialized Semp.
-func NewSemp(logger *slog.Logger, brokerURI string, httpClient http.Client, httpRequestVisitor func(*http.Request), logBrokerToSlowWarnings bool, isHWBroker bool) *Semp {
+// NewSemp returns an initialized Semp. Every request to the broker waits for limiter first, unless it is nil.
+// Monitor reads that fail transiently are retried according to retry. No request is sent while breaker is open.
+func NewSemp(logger *slog.Logger, brokerURI string, httpClient http.Client, httpRequestVisitor func(*http.Request), logBrokerToSlowWarnings bool, isHWBroker bool, limiter *Limiter, retry RetryPolicy, breaker *CircuitBreaker) *Semp {
 	return &Semp{
 		logger:                  logger,
 		brokerURI:               brokerURI,
@@ -24,5 +39,27 @@ func NewSemp(logger *slog.Logger, brokerURI string, httpClient http.Client, http
 		httpRequestVisitor:      httpRequestVisitor,
 		logBrokerToSlowWarnings: logBrokerToSlowWarnings,
 		isHWBroker:              isHWBroker,
+		limiter:                 limiter,
+		retry:                   retry,
+		breaker:                 breaker,
 	}
 }
+
+// ForNode returns a copy of semp whose metrics carry the label node="<node>", to tell apart the series of both nodes
+// of an HA pair.
+func (semp *Semp) ForNode(node string) *Semp {
+	scoped := *semp
+	scoped.node = node
+	return &scoped
+}
+
+// BrokerURI returns the SEMP URI of the broker.
+func (semp *Semp) BrokerURI() string {
+	return semp.brokerURI
+}
+
+// CheckCircuit returns ErrCircuitOpen while the circuit breaker of the broker rejects requests, so a scrape can skip
+// its data sources instead of failing each of them on its first request.
+func (semp *Semp) CheckCircuit() error {
+	return semp.breaker.check()
+}
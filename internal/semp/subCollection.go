@@ -0,0 +1,97 @@
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Some SEMP v2 metrics are no fields of the objects of their data source, but of a collection below each object, like
+// the txFlows of a queue. Their sempV2field is the name of that sub-collection, for the number of its objects, or
+// subCollectionField of a field of its objects. A sub-collection costs at least one more request per object, so with a
+// metric filter a data source requests it only if the filter names one of its metrics, see selectSubCollections.
+
+// subCollectionField returns the sempV2field of the metric of field of the objects of collection.
+func subCollectionField(collection string, field string) string {
+	return collection + "." + field
+}
+
+// selectSubCollections splits fieldsToSelect, the SEMP v2 fields of a metric filter, into the fields of the objects of
+// a data source, which it selects, and the sub-collections of collections the metric filter names. The selected
+// sub-collections map to the fields of their objects the metric filter names; a sub-collection that is only counted
+// has none. Without a metric filter, no sub-collection is selected.
+func selectSubCollections(fieldsToSelect []string, collections ...string) (objectFields []string, selected map[string][]string) {
+	selected = make(map[string][]string)
+	for _, field := range fieldsToSelect {
+		collection, collectionField, _ := strings.Cut(field, ".")
+		if !slices.Contains(collections, collection) {
+			objectFields = append(objectFields, field)
+			continue
+		}
+		fields := selected[collection]
+		if collectionField != "" {
+			fields = append(fields, collectionField)
+		}
+		selected[collection] = fields
+	}
+	return objectFields, selected
+}
+
+// errUnexpectedSubCollection is the error of countSempV2Collection for a reply it can't count.
+var errUnexpectedSubCollection = errors.New("unexpected result: see log")
+
+// countSempV2Collection returns the number of objects of the SEMP v2 collection of uri, following its pages.
+func (semp *Semp) countSempV2Collection(ctx context.Context, uri string, logName string) (float64, error) {
+	type Response struct {
+		Data []json.RawMessage `json:"data"`
+		Meta struct {
+			ResponseCode int `json:"responseCode"`
+			Paging       struct {
+				NextPageURI string `json:"nextPageUri"`
+			} `json:"paging"`
+			Error struct {
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"meta"`
+	}
+
+	var count float64
+	var page = 1
+	for nextURL := uri; nextURL != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return 0, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json", logName, page)
+		page++
+		if err != nil {
+			semp.logger.Error("Can't scrape "+logName, "command", nextURL, "err", err, "broker", semp.brokerURI)
+			return 0, err
+		}
+
+		var response Response
+		if err := json.Unmarshal(body, &response); err != nil {
+			semp.logger.Error("Can't decode "+logName, "err", err, "broker", semp.brokerURI)
+			semp.observeDecodeError()
+			return 0, errors.Join(errUnexpectedSubCollection, err)
+		}
+		if response.Meta.ResponseCode != 200 {
+			semp.logger.Error("unexpected result", "command", nextURL, "remoteError", response.Meta.Error.Description, "broker", semp.brokerURI)
+			return 0, errUnexpectedSubCollection
+		}
+
+		count += float64(len(response.Data))
+		nextURL = response.Meta.Paging.NextPageURI
+	}
+	return count, nil
+}
+
+// subCollectionUp returns what a data source reports for err of countSempV2Collection, like for an error of its own
+// collection: 0 for an unexpected reply, -1 for a failed request or a cancelled scrape.
+func subCollectionUp(err error) float64 {
+	if errors.Is(err, errUnexpectedSubCollection) {
+		return 0
+	}
+	return -1
+}
@@ -0,0 +1,33 @@
+package semp
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestSelectSubCollections(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		fieldsToSelect   []string
+		wantObjectFields []string
+		wantSelected     map[string][]string
+	}{
+		{fieldsToSelect: nil, wantSelected: map[string][]string{}},
+		{fieldsToSelect: []string{"bindCount", "queueName"}, wantObjectFields: []string{"bindCount", "queueName"}, wantSelected: map[string][]string{}},
+		{fieldsToSelect: []string{"txFlows", "queueName"}, wantObjectFields: []string{"queueName"}, wantSelected: map[string][]string{"txFlows": nil}},
+		{
+			fieldsToSelect:   []string{"remoteMsgVpns.up", "bridgeName", "remoteMsgVpns.enabled"},
+			wantObjectFields: []string{"bridgeName"},
+			wantSelected:     map[string][]string{"remoteMsgVpns": {"up", "enabled"}},
+		},
+	}
+
+	for _, tt := range tests {
+		objectFields, selected := selectSubCollections(tt.fieldsToSelect, "txFlows", "rxFlows", "remoteMsgVpns")
+		if !slices.Equal(objectFields, tt.wantObjectFields) || !maps.EqualFunc(selected, tt.wantSelected, slices.Equal) {
+			t.Errorf("selectSubCollections(%q) = %q, %q, want %q, %q", tt.fieldsToSelect, objectFields, selected, tt.wantObjectFields, tt.wantSelected)
+		}
+	}
+}
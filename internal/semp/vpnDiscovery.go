@@ -0,0 +1,191 @@
+package semp
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+	"solace_exporter/internal/semp/types"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+// vpnList is the discovered message VPNs of one broker.
+type vpnList struct {
+	brokerUse
+	names   []string
+	updated time.Time
+}
+
+// vpnLists caches the vpnList per broker URI, see DiscoverVpns.
+var vpnLists = brokerRegistry[*vpnList]{
+	maxIdle: brokerIdleTimeout,
+	evict: func(brokerURI string, _ *vpnList) {
+		discoveredVpns.DeleteLabelValues(brokerURI)
+	},
+}
+
+// vpnDiscoveries makes concurrent data sources of a broker wait for one discovery, keyed by broker URI.
+var vpnDiscoveries singleflight.Group
+
+var discoveredVpns = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "solace_exporter_discovered_vpns",
+	Help: "Message VPNs the last discovery found on the broker.",
+}, []string{"broker"})
+
+func init() {
+	prometheus.MustRegister(discoveredVpns)
+}
+
+// errNoSempV2Monitor is a reply to a SEMP v2 monitor request that is no SEMP v2 reply, like the one of a broker without
+// the SEMP v2 monitor API.
+var errNoSempV2Monitor = errors.New("no SEMP v2 monitor reply")
+
+// DiscoverVpns returns the names of all message VPNs of the broker, sorted. It asks the broker, unless it did less than
+// maxAge ago: through /SEMP/v2/monitor/msgVpns, or `show message-vpn *` if the broker has no SEMP v2 monitor API. The
+// list is not refreshed in the background, but by the first call after it got older than maxAge. Concurrent calls for
+// the same broker share one discovery, which is detached from the cancellation of the caller that started it, but
+// bounded by its deadline.
+func (semp *Semp) DiscoverVpns(ctx context.Context, maxAge time.Duration) ([]string, error) {
+	if names, ok := cachedVpnNames(semp.brokerURI, maxAge); ok {
+		return names, nil
+	}
+
+	ch := vpnDiscoveries.DoChan(semp.brokerURI, func() (interface{}, error) {
+		// Another data source may have discovered the VPNs between our check above and acquiring the singleflight
+		// slot.
+		if names, ok := cachedVpnNames(semp.brokerURI, maxAge); ok {
+			return names, nil
+		}
+
+		discoverCtx, cancel := detachedContext(ctx)
+		defer cancel()
+
+		names, err := semp.fetchVpnNamesSemp2(discoverCtx)
+		if errors.Is(err, errNoSempV2Monitor) {
+			semp.logger.Debug("Discovering message VPNs through SEMP v1", "err", err, "broker", semp.brokerURI)
+			names, err = semp.fetchVpnNamesSemp1(discoverCtx)
+		}
+		if err != nil {
+			return []string(nil), fmt.Errorf("discovering message VPNs: %w", err)
+		}
+
+		slices.Sort(names)
+		names = slices.Compact(names)
+		previous, _ := vpnLists.store(semp.brokerURI, &vpnList{names: names, updated: time.Now()})
+		if previous == nil || !slices.Equal(names, previous.names) {
+			semp.logger.Info("Discovered message VPNs", "broker", semp.brokerURI, "vpns", len(names))
+		}
+		discoveredVpns.WithLabelValues(semp.brokerURI).Set(float64(len(names)))
+		return names, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case out := <-ch:
+		return out.Val.([]string), out.Err
+	}
+}
+
+// cachedVpnNames returns the discovered message VPNs of brokerURI if they are younger than maxAge.
+func cachedVpnNames(brokerURI string, maxAge time.Duration) ([]string, bool) {
+	if list, ok := vpnLists.load(brokerURI); ok && time.Since(list.updated) < maxAge {
+		return list.names, true
+	}
+	return nil, false
+}
+
+func (semp *Semp) fetchVpnNamesSemp2(ctx context.Context) ([]string, error) {
+	type Response struct {
+		MsgVpns []struct {
+			MsgVpnName string `json:"msgVpnName"`
+		} `json:"data"`
+		Meta struct {
+			ResponseCode int `json:"responseCode"`
+			Paging       struct {
+				NextPageURI string `json:"nextPageUri"`
+			} `json:"paging"`
+			Error struct {
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"meta"`
+	}
+
+	var names = []string{}
+	var page = 1
+	for nextURL := semp.brokerURI + "/SEMP/v2/monitor/msgVpns?count=100&select=msgVpnName"; nextURL != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return nil, err
+		}
+		body, err := semp.getHTTPbytes(ctx, nextURL, "application/json", "VpnDiscoverySemp2", page)
+		page++
+		if err != nil {
+			return nil, err
+		}
+
+		var response Response
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("%w: %w", errNoSempV2Monitor, err)
+		}
+		if response.Meta.ResponseCode != 200 {
+			return nil, fmt.Errorf("%w: response code %d: %s", errNoSempV2Monitor, response.Meta.ResponseCode, response.Meta.Error.Description)
+		}
+
+		for _, vpn := range response.MsgVpns {
+			names = append(names, vpn.MsgVpnName)
+		}
+		nextURL = response.Meta.Paging.NextPageURI
+	}
+	return names, nil
+}
+
+func (semp *Semp) fetchVpnNamesSemp1(ctx context.Context) ([]string, error) {
+	type Data struct {
+		RPC struct {
+			Show struct {
+				MessageVpn struct {
+					Vpn []struct {
+						Name string `xml:"name"`
+					} `xml:"vpn"`
+				} `xml:"message-vpn"`
+			} `xml:"show"`
+		} `xml:"rpc"`
+		MoreCookie    types.MoreCookie    `xml:"more-cookie,omitempty"`
+		ExecuteResult types.ExecuteResult `xml:"execute-result"`
+	}
+
+	var names = []string{}
+	var page = 1
+	for command := "<rpc><show><message-vpn><vpn-name>*</vpn-name><count/><num-elements>100</num-elements></message-vpn></show></rpc>"; command != ""; {
+		if err := scrapeCancelled(ctx, page); err != nil {
+			return nil, err
+		}
+		body, err := semp.postHTTP(ctx, semp.brokerURI+"/SEMP", "application/xml", command, "VpnDiscoverySemp1", page)
+		page++
+		if err != nil {
+			return nil, err
+		}
+
+		var target Data
+		err = xml.NewDecoder(body).Decode(&target)
+		_ = body.Close()
+		if err != nil {
+			semp.observeDecodeError()
+			return nil, err
+		}
+		if err := target.ExecuteResult.OK(); err != nil {
+			return nil, err
+		}
+
+		for _, vpn := range target.RPC.Show.MessageVpn.Vpn {
+			names = append(names, vpn.Name)
+		}
+		command = target.MoreCookie.RPC
+	}
+	return names, nil
+}
@@ -0,0 +1,75 @@
+package semp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDiscoverVpnsFallsBackToSemp1(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/SEMP" {
+			http.Error(w, "<html>not found</html>", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`<rpc-reply><rpc><show><message-vpn><vpn><name>b</name></vpn><vpn><name>a</name></vpn></message-vpn></show></rpc>` +
+			`<execute-result code="ok"/></rpc-reply>`))
+	}))
+	t.Cleanup(server.Close)
+	s := NewSemp(slog.New(slog.NewTextHandler(io.Discard, nil)), server.URL, http.Client{}, nil, false, false, nil, RetryPolicy{}, nil)
+
+	names, err := s.DiscoverVpns(t.Context(), time.Minute)
+	if err != nil {
+		t.Fatalf("DiscoverVpns error: %v", err)
+	}
+	if want := []string{"a", "b"}; !slices.Equal(names, want) {
+		t.Errorf("DiscoverVpns = %q, want %q", names, want)
+	}
+}
+
+func TestDiscoverVpnsConcurrentlyRespectsCancellation(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"data":[{"msgVpnName":"default"}],"meta":{"responseCode":200}}`))
+	}))
+	t.Cleanup(server.Close)
+	s := NewSemp(slog.New(slog.NewTextHandler(io.Discard, nil)), server.URL, http.Client{}, nil, false, false, nil, RetryPolicy{}, nil)
+
+	// A caller whose scrape is abandoned stops waiting, but the discovery goes on for the others.
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	if _, err := s.DiscoverVpns(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("DiscoverVpns of the abandoned scrape = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("the abandoned scrape waited %s for the discovery", elapsed)
+	}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			if names, err := s.DiscoverVpns(t.Context(), time.Minute); err != nil || !slices.Equal(names, []string{"default"}) {
+				t.Errorf("DiscoverVpns = %q, %v", names, err)
+			}
+		})
+	}
+	wg.Wait()
+	if n := requests.Load(); n != 1 {
+		t.Errorf("got %d requests, want the concurrent data sources to share the discovery", n)
+	}
+}
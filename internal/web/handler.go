@@ -4,6 +4,7 @@ import (
 	"embed"
 	"html/template"
 	"net/http"
+	"solace_exporter/internal/semp"
 )
 
 //go:embed templates/index.html
@@ -16,12 +17,21 @@ type EndpointView struct {
 
 type TemplateData struct {
 	IsHWBroker bool
-	Endpoints  []EndpointView
+	// DetectHWBroker lists the scrape targets of both broker types, since IsHWBroker is not known yet.
+	DetectHWBroker bool
+	Endpoints      []EndpointView
+	Targets        []string
+}
+
+// pageData is what the index template renders: the given TemplateData plus the scrape targets of the broker type.
+type pageData struct {
+	TemplateData
+	DataSources []*semp.DataSourceDescriptor
 }
 
 type Handler struct {
 	tmpl *template.Template
-	data TemplateData
+	data pageData
 }
 
 func NewHandler(data TemplateData) (*Handler, error) {
@@ -30,9 +40,16 @@ func NewHandler(data TemplateData) (*Handler, error) {
 		return nil, err
 	}
 
+	page := pageData{TemplateData: data}
+	for _, descriptor := range semp.DataSources() {
+		if data.DetectHWBroker || descriptor.Platform.Supports(data.IsHWBroker) {
+			page.DataSources = append(page.DataSources, descriptor)
+		}
+	}
+
 	return &Handler{
 		tmpl: tmpl,
-		data: data,
+		data: page,
 	}, nil
 }
 
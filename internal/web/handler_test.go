@@ -0,0 +1,47 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerListsScrapeTargetsOfBrokerType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		isHWBroker  bool
+		wantTargets []string
+		wantMissing []string
+	}{
+		{name: "software broker", isHWBroker: false, wantTargets: []string{"<td>Health</td>", "<td>QueueStatsV2</td>"}, wantMissing: []string{"<td>Disk</td>"}},
+		{name: "appliance", isHWBroker: true, wantTargets: []string{"<td>Disk</td>", "<td>QueueStatsV2</td>"}, wantMissing: []string{"<td>Health</td>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := NewHandler(TemplateData{IsHWBroker: tt.isHWBroker})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			body := rr.Body.String()
+			for _, want := range tt.wantTargets {
+				if !strings.Contains(body, want) {
+					t.Errorf("index does not list %s", want)
+				}
+			}
+			for _, missing := range tt.wantMissing {
+				if strings.Contains(body, missing) {
+					t.Errorf("index lists %s", missing)
+				}
+			}
+		})
+	}
+}